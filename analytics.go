@@ -0,0 +1,252 @@
+package teamworkapi
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EstimateSample captures the estimated and actual hours of a single task,
+// along with the attributes used to group estimate accuracy.
+type EstimateSample struct {
+	TaskID         int
+	ProjectID      int
+	TaskListID     int
+	AssigneeIDs    []string
+	Tags           []string
+	EstimatedHours float64
+	ActualHours    float64
+	PercentError   float64
+	Estimated      bool
+}
+
+// EstimateStats summarizes the estimate error distribution of a group of
+// tasks.  Mean, Median and P90 are computed on the absolute percent error,
+// while Bias is the mean signed percent error (positive values indicate
+// over-estimation).  Unestimated tasks are counted but excluded from the
+// distribution.
+type EstimateStats struct {
+	Count       int
+	Unestimated int
+	Mean        float64
+	Median      float64
+	P90         float64
+	Bias        float64
+}
+
+// EstimateReport groups estimate accuracy by assignee, tag, task list and
+// project.
+type EstimateReport struct {
+	Overall    *EstimateStats
+	ByAssignee map[string]*EstimateStats
+	ByTag      map[string]*EstimateStats
+	ByTaskList map[string]*EstimateStats
+	ByProject  map[string]*EstimateStats
+}
+
+// NewEstimateSample builds an EstimateSample from a task and its time totals.
+// A task without an estimate is marked as unestimated and its percent error
+// is left at zero.
+func NewEstimateSample(task *Task, totals *TimeTotals) *EstimateSample {
+
+	s := &EstimateSample{
+		TaskID:         task.ID,
+		ProjectID:      task.ProjectID,
+		TaskListID:     task.TaskListID,
+		EstimatedHours: float64(task.EstimatedMin) / 60,
+	}
+
+	for _, id := range strings.Split(task.AssignedUserID, ",") {
+		id = strings.TrimSpace(id)
+		if id != "" {
+			s.AssigneeIDs = append(s.AssigneeIDs, id)
+		}
+	}
+
+	for _, tag := range task.Tags {
+		s.Tags = append(s.Tags, tag.Name)
+	}
+
+	if totals != nil {
+		s.ActualHours = totals.ActualHours
+		if totals.EstimatedHours > 0 {
+			s.EstimatedHours = totals.EstimatedHours
+		}
+	}
+
+	if s.EstimatedHours > 0 {
+		s.Estimated = true
+		s.PercentError = CalculateEstimateError(s.EstimatedHours, s.ActualHours)
+	}
+
+	return s
+}
+
+// GetEstimateSamples retrieves the tasks matching queryParams along with their
+// logged time, and returns an EstimateSample for each.  Time totals are only
+// requested for tasks that have an estimate.
+func (conn *Connection) GetEstimateSamples(queryParams TaskQueryParams) ([]*EstimateSample, error) {
+
+	tasks, err := conn.GetTasks(queryParams)
+	if err != nil {
+		return nil, err
+	}
+
+	samples := make([]*EstimateSample, 0, len(tasks))
+
+	for _, t := range tasks {
+
+		var totals *TimeTotals
+
+		if t.EstimatedMin > 0 {
+			totals, err = conn.GetTaskHours(strconv.Itoa(t.ID))
+			if err != nil {
+				return nil, fmt.Errorf("failed to retrieve hours for task (%d): %s", t.ID, err)
+			}
+		}
+
+		samples = append(samples, NewEstimateSample(t, totals))
+	}
+
+	return samples, nil
+}
+
+// GetEstimateReport builds an EstimateReport for tasks completed between
+// fromDate and toDate (YYYYMMDD).  Additional filters (e.g. ProjectIDs or
+// AssignedUserID) may be supplied through queryParams.
+func (conn *Connection) GetEstimateReport(fromDate string, toDate string, queryParams TaskQueryParams) (*EstimateReport, error) {
+
+	errBuff := ""
+
+	if fromDate == "" {
+		errBuff += "fromDate"
+	}
+
+	if toDate == "" {
+		if errBuff != "" {
+			errBuff += ", "
+		}
+		errBuff += "toDate"
+	}
+
+	if errBuff != "" {
+		return nil, fmt.Errorf("missing required parameter(s): %s", errBuff)
+	}
+
+	queryParams.IncludeCompleted = true
+	queryParams.CompletedAfter = fromDate
+	queryParams.CompletedBefore = toDate
+
+	samples, err := conn.GetEstimateSamples(queryParams)
+	if err != nil {
+		return nil, err
+	}
+
+	return BuildEstimateReport(samples), nil
+}
+
+// BuildEstimateReport groups samples by assignee, tag, task list and project
+// and summarizes each group.  A task with several assignees or tags
+// contributes to each of them.
+func BuildEstimateReport(samples []*EstimateSample) *EstimateReport {
+
+	byAssignee := make(map[string][]*EstimateSample)
+	byTag := make(map[string][]*EstimateSample)
+	byTaskList := make(map[string][]*EstimateSample)
+	byProject := make(map[string][]*EstimateSample)
+
+	for _, s := range samples {
+		for _, a := range s.AssigneeIDs {
+			byAssignee[a] = append(byAssignee[a], s)
+		}
+
+		for _, tag := range s.Tags {
+			byTag[tag] = append(byTag[tag], s)
+		}
+
+		tl := strconv.Itoa(s.TaskListID)
+		byTaskList[tl] = append(byTaskList[tl], s)
+
+		p := strconv.Itoa(s.ProjectID)
+		byProject[p] = append(byProject[p], s)
+	}
+
+	return &EstimateReport{
+		Overall:    SummarizeEstimates(samples),
+		ByAssignee: summarizeEstimateGroups(byAssignee),
+		ByTag:      summarizeEstimateGroups(byTag),
+		ByTaskList: summarizeEstimateGroups(byTaskList),
+		ByProject:  summarizeEstimateGroups(byProject),
+	}
+}
+
+// SummarizeEstimates computes the estimate error distribution of samples.
+func SummarizeEstimates(samples []*EstimateSample) *EstimateStats {
+
+	stats := new(EstimateStats)
+
+	var abs []float64
+	signed := 0.0
+
+	for _, s := range samples {
+		if !s.Estimated {
+			stats.Unestimated++
+			continue
+		}
+
+		abs = append(abs, math.Abs(s.PercentError))
+		signed += s.PercentError
+	}
+
+	stats.Count = len(abs)
+
+	if stats.Count == 0 {
+		return stats
+	}
+
+	sort.Float64s(abs)
+
+	total := 0.0
+	for _, v := range abs {
+		total += v
+	}
+
+	stats.Mean = math.Round(total/float64(stats.Count)*100) / 100
+	stats.Median = math.Round(percentile(abs, 50)*100) / 100
+	stats.P90 = math.Round(percentile(abs, 90)*100) / 100
+	stats.Bias = math.Round(signed/float64(stats.Count)*100) / 100
+
+	return stats
+}
+
+func summarizeEstimateGroups(groups map[string][]*EstimateSample) map[string]*EstimateStats {
+
+	retVal := make(map[string]*EstimateStats, len(groups))
+
+	for k, v := range groups {
+		retVal[k] = SummarizeEstimates(v)
+	}
+
+	return retVal
+}
+
+// percentile returns the p-th percentile of sorted values using linear
+// interpolation between closest ranks.
+func percentile(sorted []float64, p float64) float64 {
+
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
@@ -0,0 +1,109 @@
+package teamworkapi
+
+import (
+	"testing"
+)
+
+func TestNewEstimateSample(t *testing.T) {
+
+	var tests = []struct {
+		task          *Task
+		totals        *TimeTotals
+		wantEstimated bool
+		wantError     float64
+		wantAssignees int
+	}{
+		{&Task{ID: 1, EstimatedMin: 600, AssignedUserID: "123,456"}, &TimeTotals{ActualHours: 5, EstimatedHours: 10}, true, 50, 2},
+		{&Task{ID: 2, EstimatedMin: 0, AssignedUserID: ""}, nil, false, 0, 0},
+		{&Task{ID: 3, EstimatedMin: 120}, &TimeTotals{ActualHours: 3}, true, -50, 0},
+	}
+
+	for _, v := range tests {
+
+		s := NewEstimateSample(v.task, v.totals)
+
+		if s.Estimated != v.wantEstimated {
+			t.Errorf("expected Estimated to be %t for task (%d) but got %t", v.wantEstimated, v.task.ID, s.Estimated)
+		}
+
+		if s.PercentError != v.wantError {
+			t.Errorf("expected percent error to be %f for task (%d) but got %f", v.wantError, v.task.ID, s.PercentError)
+		}
+
+		if len(s.AssigneeIDs) != v.wantAssignees {
+			t.Errorf("expected %d assignee(s) for task (%d) but got %d", v.wantAssignees, v.task.ID, len(s.AssigneeIDs))
+		}
+	}
+}
+
+func TestSummarizeEstimates(t *testing.T) {
+
+	samples := []*EstimateSample{
+		{Estimated: true, PercentError: 10},
+		{Estimated: true, PercentError: -20},
+		{Estimated: true, PercentError: 30},
+		{Estimated: true, PercentError: -40},
+		{Estimated: false},
+	}
+
+	stats := SummarizeEstimates(samples)
+
+	var tests = []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"Count", float64(stats.Count), 4},
+		{"Unestimated", float64(stats.Unestimated), 1},
+		{"Mean", stats.Mean, 25},
+		{"Median", stats.Median, 25},
+		{"P90", stats.P90, 37},
+		{"Bias", stats.Bias, -5},
+	}
+
+	for _, v := range tests {
+		if v.got != v.want {
+			t.Errorf("expected %s to be %f but got %f", v.name, v.want, v.got)
+		}
+	}
+
+	empty := SummarizeEstimates([]*EstimateSample{{Estimated: false}})
+	if empty.Count != 0 || empty.Mean != 0 || empty.Unestimated != 1 {
+		t.Errorf("expected empty stats with 1 unestimated task but got %+v", empty)
+	}
+}
+
+func TestBuildEstimateReport(t *testing.T) {
+
+	samples := []*EstimateSample{
+		{TaskID: 1, ProjectID: 10, TaskListID: 100, AssigneeIDs: []string{"1", "2"}, Tags: []string{"api"}, Estimated: true, PercentError: 10},
+		{TaskID: 2, ProjectID: 10, TaskListID: 101, AssigneeIDs: []string{"1"}, Estimated: true, PercentError: -30},
+		{TaskID: 3, ProjectID: 20, TaskListID: 200, AssigneeIDs: []string{"2"}, Tags: []string{"api", "ui"}},
+	}
+
+	r := BuildEstimateReport(samples)
+
+	if r.Overall.Count != 2 || r.Overall.Unestimated != 1 {
+		t.Errorf("expected overall count 2 and unestimated 1 but got %d and %d", r.Overall.Count, r.Overall.Unestimated)
+	}
+
+	if r.ByAssignee["1"].Bias != -10 {
+		t.Errorf("expected bias of -10 for assignee 1 but got %f", r.ByAssignee["1"].Bias)
+	}
+
+	if r.ByAssignee["2"].Count != 1 || r.ByAssignee["2"].Unestimated != 1 {
+		t.Errorf("unexpected stats for assignee 2: %+v", r.ByAssignee["2"])
+	}
+
+	if r.ByTag["ui"].Count != 0 {
+		t.Errorf("expected no estimated tasks for tag ui but got %d", r.ByTag["ui"].Count)
+	}
+
+	if len(r.ByTaskList) != 3 {
+		t.Errorf("expected 3 task lists but got %d", len(r.ByTaskList))
+	}
+
+	if r.ByProject["10"].Mean != 20 {
+		t.Errorf("expected mean of 20 for project 10 but got %f", r.ByProject["10"].Mean)
+	}
+}
@@ -0,0 +1,207 @@
+package teamworkapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// AttachmentFailure records a local file that could not be uploaded.
+type AttachmentFailure struct {
+	Path string
+	Err  error
+}
+
+// AttachmentResult reports the outcome of attaching local files.  Attached
+// lists the paths that were uploaded and linked; Failed those that were not.
+type AttachmentResult struct {
+	Attached []string
+	Failed   []*AttachmentFailure
+}
+
+// ProjectFileJSON models the body of a request to add a pending file to a
+// project.
+type ProjectFileJSON struct {
+	File struct {
+		CategoryID     string `json:"category-id,omitempty"`
+		Description    string `json:"description,omitempty"`
+		PendingFileRef string `json:"pendingFileRef"`
+	} `json:"file"`
+}
+
+// ProjectFileResponseHandler models a http response for adding a file to a
+// project.
+type ProjectFileResponseHandler struct {
+	Status  string `json:"STATUS"`
+	Message string `json:"MESSAGE"`
+	FileID  string `json:"fileId"`
+}
+
+// ParseResponse interprets the http response for adding a file to a project.
+func (resMsg *ProjectFileResponseHandler) ParseResponse(httpMethod string, rawRes []byte) error {
+
+	err := json.Unmarshal(rawRes, &resMsg)
+	if err != nil {
+		return err
+	}
+
+	if resMsg.Status == "Error" {
+		return fmt.Errorf("received ERROR response: %s", resMsg.Message)
+	}
+
+	switch httpMethod {
+	case http.MethodPost:
+		if resMsg.FileID == "" {
+			return fmt.Errorf("no file id returned for File POST request")
+		}
+	}
+
+	return nil
+}
+
+// err summarizes the failed uploads, or returns nil if there were none.
+func (res *AttachmentResult) err() error {
+
+	if len(res.Failed) == 0 {
+		return nil
+	}
+
+	var msgs []string
+	for _, f := range res.Failed {
+		msgs = append(msgs, f.Path+": "+f.Err.Error())
+	}
+
+	return fmt.Errorf("failed to upload %d file(s): %s", len(res.Failed), strings.Join(msgs, "; "))
+}
+
+// uploadLocalFiles uploads each path and returns the pending file references
+// of those uploaded, in order.
+func (conn *Connection) uploadLocalFiles(paths []string) ([]string, *AttachmentResult) {
+
+	res := new(AttachmentResult)
+
+	var refs []string
+
+	for _, p := range paths {
+
+		ref, err := conn.UploadLocalFile(p, nil)
+		if err != nil {
+			res.Failed = append(res.Failed, &AttachmentFailure{Path: p, Err: err})
+			continue
+		}
+
+		refs = append(refs, ref)
+		res.Attached = append(res.Attached, p)
+	}
+
+	return refs, res
+}
+
+// AttachFilesToTask uploads local files and attaches them to a task in the
+// specified category (0 for none).  Files that upload are attached even if
+// others fail; an error is returned if any file was not attached.
+func (conn *Connection) AttachFilesToTask(taskID string, categoryID int, paths ...string) (*AttachmentResult, error) {
+
+	err := validateID("taskID", taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	refs, res := conn.uploadLocalFiles(paths)
+
+	if len(refs) > 0 {
+
+		patch := TaskPatchV3JSON{}
+
+		for _, ref := range refs {
+			patch.Attachments.PendingFiles = append(patch.Attachments.PendingFiles, TaskPatchPendingFiles{
+				CategoryId: categoryID,
+				Reference:  ref,
+			})
+		}
+
+		_, err = conn.PatchTask(taskID, patch)
+		if err != nil {
+			return attachFailed(res, err), err
+		}
+	}
+
+	return res, res.err()
+}
+
+// PostCommentWithAttachments uploads local files and posts a comment on a
+// task with the files attached.  The comment is posted even if some files
+// fail to upload; an error is returned if any file was not attached.
+func (conn *Connection) PostCommentWithAttachments(taskID string, comment CommentJSON, paths ...string) (*AttachmentResult, error) {
+
+	err := validateID("taskID", taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	refs, res := conn.uploadLocalFiles(paths)
+
+	comment.PendingFileAttachments = strings.Join(refs, ",")
+
+	_, err = conn.PostComment(taskID, comment)
+	if err != nil {
+		return attachFailed(res, err), err
+	}
+
+	return res, res.err()
+}
+
+// AddFileToProject uploads a local file and adds it to a project in the
+// specified category (empty for none).  The ID of the new file is returned.
+func (conn *Connection) AddFileToProject(projectID string, categoryID string, description string, path string) (string, error) {
+
+	err := validateID("projectID", projectID)
+	if err != nil {
+		return "", err
+	}
+
+	if categoryID != "" {
+		err = validateID("categoryID", categoryID)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	ref, err := conn.UploadLocalFile(path, nil)
+	if err != nil {
+		return "", err
+	}
+
+	body := ProjectFileJSON{}
+	body.File.CategoryID = categoryID
+	body.File.Description = description
+	body.File.PendingFileRef = ref
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return "", err
+	}
+
+	handler := new(ProjectFileResponseHandler)
+
+	err = conn.PostRequest("projects/"+projectID+"/files", data, handler)
+	if err != nil {
+		return "", err
+	}
+
+	return handler.FileID, nil
+}
+
+// attachFailed moves the uploaded files of res to Failed when the final link
+// step fails.
+func attachFailed(res *AttachmentResult, err error) *AttachmentResult {
+
+	for _, p := range res.Attached {
+		res.Failed = append(res.Failed, &AttachmentFailure{Path: p, Err: err})
+	}
+
+	res.Attached = nil
+
+	return res
+}
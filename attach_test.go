@@ -0,0 +1,151 @@
+package teamworkapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func initMockUploadConnection(t *testing.T, handler http.HandlerFunc) *Connection {
+
+	refs := 0
+
+	return initMockConnection(t, func(w http.ResponseWriter, r *http.Request) {
+
+		switch r.URL.Path {
+		case "/projects/api/v1/pendingfiles/presignedurl.json":
+			refs++
+			fmt.Fprintf(w, `{"ref": "tf_%d", "url": "http://%s/bucket"}`, refs, r.Host)
+		case "/bucket":
+			ioutil.ReadAll(r.Body)
+		default:
+			handler(w, r)
+		}
+	})
+}
+
+func writeTestFiles(t *testing.T, names ...string) []string {
+
+	dir := t.TempDir()
+
+	var paths []string
+
+	for _, n := range names {
+		p := filepath.Join(dir, n)
+
+		err := ioutil.WriteFile(p, []byte("contents of "+n), 0644)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		paths = append(paths, p)
+	}
+
+	return paths
+}
+
+func TestAttachFilesToTask(t *testing.T) {
+
+	var patched TaskPatchV3JSON
+
+	conn := initMockUploadConnection(t, func(w http.ResponseWriter, r *http.Request) {
+
+		if r.Method != http.MethodPatch || r.URL.Path != "/tasks/5.json" {
+			t.Errorf("unexpected request (%s %s)", r.Method, r.URL.Path)
+		}
+
+		raw, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(raw, &patched)
+
+		fmt.Fprint(w, `{"task": {"id": 5}}`)
+	})
+
+	paths := writeTestFiles(t, "a.txt", "b.txt")
+	missing := filepath.Join(os.TempDir(), "does-not-exist.txt")
+
+	res, err := conn.AttachFilesToTask("5", 9, paths[0], missing, paths[1])
+	if err == nil || !strings.HasPrefix(err.Error(), "failed to upload 1 file(s)") {
+		t.Errorf("expected partial failure error but got (%v)", err)
+	}
+
+	if len(res.Attached) != 2 || len(res.Failed) != 1 || res.Failed[0].Path != missing {
+		t.Errorf("expected 2 attached and 1 failed but got %d and %d", len(res.Attached), len(res.Failed))
+	}
+
+	pending := patched.Attachments.PendingFiles
+
+	if len(pending) != 2 || pending[0].Reference != "tf_1" || pending[1].Reference != "tf_2" || pending[0].CategoryId != 9 {
+		t.Errorf("unexpected pending files patched: %+v", pending)
+	}
+}
+
+func TestPostCommentWithAttachments(t *testing.T) {
+
+	var posted Comment
+
+	conn := initMockUploadConnection(t, func(w http.ResponseWriter, r *http.Request) {
+
+		if r.Method != http.MethodPost || r.URL.Path != "/tasks/5/comments.json" {
+			t.Errorf("unexpected request (%s %s)", r.Method, r.URL.Path)
+		}
+
+		raw, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(raw, &posted)
+
+		fmt.Fprint(w, `{"STATUS": "OK", "id": "77"}`)
+	})
+
+	paths := writeTestFiles(t, "a.txt", "b.txt")
+
+	res, err := conn.PostCommentWithAttachments("5", CommentJSON{Body: "see attached"}, paths...)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if len(res.Attached) != 2 || posted.Comment.PendingFileAttachments != "tf_1,tf_2" || posted.Comment.Body != "see attached" {
+		t.Errorf("unexpected comment posted: %+v", posted.Comment)
+	}
+}
+
+func TestAddFileToProject(t *testing.T) {
+
+	var posted ProjectFileJSON
+
+	conn := initMockUploadConnection(t, func(w http.ResponseWriter, r *http.Request) {
+
+		switch r.URL.Path {
+		case "/projects/1/files.json":
+			raw, _ := ioutil.ReadAll(r.Body)
+			json.Unmarshal(raw, &posted)
+			fmt.Fprint(w, `{"STATUS": "OK", "fileId": "123"}`)
+		default:
+			fmt.Fprint(w, `{"STATUS": "Error", "MESSAGE": "not found"}`)
+		}
+	})
+
+	paths := writeTestFiles(t, "deliverable.pdf")
+
+	fileID, err := conn.AddFileToProject("1", "4", "Final", paths[0])
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if fileID != "123" || posted.File.PendingFileRef != "tf_1" || posted.File.CategoryID != "4" || posted.File.Description != "Final" {
+		t.Errorf("unexpected file posted: %+v (%s)", posted.File, fileID)
+	}
+
+	_, err = conn.AddFileToProject("2", "", "", paths[0])
+	if err == nil || err.Error() != "received ERROR response: not found" {
+		t.Errorf("expected error response but got (%v)", err)
+	}
+
+	_, err = conn.AddFileToProject("1", "x", "", paths[0])
+	if err == nil || err.Error() != "invalid value (x) for categoryID" {
+		t.Errorf("expected invalid categoryID error but got (%v)", err)
+	}
+}
@@ -0,0 +1,249 @@
+package teamworkapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// BoardColumn models a column of a Teamwork project board.
+type BoardColumn struct {
+	ID           int    `json:"id,omitempty"`
+	Name         string `json:"name"`
+	Color        string `json:"color,omitempty"`
+	DisplayOrder int    `json:"displayOrder,omitempty"`
+}
+
+// BoardColumnJSON models the parent JSON structure of a single BoardColumn and
+// facilitates marshalling.
+type BoardColumnJSON struct {
+	Column *BoardColumn `json:"column"`
+}
+
+// BoardColumnsJSON models the parent JSON structure of an array of
+// BoardColumns and facilitates unmarshalling.
+type BoardColumnsJSON struct {
+	Columns []*BoardColumn `json:"columns"`
+}
+
+// BoardColumnResponseHandler models a http response for a BoardColumn
+// operation.
+type BoardColumnResponseHandler struct {
+	Status  string `json:"STATUS"`
+	Message string `json:"MESSAGE"`
+	ID      string `json:"id"`
+}
+
+// ParseResponse interprets the http response for a BoardColumn operation.
+func (resMsg *BoardColumnResponseHandler) ParseResponse(httpMethod string, rawRes []byte) error {
+
+	err := json.Unmarshal(rawRes, &resMsg)
+	if err != nil {
+		return err
+	}
+
+	if resMsg.Status == "Error" {
+		return fmt.Errorf("received ERROR response: %s", resMsg.Message)
+	}
+
+	switch httpMethod {
+	case http.MethodPost:
+		if resMsg.ID == "" {
+			return fmt.Errorf("no ID returned for BoardColumn POST request")
+		}
+	}
+
+	return nil
+}
+
+// BoardColumnTasks models a board column and its tasks, in board order.
+type BoardColumnTasks struct {
+	Column *BoardColumn
+	Tasks  []*Task
+}
+
+// Board models a project board: its columns, in display order, and the tasks
+// not on the board.
+type Board struct {
+	Columns    []*BoardColumnTasks
+	Unassigned []*Task
+}
+
+// GetBoardColumns retrieves the board columns of a project, in display order.
+func (conn *Connection) GetBoardColumns(projectID string) ([]*BoardColumn, error) {
+
+	err := validateID("projectID", projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := conn.GetRequest("projects/"+projectID+"/boards/columns", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := new(BoardColumnsJSON)
+
+	err = json.Unmarshal(data, &columns)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(columns.Columns, func(i, j int) bool {
+		return columns.Columns[i].DisplayOrder < columns.Columns[j].DisplayOrder
+	})
+
+	return columns.Columns, nil
+}
+
+// PostBoardColumn creates a board column in a project.  The ID of the new
+// column is returned and stored in column.
+func (conn *Connection) PostBoardColumn(projectID string, column *BoardColumn) (int, error) {
+
+	err := validateID("projectID", projectID)
+	if err != nil {
+		return 0, err
+	}
+
+	if column == nil || strings.TrimSpace(column.Name) == "" {
+		return 0, fmt.Errorf("board column is missing required field(s): Name")
+	}
+
+	data, err := json.Marshal(BoardColumnJSON{Column: column})
+	if err != nil {
+		return 0, err
+	}
+
+	handler := new(BoardColumnResponseHandler)
+
+	err = conn.PostRequest("projects/"+projectID+"/boards/columns", data, handler)
+	if err != nil {
+		return 0, err
+	}
+
+	column.ID, err = strconv.Atoi(handler.ID)
+	if err != nil {
+		return 0, fmt.Errorf("invalid ID (%s) returned for BoardColumn POST request", handler.ID)
+	}
+
+	return column.ID, nil
+}
+
+// PutBoardColumn updates the board column identified by column.ID.
+func (conn *Connection) PutBoardColumn(column *BoardColumn) error {
+
+	if column == nil || column.ID == 0 {
+		return fmt.Errorf("missing required parameter(s): ID")
+	}
+
+	if strings.TrimSpace(column.Name) == "" {
+		return fmt.Errorf("board column is missing required field(s): Name")
+	}
+
+	data, err := json.Marshal(BoardColumnJSON{Column: column})
+	if err != nil {
+		return err
+	}
+
+	return conn.PutRequest("boards/columns/"+strconv.Itoa(column.ID), data, new(BoardColumnResponseHandler))
+}
+
+// DeleteBoardColumn deletes a board column.  Its tasks are removed from the
+// board, not deleted.
+func (conn *Connection) DeleteBoardColumn(columnID int) error {
+
+	if columnID <= 0 {
+		return fmt.Errorf("invalid value (%d) for columnID", columnID)
+	}
+
+	return conn.DeleteRequest("boards/columns/"+strconv.Itoa(columnID), new(BoardColumnResponseHandler))
+}
+
+// MoveTaskToColumn moves the card of a task to a board column.
+func (conn *Connection) MoveTaskToColumn(taskID string, columnID int) error {
+
+	err := validateID("taskID", taskID)
+	if err != nil {
+		return err
+	}
+
+	if columnID <= 0 {
+		return fmt.Errorf("invalid value (%d) for columnID", columnID)
+	}
+
+	data, err := json.Marshal(map[string]map[string]int{
+		"todo-item": {"columnId": columnID},
+	})
+	if err != nil {
+		return err
+	}
+
+	return conn.PutRequest("tasks/"+taskID, data, new(TaskItemResponseHandler))
+}
+
+// GetBoard retrieves the board of a project: its columns with their tasks.
+// Tasks are retrieved with GetTasks, so queryParams can narrow them further.
+func (conn *Connection) GetBoard(projectID string, queryParams TaskQueryParams) (*Board, error) {
+
+	columns, err := conn.GetBoardColumns(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	queryParams.ProjectIDs = projectID
+
+	tasks, err := conn.GetTasks(queryParams)
+	if err != nil {
+		return nil, err
+	}
+
+	return BuildBoard(columns, tasks), nil
+}
+
+// BuildBoard groups tasks by board column.  Tasks within a column are sorted
+// by their order, then ID.
+func BuildBoard(columns []*BoardColumn, tasks []*Task) *Board {
+
+	board := new(Board)
+
+	byID := make(map[int]*BoardColumnTasks)
+
+	for _, c := range columns {
+		ct := &BoardColumnTasks{Column: c}
+		byID[c.ID] = ct
+		board.Columns = append(board.Columns, ct)
+	}
+
+	for _, t := range tasks {
+
+		if t.BoardColumn != nil {
+			if ct, ok := byID[t.BoardColumn.ID]; ok {
+				ct.Tasks = append(ct.Tasks, t)
+				continue
+			}
+		}
+
+		board.Unassigned = append(board.Unassigned, t)
+	}
+
+	for _, ct := range board.Columns {
+		sortBoardTasks(ct.Tasks)
+	}
+
+	sortBoardTasks(board.Unassigned)
+
+	return board
+}
+
+func sortBoardTasks(tasks []*Task) {
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if tasks[i].Order != tasks[j].Order {
+			return tasks[i].Order < tasks[j].Order
+		}
+		return tasks[i].ID < tasks[j].ID
+	})
+}
@@ -0,0 +1,128 @@
+package teamworkapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestBuildBoard(t *testing.T) {
+
+	columns := []*BoardColumn{{ID: 1, Name: "To Do"}, {ID: 2, Name: "Done"}}
+
+	tasks := []*Task{
+		{ID: 10, Order: 2, BoardColumn: &TaskBoardColumn{ID: 1}},
+		{ID: 11, Order: 1, BoardColumn: &TaskBoardColumn{ID: 1}},
+		{ID: 12, BoardColumn: &TaskBoardColumn{ID: 2}},
+		{ID: 13},
+		{ID: 14, BoardColumn: &TaskBoardColumn{ID: 99}},
+	}
+
+	board := BuildBoard(columns, tasks)
+
+	ids := func(tasks []*Task) string {
+		var s []string
+		for _, t := range tasks {
+			s = append(s, fmt.Sprint(t.ID))
+		}
+		return strings.Join(s, ",")
+	}
+
+	var tests = []struct {
+		got  string
+		want string
+	}{
+		{ids(board.Columns[0].Tasks), "11,10"},
+		{ids(board.Columns[1].Tasks), "12"},
+		{ids(board.Unassigned), "13,14"},
+	}
+
+	for _, v := range tests {
+		if v.got != v.want {
+			t.Errorf("expected tasks (%s) but got (%s)", v.want, v.got)
+		}
+	}
+}
+
+func TestBoardColumns(t *testing.T) {
+
+	var calls, bodies []string
+
+	conn := initMockConnection(t, func(w http.ResponseWriter, r *http.Request) {
+
+		raw, _ := ioutil.ReadAll(r.Body)
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/projects/1/boards/columns.json":
+			fmt.Fprint(w, `{"STATUS": "OK", "columns": [{"id": 2, "name": "Done", "displayOrder": 2}, {"id": 1, "name": "To Do", "displayOrder": 1}]}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/tasks.json":
+			if r.URL.Query().Get("projectIds") != "1" {
+				t.Errorf("expected projectIds query parameter but got (%s)", r.URL.RawQuery)
+			}
+			fmt.Fprint(w, `{"STATUS": "OK", "todo-items": [{"id": 10, "boardColumn": {"id": 2, "name": "Done"}}]}`)
+		case r.Method == http.MethodPost:
+			calls = append(calls, r.Method+" "+r.URL.Path)
+			bodies = append(bodies, string(raw))
+			fmt.Fprint(w, `{"STATUS": "OK", "id": "3"}`)
+		default:
+			calls = append(calls, r.Method+" "+r.URL.Path)
+			bodies = append(bodies, string(raw))
+			fmt.Fprint(w, `{"STATUS": "OK"}`)
+		}
+	})
+
+	board, err := conn.GetBoard("1", TaskQueryParams{})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if board.Columns[0].Column.Name != "To Do" || len(board.Columns[1].Tasks) != 1 {
+		t.Errorf("unexpected board: %+v", board.Columns)
+	}
+
+	c := &BoardColumn{Name: "Review", Color: "#ff0000"}
+
+	id, err := conn.PostBoardColumn("1", c)
+	if err != nil || id != 3 || c.ID != 3 {
+		t.Errorf("expected column (3) but got (%d, %v)", id, err)
+	}
+
+	c.Name = "In Review"
+
+	for _, f := range []func() error{
+		func() error { return conn.PutBoardColumn(c) },
+		func() error { return conn.MoveTaskToColumn("10", 3) },
+		func() error { return conn.DeleteBoardColumn(3) },
+	} {
+		err = f()
+		if err != nil {
+			t.Errorf(err.Error())
+		}
+	}
+
+	want := "POST /projects/1/boards/columns.json,PUT /boards/columns/3.json,PUT /tasks/10.json,DELETE /boards/columns/3.json"
+
+	if strings.Join(calls, ",") != want {
+		t.Errorf("expected calls (%s) but got (%s)", want, strings.Join(calls, ","))
+	}
+
+	move := make(map[string]map[string]int)
+	json.Unmarshal([]byte(bodies[2]), &move)
+
+	if move["todo-item"]["columnId"] != 3 {
+		t.Errorf("unexpected move body (%s)", bodies[2])
+	}
+
+	err = conn.MoveTaskToColumn("10", 0)
+	if err == nil || err.Error() != "invalid value (0) for columnID" {
+		t.Errorf("expected invalid columnID error but got (%v)", err)
+	}
+
+	err = conn.MoveTaskToColumn("", 3)
+	if err == nil || err.Error() != "missing required parameter(s): taskID" {
+		t.Errorf("expected missing taskID error but got (%v)", err)
+	}
+}
@@ -0,0 +1,641 @@
+package teamworkapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultBulkRequestsPerMinute keeps bulk operations below the default
+// Teamwork rate limit of 150 requests per minute.
+const defaultBulkRequestsPerMinute = 120
+
+// BulkSelector selects the tasks of a bulk operation: those matching Query, if
+// not nil, and those with the IDs listed.
+type BulkSelector struct {
+	Query   *TaskQueryParams
+	TaskIDs []string
+}
+
+// BulkUpdate describes the change applied to each task by BulkUpdateTasks.
+// Fields left empty are not changed.
+//
+// AssignedUserIDs is a comma separated list of person IDs; an empty string
+// unassigns the tasks.  StartDate and DueDate (YYYYMMDD) set the dates, while
+// ShiftDays moves the existing dates by a number of days, or business days of
+// Calendar if not nil.  Complete completes (true) or reopens (false) tasks.
+type BulkUpdate struct {
+	AssignedUserIDs *string
+	AddTags         []string
+	RemoveTags      []string
+	StartDate       string
+	DueDate         string
+	ShiftDays       int
+	Calendar        *BusinessCalendar
+	Complete        *bool
+}
+
+// BulkOptions configures a bulk operation.  Workers (default 4) tasks are
+// updated concurrently, making at most RequestsPerMinute (default 120)
+// requests.  Requests failing with a network error, 429 Too Many Requests or a
+// server error are retried up to Retries times, honoring any Retry-After
+// delay.  If DryRun is
+// true, the changes are reported without being made.
+type BulkOptions struct {
+	Workers           int
+	RequestsPerMinute int
+	Retries           int
+	DryRun            bool
+}
+
+// BulkTaskResult reports the fields of a task changed (or that would be
+// changed on a dry run) by a bulk operation, or the error that occurred.
+type BulkTaskResult struct {
+	TaskID string
+	Title  string
+	Fields []string
+	Err    error
+}
+
+// BulkResult reports the result of a bulk operation for each task, in the
+// order selected, and the log needed to undo it.
+type BulkResult struct {
+	Results []*BulkTaskResult
+	Undo    *BulkUndoLog
+}
+
+// BulkUndoEntry records the previous values of the fields of a task changed
+// by a bulk operation.  Nil fields, and Tags unless RestoreTags is true, were
+// not changed.
+type BulkUndoEntry struct {
+	TaskID         string   `json:"taskId"`
+	AssignedUserID *string  `json:"assignedUserId,omitempty"`
+	StartDate      *string  `json:"startDate,omitempty"`
+	DueDate        *string  `json:"dueDate,omitempty"`
+	Tags           []string `json:"tags,omitempty"`
+	RestoreTags    bool     `json:"restoreTags,omitempty"`
+	Completed      *bool    `json:"completed,omitempty"`
+}
+
+// BulkUndoLog records the changes of a bulk operation so they can be reverted
+// by UndoBulkUpdate.  It can be saved as JSON.
+type BulkUndoLog struct {
+	Entries []*BulkUndoEntry `json:"entries"`
+}
+
+// Failed returns the results of the tasks that could not be updated.
+func (r *BulkResult) Failed() []*BulkTaskResult {
+
+	var retVal []*BulkTaskResult
+
+	for _, res := range r.Results {
+		if res.Err != nil {
+			retVal = append(retVal, res)
+		}
+	}
+
+	return retVal
+}
+
+// bulkChange holds the requests needed to change a task.
+type bulkChange struct {
+	item     map[string]string
+	tags     []string
+	setTags  bool
+	complete *bool
+}
+
+// bulkJob holds a task to change.  Task is nil if the task has yet to be
+// retrieved.
+type bulkJob struct {
+	taskID string
+	task   *Task
+	undo   *BulkUndoEntry
+}
+
+// defaultRetryAfter is the pause after a 429 response without a Retry-After
+// header.
+const defaultRetryAfter = 10 * time.Second
+
+// rateLimiter spaces requests evenly over a minute, holding them all back
+// while paused after a 429 response.
+type rateLimiter struct {
+	ticker *time.Ticker
+
+	mu          sync.Mutex
+	pausedUntil time.Time
+}
+
+func newRateLimiter(perMinute int) *rateLimiter {
+	return &rateLimiter{ticker: time.NewTicker(time.Minute / time.Duration(perMinute))}
+}
+
+func (l *rateLimiter) wait() {
+
+	l.mu.Lock()
+	until := l.pausedUntil
+	l.mu.Unlock()
+
+	time.Sleep(time.Until(until))
+
+	<-l.ticker.C
+}
+
+// pause holds back requests for at least d.
+func (l *rateLimiter) pause(d time.Duration) {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if until := time.Now().Add(d); until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+}
+
+func (l *rateLimiter) stop() {
+	l.ticker.Stop()
+}
+
+// BulkUpdateTasks applies an update to the selected tasks.  An error is
+// returned if the update is invalid or the tasks cannot be selected; errors
+// updating individual tasks are reported in the results.
+func (conn *Connection) BulkUpdateTasks(sel BulkSelector, update BulkUpdate, opts BulkOptions) (*BulkResult, error) {
+
+	err := validateBulkUpdate(&update)
+	if err != nil {
+		return nil, err
+	}
+
+	limiter := newBulkLimiter(opts)
+	defer limiter.stop()
+
+	var jobs []*bulkJob
+	seen := make(map[string]bool)
+
+	if sel.Query != nil {
+
+		tasks, err := getTaskPages(*sel.Query, func(qp TaskQueryParams) ([]*Task, error) {
+			var page []*Task
+			err := retryBulk(limiter, opts.Retries, func() error {
+				var err error
+				page, err = conn.GetTasks(qp)
+				return err
+			})
+			return page, err
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, t := range tasks {
+			id := strconv.Itoa(t.ID)
+			if !seen[id] {
+				seen[id] = true
+				jobs = append(jobs, &bulkJob{taskID: id, task: t})
+			}
+		}
+	}
+
+	for _, id := range sel.TaskIDs {
+
+		err := validateID("taskID", id)
+		if err != nil {
+			return nil, err
+		}
+
+		if !seen[id] {
+			seen[id] = true
+			jobs = append(jobs, &bulkJob{taskID: id})
+		}
+	}
+
+	return conn.runBulk(jobs, opts, limiter, func(c *Connection, job *bulkJob) (*bulkChange, *BulkUndoEntry, error) {
+
+		if job.task == nil {
+			err := retryBulk(limiter, opts.Retries, func() error {
+				var err error
+				job.task, err = c.GetTaskByID(job.taskID)
+				return err
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		ch, undo := update.change(job.task)
+
+		return ch, undo, nil
+	}), nil
+}
+
+// UndoBulkUpdate reverts the changes recorded in the undo log of a bulk
+// operation.
+func (conn *Connection) UndoBulkUpdate(undo *BulkUndoLog, opts BulkOptions) (*BulkResult, error) {
+
+	if undo == nil {
+		return nil, fmt.Errorf("missing required parameter(s): undo")
+	}
+
+	limiter := newBulkLimiter(opts)
+	defer limiter.stop()
+
+	var jobs []*bulkJob
+
+	for _, e := range undo.Entries {
+		jobs = append(jobs, &bulkJob{taskID: e.TaskID, undo: e})
+	}
+
+	return conn.runBulk(jobs, opts, limiter, func(c *Connection, job *bulkJob) (*bulkChange, *BulkUndoEntry, error) {
+		return job.undo.change(), nil, nil
+	}), nil
+}
+
+func newBulkLimiter(opts BulkOptions) *rateLimiter {
+
+	perMinute := opts.RequestsPerMinute
+	if perMinute <= 0 {
+		perMinute = defaultBulkRequestsPerMinute
+	}
+
+	return newRateLimiter(perMinute)
+}
+
+// runBulk plans and makes the change to each task with a pool of workers.
+func (conn *Connection) runBulk(jobs []*bulkJob, opts BulkOptions, limiter *rateLimiter,
+	plan func(c *Connection, job *bulkJob) (*bulkChange, *BulkUndoEntry, error)) *BulkResult {
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = 4
+	}
+
+	results := make([]*BulkTaskResult, len(jobs))
+	undo := make([]*BulkUndoEntry, len(jobs))
+
+	queue := make(chan int)
+
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+
+		wg.Add(1)
+
+		go func() {
+
+			defer wg.Done()
+
+			// each worker has its own copy, as requests record their URL on
+			// the connection
+			c := *conn
+
+			for i := range queue {
+
+				job := jobs[i]
+				res := &BulkTaskResult{TaskID: job.taskID}
+				results[i] = res
+
+				ch, entry, err := plan(&c, job)
+				if err != nil {
+					res.Err = err
+					continue
+				}
+
+				if job.task != nil {
+					res.Title = job.task.Title
+				}
+
+				res.Fields = ch.fields()
+
+				if opts.DryRun || len(res.Fields) == 0 {
+					continue
+				}
+
+				undo[i], res.Err = c.applyBulkChange(job.taskID, ch, entry, limiter, opts.Retries)
+			}
+		}()
+	}
+
+	for i := range jobs {
+		queue <- i
+	}
+	close(queue)
+
+	wg.Wait()
+
+	retVal := &BulkResult{Results: results, Undo: new(BulkUndoLog)}
+
+	for _, e := range undo {
+		if e != nil {
+			retVal.Undo.Entries = append(retVal.Undo.Entries, e)
+		}
+	}
+
+	return retVal
+}
+
+// applyBulkChange makes the requests of a change.  Tasks are reopened before,
+// and completed after, other fields are changed.  The part of undo covering
+// the requests that succeeded is returned, or nil if undo is nil or no request
+// succeeded.
+func (conn *Connection) applyBulkChange(taskID string, ch *bulkChange, undo *BulkUndoEntry, limiter *rateLimiter, retries int) (*BulkUndoEntry, error) {
+
+	var applied *BulkUndoEntry
+
+	// record marks the fields of undo copied by step as applied
+	record := func(step func(a *BulkUndoEntry)) {
+		if undo == nil {
+			return
+		}
+		if applied == nil {
+			applied = &BulkUndoEntry{TaskID: undo.TaskID}
+		}
+		step(applied)
+	}
+
+	if ch.complete != nil && !*ch.complete {
+		err := retryBulk(limiter, retries, func() error { return conn.UncompleteTask(taskID) })
+		if err != nil {
+			return applied, err
+		}
+		record(func(a *BulkUndoEntry) { a.Completed = undo.Completed })
+	}
+
+	if len(ch.item) > 0 {
+
+		data, err := json.Marshal(map[string]map[string]string{"todo-item": ch.item})
+		if err != nil {
+			return applied, err
+		}
+
+		err = retryBulk(limiter, retries, func() error {
+			return conn.PutRequest("tasks/"+taskID, data, new(TaskItemResponseHandler))
+		})
+		if err != nil {
+			return applied, err
+		}
+		record(func(a *BulkUndoEntry) {
+			a.AssignedUserID = undo.AssignedUserID
+			a.StartDate = undo.StartDate
+			a.DueDate = undo.DueDate
+		})
+	}
+
+	if ch.setTags {
+		err := retryBulk(limiter, retries, func() error { return conn.SetTaskTags(taskID, ch.tags) })
+		if err != nil {
+			return applied, err
+		}
+		record(func(a *BulkUndoEntry) {
+			a.Tags = undo.Tags
+			a.RestoreTags = undo.RestoreTags
+		})
+	}
+
+	if ch.complete != nil && *ch.complete {
+		err := retryBulk(limiter, retries, func() error { return conn.CompleteTask(taskID) })
+		if err != nil {
+			return applied, err
+		}
+		record(func(a *BulkUndoEntry) { a.Completed = undo.Completed })
+	}
+
+	return applied, nil
+}
+
+// retryBulk makes a request, waiting for the rate limiter before each
+// attempt.  Only transient failures are retried: network errors, 429 Too Many
+// Requests, after pausing the limiter for the Retry-After delay, and server
+// errors.
+func retryBulk(limiter *rateLimiter, retries int, request func() error) error {
+
+	for attempt := 0; ; attempt++ {
+
+		limiter.wait()
+
+		err := request()
+		if err == nil || attempt >= retries || !retryable(err) {
+			return err
+		}
+
+		var httpErr *HTTPError
+		if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusTooManyRequests {
+			if httpErr.RetryAfter > 0 {
+				limiter.pause(httpErr.RetryAfter)
+			} else {
+				limiter.pause(defaultRetryAfter)
+			}
+		}
+	}
+}
+
+// retryable reports whether err is a transient failure worth retrying.
+func retryable(err error) bool {
+
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// fields returns the names of the fields changed.
+func (ch *bulkChange) fields() []string {
+
+	var retVal []string
+
+	if _, ok := ch.item["responsible-party-id"]; ok {
+		retVal = append(retVal, "AssignedUserIDs")
+	}
+
+	if _, ok := ch.item["start-date"]; ok {
+		retVal = append(retVal, "StartDate")
+	}
+
+	if _, ok := ch.item["due-date"]; ok {
+		retVal = append(retVal, "DueDate")
+	}
+
+	if ch.setTags {
+		retVal = append(retVal, "Tags")
+	}
+
+	if ch.complete != nil {
+		retVal = append(retVal, "Complete")
+	}
+
+	return retVal
+}
+
+// change returns the change needed to update a task, and the entry needed to
+// undo it.
+func (u *BulkUpdate) change(t *Task) (*bulkChange, *BulkUndoEntry) {
+
+	ch := &bulkChange{item: make(map[string]string)}
+	undo := &BulkUndoEntry{TaskID: strconv.Itoa(t.ID)}
+
+	if u.AssignedUserIDs != nil && !sameSet(splitIDs(t.AssignedUserID), splitIDs(*u.AssignedUserIDs)) {
+		prev := t.AssignedUserID
+		ch.item["responsible-party-id"] = *u.AssignedUserIDs
+		undo.AssignedUserID = &prev
+	}
+
+	var current, tags []string
+
+	for _, tag := range t.Tags {
+		current = append(current, tag.Name)
+		if !containsFold(u.RemoveTags, tag.Name) {
+			tags = append(tags, tag.Name)
+		}
+	}
+
+	for _, name := range u.AddTags {
+		if !containsFold(tags, name) {
+			tags = append(tags, name)
+		}
+	}
+
+	if !sameFold(current, tags) {
+		ch.tags = tags
+		ch.setTags = true
+		undo.Tags = current
+		undo.RestoreTags = true
+	}
+
+	start := u.shift(t.StartDate, u.StartDate)
+	if start != t.StartDate {
+		prev := t.StartDate
+		ch.item["start-date"] = start
+		undo.StartDate = &prev
+	}
+
+	due := u.shift(t.DueDate, u.DueDate)
+	if due != t.DueDate {
+		prev := t.DueDate
+		ch.item["due-date"] = due
+		undo.DueDate = &prev
+	}
+
+	completed := t.Status == "completed"
+	if u.Complete != nil && *u.Complete != completed {
+		ch.complete = u.Complete
+		undo.Completed = &completed
+	}
+
+	return ch, undo
+}
+
+// shift returns the date value if set, otherwise the current date moved by
+// ShiftDays.
+func (u *BulkUpdate) shift(current string, value string) string {
+
+	if value != "" {
+		return value
+	}
+
+	if current == "" || u.ShiftDays == 0 {
+		return current
+	}
+
+	d, err := time.Parse(TeamworkDateFormatShort, current)
+	if err != nil {
+		return current
+	}
+
+	if u.Calendar != nil {
+		d = u.Calendar.AddBusinessDays(d, u.ShiftDays)
+	} else {
+		d = d.AddDate(0, 0, u.ShiftDays)
+	}
+
+	return d.Format(TeamworkDateFormatShort)
+}
+
+// change returns the change needed to restore the previous values of a task.
+func (e *BulkUndoEntry) change() *bulkChange {
+
+	ch := &bulkChange{item: make(map[string]string)}
+
+	if e.AssignedUserID != nil {
+		ch.item["responsible-party-id"] = *e.AssignedUserID
+	}
+
+	if e.StartDate != nil {
+		ch.item["start-date"] = *e.StartDate
+	}
+
+	if e.DueDate != nil {
+		ch.item["due-date"] = *e.DueDate
+	}
+
+	if e.RestoreTags {
+		ch.tags = e.Tags
+		ch.setTags = true
+	}
+
+	ch.complete = e.Completed
+
+	return ch
+}
+
+// containsFold reports whether s holds v, ignoring case.
+func containsFold(s []string, v string) bool {
+
+	for _, x := range s {
+		if strings.EqualFold(strings.TrimSpace(x), strings.TrimSpace(v)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sameFold reports whether a and b hold the same values, ignoring order and
+// case.
+func sameFold(a []string, b []string) bool {
+
+	for _, v := range a {
+		if !containsFold(b, v) {
+			return false
+		}
+	}
+
+	for _, v := range b {
+		if !containsFold(a, v) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func validateBulkUpdate(u *BulkUpdate) error {
+
+	if u.AssignedUserIDs == nil && len(u.AddTags) == 0 && len(u.RemoveTags) == 0 &&
+		u.StartDate == "" && u.DueDate == "" && u.ShiftDays == 0 && u.Complete == nil {
+		return fmt.Errorf("bulk update has no changes")
+	}
+
+	if u.AssignedUserIDs != nil {
+		for _, id := range splitIDs(*u.AssignedUserIDs) {
+			err := validateID("AssignedUserIDs", id)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	err := validateDateParam("StartDate", u.StartDate, TeamworkDateFormatShort)
+	if err != nil {
+		return err
+	}
+
+	return validateDateParam("DueDate", u.DueDate, TeamworkDateFormatShort)
+}
@@ -0,0 +1,277 @@
+package teamworkapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBulkUpdateTasks(t *testing.T) {
+
+	var mu sync.Mutex
+	var writes []string
+
+	conn := initMockConnection(t, func(w http.ResponseWriter, r *http.Request) {
+
+		raw, _ := ioutil.ReadAll(r.Body)
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/tasks.json":
+			fmt.Fprint(w, `{"STATUS": "OK", "todo-items": [
+				{"id": 1, "content": "Patch review", "responsible-party-id": "5", "due-date": "20210301", "tags": [{"name": "Ops"}]},
+				{"id": 2, "content": "Backup audit", "status": "completed"}
+			]}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/tasks/3.json":
+			fmt.Fprint(w, `{"STATUS": "OK", "todo-item": {"id": 3, "content": "Rotate keys", "responsible-party-id": "7", "start-date": "20210305", "due-date": "20210310"}}`)
+		case r.Method == http.MethodGet:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"STATUS": "Error", "MESSAGE": "Not found"}`)
+		default:
+			mu.Lock()
+			writes = append(writes, r.Method+" "+r.URL.Path+" "+string(raw))
+			mu.Unlock()
+			fmt.Fprint(w, `{"STATUS": "OK"}`)
+		}
+	})
+
+	assignee := "7"
+	complete := true
+
+	update := BulkUpdate{AssignedUserIDs: &assignee, AddTags: []string{"Audit"}, RemoveTags: []string{"ops"}, ShiftDays: 2, Complete: &complete}
+	sel := BulkSelector{Query: &TaskQueryParams{ProjectIDs: "1"}, TaskIDs: []string{"3", "1", "4"}}
+	opts := BulkOptions{Workers: 3, RequestsPerMinute: 600000}
+
+	dryRun := opts
+	dryRun.DryRun = true
+
+	res, err := conn.BulkUpdateTasks(sel, update, dryRun)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if len(writes) != 0 || len(res.Undo.Entries) != 0 {
+		t.Errorf("expected no changes on a dry run but got %v", writes)
+	}
+
+	res, err = conn.BulkUpdateTasks(sel, update, opts)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	var got []string
+	for _, r := range res.Results {
+		got = append(got, r.TaskID+":"+strings.Join(r.Fields, ","))
+	}
+
+	want := []string{
+		"1:AssignedUserIDs,DueDate,Tags,Complete",
+		"2:AssignedUserIDs,Tags",
+		"3:StartDate,DueDate,Tags,Complete",
+		"4:",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected results %v but got %v", want, got)
+	}
+
+	if len(res.Failed()) != 1 || res.Failed()[0].TaskID != "4" {
+		t.Errorf("expected task 4 to fail but got %v", res.Failed())
+	}
+
+	sort.Strings(writes)
+
+	wantWrites := []string{
+		`PUT /tasks/1.json {"todo-item":{"due-date":"20210303","responsible-party-id":"7"}}`,
+		`PUT /tasks/1/complete.json `,
+		`PUT /tasks/1/tags.json {"replaceExistingTags":true,"tags":{"content":"Audit"}}`,
+		`PUT /tasks/2.json {"todo-item":{"responsible-party-id":"7"}}`,
+		`PUT /tasks/2/tags.json {"replaceExistingTags":true,"tags":{"content":"Audit"}}`,
+		`PUT /tasks/3.json {"todo-item":{"due-date":"20210312","start-date":"20210307"}}`,
+		`PUT /tasks/3/complete.json `,
+		`PUT /tasks/3/tags.json {"replaceExistingTags":true,"tags":{"content":"Audit"}}`,
+	}
+
+	if !reflect.DeepEqual(writes, wantWrites) {
+		t.Errorf("expected writes %v but got %v", wantWrites, writes)
+	}
+
+	// the undo log survives a round trip through JSON
+	data, err := json.Marshal(res.Undo)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	undo := new(BulkUndoLog)
+
+	err = json.Unmarshal(data, undo)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if len(undo.Entries) != 3 {
+		t.Fatalf("expected 3 undo entries but got %d", len(undo.Entries))
+	}
+
+	writes = nil
+
+	res, err = conn.UndoBulkUpdate(undo, opts)
+	if err != nil || len(res.Failed()) != 0 {
+		t.Fatalf("expected undo to succeed but got (%v) %v", err, res.Failed())
+	}
+
+	sort.Strings(writes)
+
+	wantWrites = []string{
+		`PUT /tasks/1.json {"todo-item":{"due-date":"20210301","responsible-party-id":"5"}}`,
+		`PUT /tasks/1/tags.json {"replaceExistingTags":true,"tags":{"content":"Ops"}}`,
+		`PUT /tasks/1/uncomplete.json `,
+		`PUT /tasks/2.json {"todo-item":{"responsible-party-id":""}}`,
+		`PUT /tasks/2/tags.json {"replaceExistingTags":true,"tags":{"content":""}}`,
+		`PUT /tasks/3.json {"todo-item":{"due-date":"20210310","start-date":"20210305"}}`,
+		`PUT /tasks/3/tags.json {"replaceExistingTags":true,"tags":{"content":""}}`,
+		`PUT /tasks/3/uncomplete.json `,
+	}
+
+	if !reflect.DeepEqual(writes, wantWrites) {
+		t.Errorf("expected undo writes %v but got %v", wantWrites, writes)
+	}
+}
+
+func TestValidateBulkUpdate(t *testing.T) {
+
+	assignees := "5,x"
+
+	var tests = []struct {
+		update BulkUpdate
+		want   string
+	}{
+		{BulkUpdate{}, "bulk update has no changes"},
+		{BulkUpdate{AssignedUserIDs: &assignees}, "invalid value (x) for AssignedUserIDs"},
+		{BulkUpdate{DueDate: "2021-03-01"}, "invalid format for DueDate parameter.  Should be YYYYMMDD, but found 2021-03-01"},
+	}
+
+	for _, v := range tests {
+		err := validateBulkUpdate(&v.update)
+		if err == nil || err.Error() != v.want {
+			t.Errorf("expected error (%s) but got (%v)", v.want, err)
+		}
+	}
+}
+
+func TestBulkUpdateTasksUndoOnlyApplied(t *testing.T) {
+
+	conn := initMockConnection(t, func(w http.ResponseWriter, r *http.Request) {
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/tasks/1.json":
+			fmt.Fprint(w, `{"STATUS": "OK", "todo-item": {"id": 1, "content": "Patch review", "responsible-party-id": "5", "tags": [{"name": "Ops"}]}}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/tasks/2.json":
+			fmt.Fprint(w, `{"STATUS": "OK", "todo-item": {"id": 2, "content": "Backup audit", "responsible-party-id": "5"}}`)
+		case r.URL.Path == "/tasks/1/tags.json", r.URL.Path == "/tasks/2.json":
+			// the tags of task 1, and every change to task 2, fail
+			fmt.Fprint(w, `{"STATUS": "Error", "MESSAGE": "Forbidden"}`)
+		default:
+			fmt.Fprint(w, `{"STATUS": "OK"}`)
+		}
+	})
+
+	assignee := "7"
+
+	update := BulkUpdate{AssignedUserIDs: &assignee, AddTags: []string{"Audit"}}
+	sel := BulkSelector{TaskIDs: []string{"1", "2"}}
+
+	res, err := conn.BulkUpdateTasks(sel, update, BulkOptions{RequestsPerMinute: 600000})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if len(res.Failed()) != 2 {
+		t.Errorf("expected both tasks to fail but got %v", res.Failed())
+	}
+
+	if len(res.Undo.Entries) != 1 {
+		t.Fatalf("expected 1 undo entry but got %d", len(res.Undo.Entries))
+	}
+
+	e := res.Undo.Entries[0]
+
+	if e.TaskID != "1" || e.AssignedUserID == nil || *e.AssignedUserID != "5" || e.RestoreTags || e.Tags != nil {
+		t.Errorf("expected undo entry to restore only the assignee of task 1 but got %+v", e)
+	}
+}
+
+func TestBulkUpdateTasksRetries(t *testing.T) {
+
+	var mu sync.Mutex
+	attempts := make(map[string]int)
+
+	conn := initMockConnection(t, func(w http.ResponseWriter, r *http.Request) {
+
+		key := r.Method + " " + r.URL.Path + "?" + r.URL.Query().Get("page")
+
+		mu.Lock()
+		attempts[key]++
+		n := attempts[key]
+		mu.Unlock()
+
+		switch {
+		case key == "GET /tasks.json?1":
+			fmt.Fprint(w, `{"STATUS": "OK", "todo-items": [{"id": 1}, {"id": 2}]}`)
+		case key == "GET /tasks.json?2" && n == 1:
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case key == "GET /tasks.json?2":
+			fmt.Fprint(w, `{"STATUS": "OK", "todo-items": [{"id": 3}]}`)
+		case r.URL.Path == "/tasks/1/complete.json" && n == 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case r.URL.Path == "/tasks/2/complete.json":
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"STATUS": "Error", "MESSAGE": "Invalid task"}`)
+		default:
+			fmt.Fprint(w, `{"STATUS": "OK"}`)
+		}
+	})
+
+	complete := true
+
+	sel := BulkSelector{Query: &TaskQueryParams{ProjectIDs: "1", PageSize: "2"}}
+	opts := BulkOptions{Workers: 2, RequestsPerMinute: 600000, Retries: 2}
+
+	start := time.Now()
+
+	res, err := conn.BulkUpdateTasks(sel, BulkUpdate{Complete: &complete}, opts)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("expected the Retry-After delay to be honored but finished in %v", elapsed)
+	}
+
+	if len(res.Results) != 3 {
+		t.Fatalf("expected 3 results across both pages but got %d", len(res.Results))
+	}
+
+	if len(res.Failed()) != 1 || res.Failed()[0].TaskID != "2" {
+		t.Errorf("expected task 2 to fail but got %v", res.Failed())
+	}
+
+	want := map[string]int{
+		"GET /tasks.json?1":           1,
+		"GET /tasks.json?2":           2,
+		"PUT /tasks/1/complete.json?": 2,
+		"PUT /tasks/2/complete.json?": 1,
+		"PUT /tasks/3/complete.json?": 1,
+	}
+
+	if !reflect.DeepEqual(attempts, want) {
+		t.Errorf("expected attempts %v but got %v", want, attempts)
+	}
+}
@@ -3,22 +3,34 @@ package teamworkapi
 import (
 	"encoding/json"
 	"fmt"
-	"time"
+	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/google/go-querystring/query"
 )
 
 // CalendarEvent models a Teamwork Calendar event.
 type CalendarEvent struct {
-	ID          string     `json:"id"`
-	Title       string     `json:"title"`
-	Description string     `json:"description"`
-	Start       string     `json:"start"`
-	End         string     `json:"end"`
-	AllDay      bool       `json:"all-day"`
-	Type        *EventType `json:"type"`
-	AttendeeIDs string     `json:"attending-user-ids"`
-	Status      string     `json:"status"`
+	ID          string                `json:"id"`
+	Title       string                `json:"title"`
+	Description string                `json:"description"`
+	Start       string                `json:"start"`
+	End         string                `json:"end"`
+	AllDay      bool                  `json:"all-day"`
+	Type        *EventType            `json:"type"`
+	AttendeeIDs string                `json:"attending-user-ids"`
+	Status      string                `json:"status"`
+	Where       string                `json:"where,omitempty"`
+	NotifyIDs   string                `json:"notify-user-ids,omitempty"`
+	Privacy     *CalendarEventPrivacy `json:"privacy,omitempty"`
+	Repeat      *CalendarEventRepeat  `json:"repeat,omitempty"`
+}
+
+// CalendarEventJSON provides a wrapper around CalendarEvent to properly marshal json
+// data when posting to API.
+type CalendarEventJSON struct {
+	Event *CalendarEvent `json:"event"`
 }
 
 // CalendarEventsJSON models the parent JSON structure of an array of CalendarEvent and
@@ -26,6 +38,9 @@ type CalendarEvent struct {
 type CalendarEventsJSON struct {
 	Events []*CalendarEvent `json:"events"`
 }
+
+// CalendarEventsJSONV3 models the parent JSON structure of an array of
+// CalendarEventsV3JSON and facilitates unmarshalling.
 type CalendarEventsJSONV3 struct {
 	Events []*CalendarEventsV3JSON `json:"calendarEvents"`
 }
@@ -37,10 +52,34 @@ type EventType struct {
 	Color string `json:"color"`
 }
 
+// EventTypesJSON models the parent JSON structure of an array of EventTypes and
+// facilitates unmarshalling.
+type EventTypesJSON struct {
+	EventTypes []*EventType `json:"eventtypes"`
+}
+
+// CalendarEventPrivacy models who is able to see a Calendar event.  Type is
+// one of "all", "private" or "custom".
+type CalendarEventPrivacy struct {
+	Type string `json:"type"`
+}
+
+// CalendarEventRepeat models the recurrence of a Calendar event.  Frequency is
+// one of "noRepeat", "daily", "weekly", "monthly" or "yearly".
+type CalendarEventRepeat struct {
+	Frequency    string   `json:"frequency,omitempty"`
+	Interval     int      `json:"interval,omitempty"`
+	SelectedDays string   `json:"selecteddays,omitempty"` // e.g. "Mon,Wed,Fri"
+	EndsOn       string   `json:"endsOn,omitempty"`       // expected format is YYYYMMDD
+	Count        int      `json:"count,omitempty"`
+	Exceptions   []string `json:"exceptions,omitempty"` // dates of skipped occurrences, YYYYMMDD
+}
+
 // CalendarEventResponseHandler models a http response for a Calendar Event operation.
 type CalendarEventResponseHandler struct {
 	Status  string `json:"STATUS"`
 	Message string `json:"MESSAGE"`
+	ID      string `json:"id"`
 }
 
 // CalendarEventQueryParams defines valid query parameters for this resource.
@@ -56,34 +95,65 @@ type CalendarEventQueryParamsV3 struct {
 	StartDate string `url:"startDate,omitempty"`
 	ProjectID string `url:"projectId,omitempty"`
 	PageSize string  `url:"pageSize,omitempty"`
+	Page      string `url:"page,omitempty"`
 }
 
+// CalendarEventsV3JSON models a Teamwork Calendar event using version 3 of
+// teamwork api.
 type CalendarEventsV3JSON struct {
-	AttendingUserIds []int  `json:"attendingUserIds"`
-	TypeId           int    `json:"typeId"`
-	OwnerUserId      int    `json:"ownerUserId"`            
-	StartDate        string `json:"startDate"`
-	EndDate          string `json:"endDate"`
-	AllDay  		 bool   `json:"allDay"`       
+	ID               int                   `json:"id"`
+	Title            string                `json:"title"`
+	Description      string                `json:"description"`
+	Location         string                `json:"where"`
+	AttendingUserIds []int                 `json:"attendingUserIds"`
+	TypeId           int                   `json:"typeId"`
+	OwnerUserId      int                   `json:"ownerUserId"`
+	ProjectID        int                   `json:"projectId"`
+	StartDate        string                `json:"startDate"`
+	EndDate          string                `json:"endDate"`
+	AllDay           bool                  `json:"allDay"`
+	Privacy          *CalendarEventPrivacy `json:"privacy"`
+	Repeat           *CalendarEventRepeat  `json:"repeat"`
+}
+
+// ParseResponse interprets a http response for a Calendar Event operation such
+// as POST, PUT, DELETE.
+func (resMsg *CalendarEventResponseHandler) ParseResponse(httpMethod string, rawRes []byte) error {
+
+	err := json.Unmarshal(rawRes, &resMsg)
+	if err != nil {
+		return err
+	}
+
+	if resMsg.Status == "Error" {
+		return fmt.Errorf("received ERROR response: %s", resMsg.Message)
+	}
+
+	switch httpMethod {
+	case http.MethodPost:
+		if resMsg.ID == "" {
+			return fmt.Errorf("no ID returned for calendar event POST")
+		}
+	}
+
+	return nil
 }
 
 // FormatQueryParams formats query parameters for this resource.
 func (qp CalendarEventQueryParams) FormatQueryParams() (string, error) {
 
-	if qp.From != "" {
-		_, err := time.Parse("20060102", qp.From)
-		if err != nil {
-			return "", fmt.Errorf("invalid format for From parameter.  Should be YYYYMMDD, but found %s", qp.From)
-		}
-	} else {
+	if qp.From == "" {
 		return "", fmt.Errorf("missing required parameter 'From'")
 	}
 
-	if qp.To != "" {
-		_, err := time.Parse("20060102", qp.To)
-		if err != nil {
-			return "", fmt.Errorf("invalid format for To parameter.  Should be YYYYMMDD, but found %s", qp.To)
-		}
+	err := validateDateParam("From", qp.From, TeamworkDateFormatShort)
+	if err != nil {
+		return "", err
+	}
+
+	err = validateDateParam("To", qp.To, TeamworkDateFormatShort)
+	if err != nil {
+		return "", err
 	}
 
 	params, err := query.Values(qp)
@@ -95,18 +165,14 @@ func (qp CalendarEventQueryParams) FormatQueryParams() (string, error) {
 }
 func (qp CalendarEventQueryParamsV3) FormatQueryParamsV3() (string, error) {
 
-	if qp.StartDate != "" {
-		_, err := time.Parse("2006-01-02", qp.StartDate)
-		if err != nil {
-			return "", fmt.Errorf("invalid format for FromDate parameter.  Should be YYYY-MM-DD, but found %s", qp.StartDate)
-		}
+	err := validateDateParam("StartDate", qp.StartDate, TeamworkDateFormatV3)
+	if err != nil {
+		return "", err
 	}
 
-	if qp.EndDate != "" {
-		_, err := time.Parse("2006-01-02", qp.EndDate)
-		if err != nil {
-			return "", fmt.Errorf("invalid format for ToDate parameter.  Should be YYYY-MM-DD, but found %s", qp.EndDate)
-		}
+	err = validateDateParam("EndDate", qp.EndDate, TeamworkDateFormatV3)
+	if err != nil {
+		return "", err
 	}
 
 	s, err := query.Values(qp)
@@ -151,3 +217,279 @@ func (conn *Connection) GetCalendarEventsV3(queryParams CalendarEventQueryParams
 
 	return events.Events, nil
 }
+
+// GetAllCalendarEventsV3 retrieves every page of calendar events specified by
+// queryParams.  The Page parameter is ignored.
+func (conn *Connection) GetAllCalendarEventsV3(queryParams CalendarEventQueryParamsV3) ([]*CalendarEventsV3JSON, error) {
+
+	var retVal []*CalendarEventsV3JSON
+
+	err := conn.getPagesV3("calendar/events", func(page int) QueryParamsV3 {
+		queryParams.Page = strconv.Itoa(page)
+		return queryParams
+	}, func(data []byte) error {
+
+		events := new(CalendarEventsJSONV3)
+
+		err := json.Unmarshal(data, &events)
+		if err != nil {
+			return err
+		}
+
+		retVal = append(retVal, events.Events...)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return retVal, nil
+}
+
+// GetCalendarEventByID retrieves a specific calendar event based on ID.
+func (conn *Connection) GetCalendarEventByID(ID string) (*CalendarEvent, error) {
+
+	_, err := strconv.Atoi(ID)
+	if err != nil {
+		if ID == "" {
+			return nil, fmt.Errorf("missing required parameter(s): ID")
+		}
+		return nil, fmt.Errorf("invalid value (%s) for ID", ID)
+	}
+
+	data, err := conn.GetRequest("calendarevents/"+ID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	e := new(CalendarEventJSON)
+
+	err = json.Unmarshal(data, &e)
+	if err != nil {
+		return nil, err
+	}
+
+	if e.Event == nil {
+		return nil, fmt.Errorf("failed to retrieve calendar event with ID (%s)", ID)
+	}
+
+	return e.Event, nil
+}
+
+// PostCalendarEvent creates a calendar event.  The ID of the new event is
+// returned and stored in event.
+func (conn *Connection) PostCalendarEvent(event *CalendarEvent) (string, error) {
+
+	err := validateCalendarEvent(event)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(CalendarEventJSON{Event: event})
+	if err != nil {
+		return "", err
+	}
+
+	handler := new(CalendarEventResponseHandler)
+
+	err = conn.PostRequest("calendarevents", data, handler)
+	if err != nil {
+		return "", err
+	}
+
+	event.ID = handler.ID
+
+	return handler.ID, nil
+}
+
+// PutCalendarEvent updates the calendar event identified by event.ID.
+func (conn *Connection) PutCalendarEvent(event *CalendarEvent) error {
+
+	if event == nil || event.ID == "" {
+		return fmt.Errorf("missing required parameter(s): ID")
+	}
+
+	err := validateCalendarEvent(event)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(CalendarEventJSON{Event: event})
+	if err != nil {
+		return err
+	}
+
+	return conn.PutRequest("calendarevents/"+event.ID, data, new(CalendarEventResponseHandler))
+}
+
+// DeleteCalendarEvent deletes a calendar event with the specified ID.
+func (conn *Connection) DeleteCalendarEvent(ID string) error {
+
+	err := validateID("ID", ID)
+	if err != nil {
+		return err
+	}
+
+	return conn.DeleteRequest("calendarevents/"+ID, new(CalendarEventResponseHandler))
+}
+
+// AddCalendarEventAttendees adds people to the attendees of a calendar event.
+// People already attending are ignored.
+//
+// The Teamwork calendar API records who attends an event but not their
+// response to it, so RSVPs cannot be read or set; attendance is managed by
+// adding and removing attendees instead.
+func (conn *Connection) AddCalendarEventAttendees(eventID string, personIDs ...string) error {
+
+	event, err := conn.GetCalendarEventByID(eventID)
+	if err != nil {
+		return err
+	}
+
+	attendees := splitIDs(event.AttendeeIDs)
+
+	for _, id := range personIDs {
+		if !containsString(attendees, id) {
+			attendees = append(attendees, id)
+		}
+	}
+
+	event.AttendeeIDs = strings.Join(attendees, ",")
+
+	return conn.PutCalendarEvent(event)
+}
+
+// RemoveCalendarEventAttendees removes people from the attendees of a calendar
+// event.
+func (conn *Connection) RemoveCalendarEventAttendees(eventID string, personIDs ...string) error {
+
+	event, err := conn.GetCalendarEventByID(eventID)
+	if err != nil {
+		return err
+	}
+
+	var attendees []string
+
+	for _, id := range splitIDs(event.AttendeeIDs) {
+		if !containsString(personIDs, id) {
+			attendees = append(attendees, id)
+		}
+	}
+
+	event.AttendeeIDs = strings.Join(attendees, ",")
+
+	return conn.PutCalendarEvent(event)
+}
+
+// GetCalendarEventTypes retrieves all calendar event types.
+func (conn *Connection) GetCalendarEventTypes() ([]*EventType, error) {
+
+	data, err := conn.GetRequest("calendareventtypes", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	types := new(EventTypesJSON)
+
+	err = json.Unmarshal(data, &types)
+	if err != nil {
+		return nil, err
+	}
+
+	return types.EventTypes, nil
+}
+
+// GetCalendarEventTypeByName retrieves the calendar event type with the
+// specified name (case-insensitive).
+func (conn *Connection) GetCalendarEventTypeByName(name string) (*EventType, error) {
+
+	if name == "" {
+		return nil, fmt.Errorf("missing required parameter(s): name")
+	}
+
+	types, err := conn.GetCalendarEventTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range types {
+		if strings.EqualFold(t.Name, name) {
+			return t, nil
+		}
+	}
+
+	return nil, fmt.Errorf("failed to retrieve calendar event type with name (%s)", name)
+}
+
+func validateCalendarEvent(event *CalendarEvent) error {
+
+	if event == nil {
+		return fmt.Errorf("missing required parameter(s): event")
+	}
+
+	errBuff := ""
+
+	if event.Title == "" {
+		errBuff += "Title"
+	}
+
+	if event.Start == "" {
+		if errBuff != "" {
+			errBuff += ", "
+		}
+		errBuff += "Start"
+	}
+
+	if event.End == "" {
+		if errBuff != "" {
+			errBuff += ", "
+		}
+		errBuff += "End"
+	}
+
+	if errBuff != "" {
+		return fmt.Errorf("calendar event is missing required field(s): %s", errBuff)
+	}
+
+	start, err := ParseTeamworkDate(event.Start)
+	if err != nil {
+		return fmt.Errorf("invalid format for Start field (%s)", event.Start)
+	}
+
+	end, err := ParseTeamworkDate(event.End)
+	if err != nil {
+		return fmt.Errorf("invalid format for End field (%s)", event.End)
+	}
+
+	if end.Before(start) {
+		return fmt.Errorf("calendar event ends (%s) before it starts (%s)", event.End, event.Start)
+	}
+
+	return nil
+}
+
+// splitIDs splits a comma separated list of IDs, ignoring empty values.
+func splitIDs(s string) []string {
+
+	var retVal []string
+
+	for _, id := range strings.Split(s, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			retVal = append(retVal, id)
+		}
+	}
+
+	return retVal
+}
+
+func containsString(values []string, s string) bool {
+
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
@@ -6,6 +6,8 @@ import (
 	"os"
 	"io/ioutil"
 	"encoding/json"
+	"net/http"
+	"strings"
 )
 
 func initCalendarEventTestConnection(t *testing.T) *Connection {
@@ -94,3 +96,103 @@ func TestGetCalendarEventsV3(t *testing.T) {
 
 	}
 }
+
+func TestCalendarEventCRUD(t *testing.T) {
+
+	var lastBody CalendarEventJSON
+
+	conn := initMockConnection(t, func(w http.ResponseWriter, r *http.Request) {
+
+		if r.Method == http.MethodPost || r.Method == http.MethodPut {
+			raw, _ := ioutil.ReadAll(r.Body)
+			json.Unmarshal(raw, &lastBody)
+		}
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/calendarevents.json":
+			fmt.Fprint(w, `{"STATUS": "OK", "id": "42"}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/calendarevents/42.json":
+			fmt.Fprint(w, `{"STATUS": "OK", "event": {"id": "42", "title": "Leave", "start": "2021-03-01T00:00", "end": "2021-03-02T00:00", "attending-user-ids": "1,2"}}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/calendareventtypes.json":
+			fmt.Fprint(w, `{"STATUS": "OK", "eventtypes": [{"id": "7", "name": "Vacation"}, {"id": "8", "name": "Meeting"}]}`)
+		case r.Method == http.MethodPut, r.Method == http.MethodDelete:
+			fmt.Fprint(w, `{"STATUS": "OK"}`)
+		default:
+			fmt.Fprint(w, `{"STATUS": "Error", "MESSAGE": "not found"}`)
+		}
+	})
+
+	event := &CalendarEvent{
+		Title:   "Leave",
+		Start:   "2021-03-01T00:00",
+		End:     "2021-03-02T00:00",
+		AllDay:  true,
+		Type:    &EventType{ID: "7"},
+		Privacy: &CalendarEventPrivacy{Type: "all"},
+	}
+
+	id, err := conn.PostCalendarEvent(event)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if id != "42" || event.ID != "42" {
+		t.Errorf("expected ID (42) but got (%s)", id)
+	}
+
+	if lastBody.Event == nil || lastBody.Event.Privacy.Type != "all" {
+		t.Errorf("expected event privacy to be posted")
+	}
+
+	err = conn.AddCalendarEventAttendees("42", "2", "3")
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+
+	if lastBody.Event.AttendeeIDs != "1,2,3" {
+		t.Errorf("expected attendees (1,2,3) but got (%s)", lastBody.Event.AttendeeIDs)
+	}
+
+	err = conn.RemoveCalendarEventAttendees("42", "1")
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+
+	if lastBody.Event.AttendeeIDs != "2" {
+		t.Errorf("expected attendees (2) but got (%s)", lastBody.Event.AttendeeIDs)
+	}
+
+	err = conn.DeleteCalendarEvent("42")
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+
+	err = conn.DeleteCalendarEvent("abc")
+	if err == nil || err.Error() != "invalid value (abc) for ID" {
+		t.Errorf("expected invalid ID error but got (%v)", err)
+	}
+
+	eventType, err := conn.GetCalendarEventTypeByName("vacation")
+	if err != nil {
+		t.Errorf(err.Error())
+	} else if eventType.ID != "7" {
+		t.Errorf("expected event type ID (7) but got (%s)", eventType.ID)
+	}
+
+	// test error responses
+	var tests = []struct {
+		event *CalendarEvent
+		want  string
+	}{
+		{&CalendarEvent{}, "calendar event is missing required field(s): Title, Start, End"},
+		{&CalendarEvent{Title: "Leave", Start: "03/01/2021", End: "2021-03-02T00:00"}, "invalid format for Start field (03/01/2021)"},
+		{&CalendarEvent{Title: "Leave", Start: "2021-03-02T00:00", End: "2021-03-01T00:00"}, "calendar event ends (2021-03-01T00:00) before it starts (2021-03-02T00:00)"},
+	}
+
+	for _, v := range tests {
+		_, err := conn.PostCalendarEvent(v.event)
+		if err == nil || !strings.Contains(err.Error(), v.want) {
+			t.Errorf("expected error (%s) but got (%v)", v.want, err)
+		}
+	}
+}
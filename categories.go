@@ -0,0 +1,288 @@
+package teamworkapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// CategoryType identifies the kind of resource a category groups.
+type CategoryType string
+
+// Supported category types.
+const (
+	CategoryTypeFile     CategoryType = "file"
+	CategoryTypeNotebook CategoryType = "notebook"
+	CategoryTypeLink     CategoryType = "link"
+)
+
+// Category models a Teamwork file, notebook or link category.  Categories are
+// nested through ParentID, which is empty or "0" for top level categories.
+type Category struct {
+	ID        string `json:"id,omitempty"`
+	Name      string `json:"name"`
+	ParentID  string `json:"parent-id,omitempty"`
+	ProjectID string `json:"project-id,omitempty"`
+}
+
+// CategoriesJSON models the parent JSON structure of an array of Categories
+// and facilitates unmarshalling.
+type CategoriesJSON struct {
+	Categories []*Category `json:"categories"`
+}
+
+// CategoryJSON models the parent JSON structure of a single Category and
+// facilitates marshalling.
+type CategoryJSON struct {
+	Category *Category `json:"category"`
+}
+
+// CategoryResponseHandler models a http response for a Category operation.
+type CategoryResponseHandler struct {
+	Status  string `json:"STATUS"`
+	Message string `json:"MESSAGE"`
+	ID      string `json:"categoryId"`
+}
+
+// ParseResponse interprets the http response for a Category operation.
+func (resMsg *CategoryResponseHandler) ParseResponse(httpMethod string, rawRes []byte) error {
+
+	err := json.Unmarshal(rawRes, &resMsg)
+	if err != nil {
+		return err
+	}
+
+	if resMsg.Status == "Error" {
+		return fmt.Errorf("received ERROR response: %s", resMsg.Message)
+	}
+
+	switch httpMethod {
+	case http.MethodPost:
+		if resMsg.ID == "" {
+			return fmt.Errorf("no ID returned for Category POST request")
+		}
+	}
+
+	return nil
+}
+
+// endpoint returns the endpoint of the category type, e.g. "filecategories".
+func (ct CategoryType) endpoint() (string, error) {
+
+	switch ct {
+	case CategoryTypeFile, CategoryTypeNotebook, CategoryTypeLink:
+		return string(ct) + "categories", nil
+	}
+
+	return "", fmt.Errorf("invalid category type (%s)", ct)
+}
+
+// GetCategories retrieves the categories of the specified type in a project.
+func (conn *Connection) GetCategories(ct CategoryType, projectID string) ([]*Category, error) {
+
+	endpoint, err := ct.endpoint()
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateID("projectID", projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := conn.GetRequest("projects/"+projectID+"/"+endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	categories := new(CategoriesJSON)
+
+	err = json.Unmarshal(data, &categories)
+	if err != nil {
+		return nil, err
+	}
+
+	return categories.Categories, nil
+}
+
+// PostCategory creates a category of the specified type in a project.  If
+// parentID is not empty, the category is nested under it.  The ID of the new
+// category is returned.
+func (conn *Connection) PostCategory(ct CategoryType, projectID string, name string, parentID string) (string, error) {
+
+	endpoint, err := ct.endpoint()
+	if err != nil {
+		return "", err
+	}
+
+	err = validateID("projectID", projectID)
+	if err != nil {
+		return "", err
+	}
+
+	if strings.TrimSpace(name) == "" {
+		return "", fmt.Errorf("missing required parameter(s): name")
+	}
+
+	data, err := json.Marshal(CategoryJSON{Category: &Category{Name: name, ParentID: parentID}})
+	if err != nil {
+		return "", err
+	}
+
+	handler := new(CategoryResponseHandler)
+
+	err = conn.PostRequest("projects/"+projectID+"/"+endpoint, data, handler)
+	if err != nil {
+		return "", err
+	}
+
+	return handler.ID, nil
+}
+
+// RenameCategory renames a category of the specified type.
+func (conn *Connection) RenameCategory(ct CategoryType, categoryID string, name string) error {
+
+	endpoint, err := ct.endpoint()
+	if err != nil {
+		return err
+	}
+
+	err = validateID("categoryID", categoryID)
+	if err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("missing required parameter(s): name")
+	}
+
+	data, err := json.Marshal(CategoryJSON{Category: &Category{Name: name}})
+	if err != nil {
+		return err
+	}
+
+	return conn.PutRequest(endpoint+"/"+categoryID, data, new(CategoryResponseHandler))
+}
+
+// DeleteCategory deletes a category of the specified type.
+func (conn *Connection) DeleteCategory(ct CategoryType, categoryID string) error {
+
+	endpoint, err := ct.endpoint()
+	if err != nil {
+		return err
+	}
+
+	err = validateID("categoryID", categoryID)
+	if err != nil {
+		return err
+	}
+
+	return conn.DeleteRequest(endpoint+"/"+categoryID, new(CategoryResponseHandler))
+}
+
+// GetCategoryByName retrieves a category of the specified type in a project by
+// name.  Nested categories are named by path, e.g. "builds/nightly".
+func (conn *Connection) GetCategoryByName(ct CategoryType, projectID string, name string) (*Category, error) {
+
+	categories, err := conn.GetCategories(ct, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	c, depth := FindCategoryByPath(categories, name)
+	if c == nil || depth < len(splitCategoryPath(name)) {
+		return nil, fmt.Errorf("no %s category found with name (%s)", ct, name)
+	}
+
+	return c, nil
+}
+
+// EnsureCategoryPath returns the ID of the category of the specified type at
+// path (e.g. "builds/nightly") in a project, creating any missing categories
+// along the way.
+func (conn *Connection) EnsureCategoryPath(ct CategoryType, projectID string, path string) (string, error) {
+
+	names := splitCategoryPath(path)
+	if len(names) == 0 {
+		return "", fmt.Errorf("missing required parameter(s): path")
+	}
+
+	categories, err := conn.GetCategories(ct, projectID)
+	if err != nil {
+		return "", err
+	}
+
+	c, depth := FindCategoryByPath(categories, path)
+
+	parentID := ""
+	if c != nil {
+		parentID = c.ID
+	}
+
+	for _, name := range names[depth:] {
+		parentID, err = conn.PostCategory(ct, projectID, name, parentID)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return parentID, nil
+}
+
+// FindCategoryByPath finds the category at path (e.g. "builds/nightly") among
+// categories, matching names case-insensitively.  If the full path is not
+// found, the deepest category found along it is returned with the number of
+// path segments matched.
+func FindCategoryByPath(categories []*Category, path string) (*Category, int) {
+
+	var found *Category
+
+	parentID := ""
+	depth := 0
+
+	for _, name := range splitCategoryPath(path) {
+
+		var next *Category
+
+		for _, c := range categories {
+			if isCategoryParent(c.ParentID, parentID) && strings.EqualFold(strings.TrimSpace(c.Name), name) {
+				next = c
+				break
+			}
+		}
+
+		if next == nil {
+			break
+		}
+
+		found = next
+		parentID = next.ID
+		depth++
+	}
+
+	return found, depth
+}
+
+func isCategoryParent(categoryParentID string, parentID string) bool {
+
+	if parentID == "" {
+		return categoryParentID == "" || categoryParentID == "0"
+	}
+
+	return categoryParentID == parentID
+}
+
+func splitCategoryPath(path string) []string {
+
+	var retVal []string
+
+	for _, s := range strings.Split(path, "/") {
+		s = strings.TrimSpace(s)
+		if s != "" {
+			retVal = append(retVal, s)
+		}
+	}
+
+	return retVal
+}
@@ -0,0 +1,112 @@
+package teamworkapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestFindCategoryByPath(t *testing.T) {
+
+	categories := []*Category{
+		{ID: "1", Name: "Builds", ParentID: "0"},
+		{ID: "2", Name: "Nightly", ParentID: "1"},
+		{ID: "3", Name: "Nightly", ParentID: ""},
+		{ID: "4", Name: "Docs"},
+	}
+
+	var tests = []struct {
+		path  string
+		id    string
+		depth int
+	}{
+		{"builds/nightly", "2", 2},
+		{"/Nightly/", "3", 1},
+		{"builds/release/v1", "1", 1},
+		{"missing", "", 0},
+		{"", "", 0},
+	}
+
+	for _, v := range tests {
+
+		c, depth := FindCategoryByPath(categories, v.path)
+
+		id := ""
+		if c != nil {
+			id = c.ID
+		}
+
+		if id != v.id || depth != v.depth {
+			t.Errorf("expected category (%s) at depth %d for path (%s) but got (%s) at depth %d", v.id, v.depth, v.path, id, depth)
+		}
+	}
+}
+
+func TestCategories(t *testing.T) {
+
+	var posted []*Category
+	var renamed, deleted bool
+
+	conn := initMockConnection(t, func(w http.ResponseWriter, r *http.Request) {
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/projects/1/filecategories.json":
+			fmt.Fprint(w, `{"STATUS": "OK", "categories": [{"id": "10", "name": "builds", "parent-id": "0"}]}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/projects/1/filecategories.json":
+			raw, _ := ioutil.ReadAll(r.Body)
+			c := new(CategoryJSON)
+			json.Unmarshal(raw, &c)
+			posted = append(posted, c.Category)
+			fmt.Fprintf(w, `{"STATUS": "OK", "categoryId": "%d"}`, 20+len(posted))
+		case r.Method == http.MethodPut && r.URL.Path == "/linkcategories/10.json":
+			renamed = true
+			fmt.Fprint(w, `{"STATUS": "OK"}`)
+		case r.Method == http.MethodDelete && r.URL.Path == "/notebookcategories/10.json":
+			deleted = true
+			fmt.Fprint(w, `{"STATUS": "OK"}`)
+		default:
+			fmt.Fprint(w, `{"STATUS": "Error", "MESSAGE": "not found"}`)
+		}
+	})
+
+	c, err := conn.GetCategoryByName(CategoryTypeFile, "1", "Builds")
+	if err != nil || c.ID != "10" {
+		t.Errorf("expected category (10) but got (%v, %v)", c, err)
+	}
+
+	_, err = conn.GetCategoryByName(CategoryTypeFile, "1", "builds/nightly")
+	if err == nil || err.Error() != "no file category found with name (builds/nightly)" {
+		t.Errorf("expected not found error but got (%v)", err)
+	}
+
+	id, err := conn.EnsureCategoryPath(CategoryTypeFile, "1", "builds/nightly/arm64")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if id != "22" || len(posted) != 2 || posted[0].Name != "nightly" || posted[0].ParentID != "10" || posted[1].ParentID != "21" {
+		t.Errorf("unexpected categories created (%s): %+v %+v", id, posted[0], posted[1])
+	}
+
+	id, err = conn.EnsureCategoryPath(CategoryTypeFile, "1", "builds")
+	if err != nil || id != "10" || len(posted) != 2 {
+		t.Errorf("expected existing category (10) but got (%s, %v)", id, err)
+	}
+
+	err = conn.RenameCategory(CategoryTypeLink, "10", "Links")
+	if err != nil || !renamed {
+		t.Errorf("expected category to be renamed (%v)", err)
+	}
+
+	err = conn.DeleteCategory(CategoryTypeNotebook, "10")
+	if err != nil || !deleted {
+		t.Errorf("expected category to be deleted (%v)", err)
+	}
+
+	_, err = conn.GetCategories(CategoryType("message"), "1")
+	if err == nil || err.Error() != "invalid category type (message)" {
+		t.Errorf("expected invalid category type error but got (%v)", err)
+	}
+}
@@ -11,6 +11,8 @@ type CommentJSON struct {
 	Body        string `json:"body"`
 	ContentType string `json:"content-type"`
 	Notify      string `json:"notify"`
+	// PendingFileAttachments is a comma separated list of pending file references.
+	PendingFileAttachments string `json:"pendingFileAttachments,omitempty"`
 }
 type Comment struct {
 	Comment CommentJSON `json:"comment"`
@@ -0,0 +1,444 @@
+package teamworkapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-querystring/query"
+)
+
+// Custom field types.
+const (
+	CustomFieldTypeText     = "text-short"
+	CustomFieldTypeTextLong = "text-long"
+	CustomFieldTypeURL      = "url"
+	CustomFieldTypeNumber   = "number-decimal"
+	CustomFieldTypeInteger  = "number-integer"
+	CustomFieldTypeDate     = "date"
+	CustomFieldTypeDropdown = "dropdown"
+	CustomFieldTypeCheckbox = "checkbox"
+)
+
+// Custom field entities.
+const (
+	CustomFieldEntityTask    = "task"
+	CustomFieldEntityProject = "project"
+)
+
+// CustomField models the definition of a Teamwork custom field using version
+// 3 of the Teamwork API.
+type CustomField struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Entity  string `json:"entity"`
+	Options struct {
+		Choices []CustomFieldChoice `json:"choices"`
+	} `json:"options"`
+}
+
+// CustomFieldChoice models a choice of a dropdown custom field.
+type CustomFieldChoice struct {
+	Value string `json:"value"`
+	Color string `json:"color"`
+}
+
+// CustomFieldValue models the value of a custom field on a task or project.
+type CustomFieldValue struct {
+	ID            int         `json:"id,omitempty"`
+	CustomFieldID int         `json:"customfieldId"`
+	TaskID        int         `json:"taskId,omitempty"`
+	Value         interface{} `json:"value"`
+}
+
+// CustomFieldsJSON models the parent JSON structure of an array of
+// CustomFields and facilitates unmarshalling.
+type CustomFieldsJSON struct {
+	CustomFields []*CustomField `json:"customfields"`
+}
+
+// CustomFieldValuesJSON models the parent JSON structure of the custom field
+// values of a task or project and facilitates unmarshalling.
+type CustomFieldValuesJSON struct {
+	Tasks    []*CustomFieldValue `json:"customfieldTasks"`
+	Projects []*CustomFieldValue `json:"customfieldProjects"`
+}
+
+// CustomFieldResponseHandler models a http response for setting a custom
+// field value.
+type CustomFieldResponseHandler struct {
+	Status  string            `json:"STATUS"`
+	Message string            `json:"MESSAGE"`
+	Task    *CustomFieldValue `json:"customfieldTask"`
+	Project *CustomFieldValue `json:"customfieldProject"`
+}
+
+// ParseResponse interprets the http response for setting a custom field value.
+func (resMsg *CustomFieldResponseHandler) ParseResponse(httpMethod string, rawRes []byte) error {
+
+	if httpMethod == http.MethodDelete && len(rawRes) == 0 {
+		return nil
+	}
+
+	err := json.Unmarshal(rawRes, &resMsg)
+	if err != nil {
+		return err
+	}
+
+	if resMsg.Status == "Error" {
+		return fmt.Errorf("received ERROR response: %s", resMsg.Message)
+	}
+
+	switch httpMethod {
+	case http.MethodPost:
+		if (resMsg.Task == nil || resMsg.Task.ID == 0) && (resMsg.Project == nil || resMsg.Project.ID == 0) {
+			return fmt.Errorf("no ID returned for custom field value POST request")
+		}
+	}
+
+	return nil
+}
+
+// CustomFieldQueryParams defines valid query parameters for this resource.
+// Entities is a comma separated list of CustomFieldEntityTask and
+// CustomFieldEntityProject.
+type CustomFieldQueryParams struct {
+	Entities string `url:"entities,omitempty"`
+}
+
+// FormatQueryParamsV3 formats query parameters for this resource.
+func (qp CustomFieldQueryParams) FormatQueryParamsV3() (string, error) {
+
+	for _, e := range splitIDs(qp.Entities) {
+		if e != CustomFieldEntityTask && e != CustomFieldEntityProject {
+			return "", fmt.Errorf("invalid value (%s) for Entities", e)
+		}
+	}
+
+	params, err := query.Values(qp)
+	if err != nil {
+		return "", err
+	}
+
+	return params.Encode(), nil
+}
+
+// Normalize validates value against the type of the custom field and returns
+// it in the form sent to Teamwork.  Dates are expected as YYYY-MM-DD.
+func (cf *CustomField) Normalize(value string) (interface{}, error) {
+
+	value = strings.TrimSpace(value)
+
+	switch cf.Type {
+	case CustomFieldTypeNumber:
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number (%s) for custom field (%s)", value, cf.Name)
+		}
+		return v, nil
+	case CustomFieldTypeInteger:
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer (%s) for custom field (%s)", value, cf.Name)
+		}
+		return v, nil
+	case CustomFieldTypeDate:
+		_, err := time.Parse(TeamworkDateFormatV3, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date (%s) for custom field (%s).  Should be YYYY-MM-DD", value, cf.Name)
+		}
+		return value, nil
+	case CustomFieldTypeCheckbox:
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid checkbox value (%s) for custom field (%s)", value, cf.Name)
+		}
+		return v, nil
+	case CustomFieldTypeDropdown:
+		for _, c := range cf.Options.Choices {
+			if strings.EqualFold(c.Value, value) {
+				return c.Value, nil
+			}
+		}
+		return nil, fmt.Errorf("invalid choice (%s) for custom field (%s)", value, cf.Name)
+	}
+
+	return value, nil
+}
+
+// normalizeValue converts a value returned by Teamwork to the form returned by
+// Normalize, so that the two can be compared.
+func (cf *CustomField) normalizeValue(value interface{}) (interface{}, error) {
+
+	var s string
+
+	switch v := value.(type) {
+	case nil:
+		return nil, fmt.Errorf("no value for custom field (%s)", cf.Name)
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		s = strconv.FormatBool(v)
+	case string:
+		s = v
+		if cf.Type == CustomFieldTypeDate {
+			if d, err := ParseTeamworkDate(v); err == nil {
+				s = d.Format(TeamworkDateFormatV3)
+			}
+		}
+	default:
+		s = fmt.Sprint(v)
+	}
+
+	return cf.Normalize(s)
+}
+
+// equalCustomFieldValues compares two normalized custom field values.  Text is
+// compared case-insensitively.
+func equalCustomFieldValues(a interface{}, b interface{}) bool {
+
+	if sa, ok := a.(string); ok {
+		if sb, ok := b.(string); ok {
+			return strings.EqualFold(sa, sb)
+		}
+	}
+
+	return a == b
+}
+
+// GetCustomFields retrieves the custom field definitions for an entity
+// (CustomFieldEntityTask or CustomFieldEntityProject), or all if entity is
+// empty.
+func (conn *Connection) GetCustomFields(entity string) ([]*CustomField, error) {
+
+	data, err := conn.GetRequestV3("customfields", CustomFieldQueryParams{Entities: entity})
+	if err != nil {
+		return nil, err
+	}
+
+	fields := new(CustomFieldsJSON)
+
+	err = json.Unmarshal(data, &fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return fields.CustomFields, nil
+}
+
+// GetCustomFieldByName retrieves the custom field definition for an entity by
+// name (case-insensitive).
+func (conn *Connection) GetCustomFieldByName(entity string, name string) (*CustomField, error) {
+
+	fields, err := conn.GetCustomFields(entity)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range fields {
+		if strings.EqualFold(strings.TrimSpace(f.Name), strings.TrimSpace(name)) {
+			return f, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no custom field found with name (%s)", name)
+}
+
+// GetTaskCustomFieldValues retrieves the custom field values of a task.
+func (conn *Connection) GetTaskCustomFieldValues(taskID string) ([]*CustomFieldValue, error) {
+	return conn.getCustomFieldValues("tasks", taskID)
+}
+
+// GetProjectCustomFieldValues retrieves the custom field values of a project.
+func (conn *Connection) GetProjectCustomFieldValues(projectID string) ([]*CustomFieldValue, error) {
+	return conn.getCustomFieldValues("projects", projectID)
+}
+
+// SetTaskCustomField sets the value of a custom field on a task, after
+// validating it against the type of the field.
+func (conn *Connection) SetTaskCustomField(taskID string, field *CustomField, value string) error {
+	return conn.setCustomField("tasks", taskID, field, value)
+}
+
+// SetProjectCustomField sets the value of a custom field on a project, after
+// validating it against the type of the field.
+func (conn *Connection) SetProjectCustomField(projectID string, field *CustomField, value string) error {
+	return conn.setCustomField("projects", projectID, field, value)
+}
+
+// ClearTaskCustomField removes the value of a custom field from a task.
+func (conn *Connection) ClearTaskCustomField(taskID string, field *CustomField) error {
+	return conn.clearCustomField("tasks", taskID, field)
+}
+
+// ClearProjectCustomField removes the value of a custom field from a project.
+func (conn *Connection) ClearProjectCustomField(projectID string, field *CustomField) error {
+	return conn.clearCustomField("projects", projectID, field)
+}
+
+// GetTasksByCustomField returns every task matching queryParams whose value of
+// a custom field equals value.  Both values are normalized for the type of
+// the field before comparing, so numbers, dates and text match regardless of
+// formatting or case.  Custom field values are only available from API v3, so
+// rather than requesting them task by task, they are included with the v3
+// tasks of the projects found.
+func (conn *Connection) GetTasksByCustomField(queryParams TaskQueryParams, field *CustomField, value string) ([]*Task, error) {
+
+	if field == nil {
+		return nil, fmt.Errorf("missing required parameter(s): field")
+	}
+
+	want, err := field.Normalize(value)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := conn.v1().GetAllTasks(queryParams)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tasks) == 0 {
+		return nil, nil
+	}
+
+	var projectIDs []string
+	seen := make(map[int]bool)
+
+	for _, t := range tasks {
+		if !seen[t.ProjectID] {
+			seen[t.ProjectID] = true
+			projectIDs = append(projectIDs, strconv.Itoa(t.ProjectID))
+		}
+	}
+
+	res, err := conn.v3().GetAllTasksV3(TaskQueryParamsV3{
+		ProjectIDs:            projectIDs,
+		IncludeCompletedTasks: queryParams.IncludeCompleted,
+		Include:               "customfieldTasks",
+		PageSize:              "250",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	matched := make(map[int]bool)
+
+	for _, v := range res.Included.CustomFieldTasks {
+
+		if v.CustomFieldID != field.ID {
+			continue
+		}
+
+		got, err := field.normalizeValue(v.Value)
+		if err == nil && equalCustomFieldValues(got, want) {
+			matched[v.TaskID] = true
+		}
+	}
+
+	var retVal []*Task
+
+	for _, t := range tasks {
+		if matched[t.ID] {
+			retVal = append(retVal, t)
+		}
+	}
+
+	return retVal, nil
+}
+
+func (conn *Connection) getCustomFieldValues(resource string, ID string) ([]*CustomFieldValue, error) {
+
+	err := validateID("ID", ID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := conn.GetRequestV3(resource+"/"+ID+"/customfields", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	values := new(CustomFieldValuesJSON)
+
+	err = json.Unmarshal(data, &values)
+	if err != nil {
+		return nil, err
+	}
+
+	if resource == "projects" {
+		return values.Projects, nil
+	}
+
+	return values.Tasks, nil
+}
+
+func (conn *Connection) setCustomField(resource string, ID string, field *CustomField, value string) error {
+
+	if field == nil {
+		return fmt.Errorf("missing required parameter(s): field")
+	}
+
+	v, err := field.Normalize(value)
+	if err != nil {
+		return err
+	}
+
+	values, err := conn.getCustomFieldValues(resource, ID)
+	if err != nil {
+		return err
+	}
+
+	key := "customfieldTask"
+	if resource == "projects" {
+		key = "customfieldProject"
+	}
+
+	data, err := json.Marshal(map[string]*CustomFieldValue{
+		key: {CustomFieldID: field.ID, Value: v},
+	})
+	if err != nil {
+		return err
+	}
+
+	existing := findCustomFieldValue(values, field.ID)
+	if existing != nil {
+		return conn.PatchRequest(resource+"/"+ID+"/customfields/"+strconv.Itoa(existing.ID), data, new(CustomFieldResponseHandler))
+	}
+
+	return conn.PostRequest(resource+"/"+ID+"/customfields", data, new(CustomFieldResponseHandler))
+}
+
+func (conn *Connection) clearCustomField(resource string, ID string, field *CustomField) error {
+
+	if field == nil {
+		return fmt.Errorf("missing required parameter(s): field")
+	}
+
+	values, err := conn.getCustomFieldValues(resource, ID)
+	if err != nil {
+		return err
+	}
+
+	existing := findCustomFieldValue(values, field.ID)
+	if existing == nil {
+		return nil
+	}
+
+	return conn.DeleteRequest(resource+"/"+ID+"/customfields/"+strconv.Itoa(existing.ID), new(CustomFieldResponseHandler))
+}
+
+func findCustomFieldValue(values []*CustomFieldValue, fieldID int) *CustomFieldValue {
+
+	for _, v := range values {
+		if v.CustomFieldID == fieldID {
+			return v
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,190 @@
+package teamworkapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCustomFieldNormalize(t *testing.T) {
+
+	dropdown := &CustomField{Name: "Severity", Type: CustomFieldTypeDropdown}
+	dropdown.Options.Choices = []CustomFieldChoice{{Value: "High"}}
+
+	var tests = []struct {
+		field *CustomField
+		value string
+		want  string
+		err   string
+	}{
+		{&CustomField{Name: "Client", Type: CustomFieldTypeText}, " ACME ", "ACME", ""},
+		{&CustomField{Name: "Cost", Type: CustomFieldTypeNumber}, "12.5", "12.5", ""},
+		{&CustomField{Name: "Cost", Type: CustomFieldTypeNumber}, "abc", "", "invalid number (abc) for custom field (Cost)"},
+		{&CustomField{Name: "Count", Type: CustomFieldTypeInteger}, "1.5", "", "invalid integer (1.5) for custom field (Count)"},
+		{&CustomField{Name: "Due", Type: CustomFieldTypeDate}, "2021-03-01", "2021-03-01", ""},
+		{&CustomField{Name: "Due", Type: CustomFieldTypeDate}, "20210301", "", "invalid date (20210301) for custom field (Due).  Should be YYYY-MM-DD"},
+		{&CustomField{Name: "Billable", Type: CustomFieldTypeCheckbox}, "true", "true", ""},
+		{dropdown, "high", "High", ""},
+		{dropdown, "Urgent", "", "invalid choice (Urgent) for custom field (Severity)"},
+	}
+
+	for _, v := range tests {
+
+		got, err := v.field.Normalize(v.value)
+
+		if v.err != "" {
+			if err == nil || err.Error() != v.err {
+				t.Errorf("expected error (%s) but got (%v)", v.err, err)
+			}
+			continue
+		}
+
+		if err != nil || fmt.Sprint(got) != v.want {
+			t.Errorf("expected (%s) but got (%v, %v)", v.want, got, err)
+		}
+	}
+
+	_, err := CustomFieldQueryParams{Entities: "task,milestone"}.FormatQueryParamsV3()
+	if err == nil || err.Error() != "invalid value (milestone) for Entities" {
+		t.Errorf("expected invalid entity error but got (%v)", err)
+	}
+}
+
+func TestCustomFieldValues(t *testing.T) {
+
+	var calls []string
+	var bodies []string
+
+	conn := initMockConnection(t, func(w http.ResponseWriter, r *http.Request) {
+
+		raw, _ := ioutil.ReadAll(r.Body)
+
+		if r.Method != http.MethodGet {
+			calls = append(calls, r.Method+" "+r.URL.Path)
+			bodies = append(bodies, string(raw))
+		}
+
+		switch {
+		case r.URL.Path == "/customfields.json":
+			if r.URL.Query().Get("entities") != "task" {
+				t.Errorf("expected entities query parameter but got (%s)", r.URL.RawQuery)
+			}
+			fmt.Fprint(w, `{"customfields": [{"id": 1, "name": "Cost Center", "type": "number-integer", "entity": "task"}]}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/tasks/10/customfields.json":
+			fmt.Fprint(w, `{"customfieldTasks": [{"id": 100, "customfieldId": 1, "value": 42}]}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/tasks/11/customfields.json":
+			fmt.Fprint(w, `{"customfieldTasks": [{"id": 101, "customfieldId": 1, "value": 7}]}`)
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/customfields.json"):
+			fmt.Fprint(w, `{"customfieldTasks": [], "customfieldProjects": []}`)
+		case r.Method == http.MethodPost:
+			fmt.Fprint(w, `{"customfieldProject": {"id": 200, "customfieldId": 1, "value": 5}}`)
+		default:
+			fmt.Fprint(w, `{}`)
+		}
+	})
+
+	field, err := conn.GetCustomFieldByName(CustomFieldEntityTask, "cost center")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	err = conn.SetTaskCustomField("10", field, "43")
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+
+	err = conn.SetProjectCustomField("1", field, "5")
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+
+	err = conn.ClearTaskCustomField("11", field)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+
+	// clearing a field without a value is a no-op
+	err = conn.ClearTaskCustomField("12", field)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+
+	err = conn.SetTaskCustomField("10", field, "lots")
+	if err == nil || err.Error() != "invalid integer (lots) for custom field (Cost Center)" {
+		t.Errorf("expected validation error but got (%v)", err)
+	}
+
+	want := "PATCH /tasks/10/customfields/100.json,POST /projects/1/customfields.json,DELETE /tasks/11/customfields/101.json"
+
+	if strings.Join(calls, ",") != want {
+		t.Errorf("expected calls (%s) but got (%s)", want, strings.Join(calls, ","))
+	}
+
+	v := make(map[string]*CustomFieldValue)
+	json.Unmarshal([]byte(bodies[1]), &v)
+
+	if v["customfieldProject"] == nil || fmt.Sprint(v["customfieldProject"].Value) != "5" {
+		t.Errorf("unexpected project value body (%s)", bodies[1])
+	}
+}
+
+func TestGetTasksByCustomField(t *testing.T) {
+
+	conn := initMockConnection(t, func(w http.ResponseWriter, r *http.Request) {
+
+		switch r.URL.Path {
+		case "/tasks.json":
+			if r.URL.Query().Get("page") == "1" {
+				fmt.Fprint(w, `{"STATUS": "OK", "todo-items": [{"id": 10, "project-id": 1}, {"id": 11, "project-id": 1}]}`)
+			} else {
+				fmt.Fprint(w, `{"STATUS": "OK", "todo-items": [{"id": 12, "project-id": 2}]}`)
+			}
+		case "/projects/api/v3/tasks.json":
+			if r.URL.Query().Get("projectIds") != "1,2" || r.URL.Query().Get("include") != "customfieldTasks" {
+				t.Errorf("unexpected v3 tasks query (%s)", r.URL.RawQuery)
+			}
+			fmt.Fprint(w, `{"tasks": [{"id": 10}, {"id": 11}, {"id": 12}], "included": {"customfieldTasks": {
+				"100": {"id": 100, "customfieldId": 1, "taskId": 10, "value": 1234567},
+				"101": {"id": 101, "customfieldId": 1, "taskId": 11, "value": 7},
+				"102": {"id": 102, "customfieldId": 1, "taskId": 12, "value": "1234567"},
+				"103": {"id": 103, "customfieldId": 2, "taskId": 11, "value": "ACME "},
+				"104": {"id": 104, "customfieldId": 3, "taskId": 12, "value": "2021-03-01T00:00:00Z"},
+				"105": {"id": 105, "customfieldId": 4, "taskId": 10, "value": 12.5}
+			}}}`)
+		default:
+			t.Errorf("unexpected request for %s", r.URL.Path)
+		}
+	})
+
+	var tests = []struct {
+		field *CustomField
+		value string
+		want  string
+	}{
+		{&CustomField{ID: 1, Name: "Cost Center", Type: CustomFieldTypeInteger}, "1234567", "10,12"},
+		{&CustomField{ID: 2, Name: "Client", Type: CustomFieldTypeText}, "acme", "11"},
+		{&CustomField{ID: 3, Name: "Due", Type: CustomFieldTypeDate}, "2021-03-01", "12"},
+		{&CustomField{ID: 4, Name: "Rate", Type: CustomFieldTypeNumber}, "12.50", "10"},
+	}
+
+	for _, v := range tests {
+
+		tasks, err := conn.GetTasksByCustomField(TaskQueryParams{PageSize: "2"}, v.field, v.value)
+		if err != nil {
+			t.Errorf(err.Error())
+			continue
+		}
+
+		var got []string
+		for _, task := range tasks {
+			got = append(got, fmt.Sprint(task.ID))
+		}
+
+		if strings.Join(got, ",") != v.want {
+			t.Errorf("expected tasks (%s) for %s but got (%s)", v.want, v.field.Name, strings.Join(got, ","))
+		}
+	}
+}
@@ -0,0 +1,281 @@
+package teamworkapi
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// TeamworkDateFormatShort is the short-form of a date used by Teamwork.
+const TeamworkDateFormatShort = "20060102"
+
+// TeamworkDateFormatMed is the medium-form of a date/time used by Teamwork.
+const TeamworkDateFormatMed = "2006-01-02T15:04"
+
+// TeamworkDateFormatLong is the long-form of a date/time used by Teamwork.
+const TeamworkDateFormatLong = "2006-01-02T15:04:05Z"
+
+// TeamworkDateFormatV3 is the form of a date used by version 3 of the
+// Teamwork API.
+const TeamworkDateFormatV3 = "2006-01-02"
+
+// TeamworkDateTimeFormatV3 is the form of a date/time used by version 3 of the
+// Teamwork API.
+const TeamworkDateTimeFormatV3 = time.RFC3339
+
+// teamworkDateFormats lists the formats accepted by ParseTeamworkDate, in the
+// order they are attempted.
+var teamworkDateFormats = []string{
+	TeamworkDateTimeFormatV3,
+	TeamworkDateFormatLong,
+	TeamworkDateFormatMed,
+	TeamworkDateFormatV3,
+	TeamworkDateFormatShort,
+}
+
+// dateFormatNames provides a human readable description of each format, used
+// in error messages.
+var dateFormatNames = map[string]string{
+	TeamworkDateFormatShort:  "YYYYMMDD",
+	TeamworkDateFormatMed:    "YYYY-MM-DDTHH:MM",
+	TeamworkDateFormatLong:   "YYYY-MM-DDTHH:MM:SSZ",
+	TeamworkDateFormatV3:     "YYYY-MM-DD",
+	TeamworkDateTimeFormatV3: "YYYY-MM-DDTHH:MM:SS+HH:MM",
+}
+
+// ParseTeamworkDate parses a date provided in any of the Teamwork date
+// formats.  Dates without time zone information are interpreted as UTC.
+func ParseTeamworkDate(s string) (time.Time, error) {
+	return ParseTeamworkDateInLocation(s, time.UTC)
+}
+
+// ParseTeamworkDateInLocation parses a date provided in any of the Teamwork
+// date formats.  Dates without time zone information are interpreted in loc.
+func ParseTeamworkDateInLocation(s string, loc *time.Location) (time.Time, error) {
+
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	for _, layout := range teamworkDateFormats {
+		t, err := time.ParseInLocation(layout, s, loc)
+		if err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid format for date (%s)", s)
+}
+
+// ConvertTeamworkDate converts a date provided in any of the Teamwork date
+// formats to the specified layout (e.g. TeamworkDateFormatShort or
+// TeamworkDateFormatV3).
+func ConvertTeamworkDate(s string, layout string) (string, error) {
+
+	t, err := ParseTeamworkDate(s)
+	if err != nil {
+		return "", err
+	}
+
+	return t.Format(layout), nil
+}
+
+// validateDateParam ensures value, if provided, matches layout.  name is the
+// query parameter reported in the error message.
+func validateDateParam(name string, value string, layout string) error {
+
+	if value == "" {
+		return nil
+	}
+
+	_, err := time.Parse(layout, value)
+	if err != nil {
+		return fmt.Errorf("invalid format for %s parameter.  Should be %s, but found %s", name, dateFormatNames[layout], value)
+	}
+
+	return nil
+}
+
+// BusinessCalendar determines which days are business days, based on the
+// weekend days and a set of holidays.  A nil BusinessCalendar treats Saturday
+// and Sunday as the only non-business days.
+type BusinessCalendar struct {
+	Weekend  []time.Weekday
+	holidays map[string]string
+}
+
+// NewBusinessCalendar initializes a calendar with a Saturday/Sunday weekend
+// and no holidays.
+func NewBusinessCalendar() *BusinessCalendar {
+	return &BusinessCalendar{
+		Weekend:  []time.Weekday{time.Saturday, time.Sunday},
+		holidays: make(map[string]string),
+	}
+}
+
+// AddHoliday marks the day of t as a holiday.
+func (bc *BusinessCalendar) AddHoliday(t time.Time, name string) {
+
+	if bc.holidays == nil {
+		bc.holidays = make(map[string]string)
+	}
+
+	bc.holidays[t.Format(TeamworkDateFormatShort)] = name
+}
+
+// AddHolidays adds holidays keyed by date, in any of the Teamwork date formats.
+func (bc *BusinessCalendar) AddHolidays(holidays map[string]string) error {
+
+	for d, name := range holidays {
+		t, err := ParseTeamworkDate(d)
+		if err != nil {
+			return err
+		}
+		bc.AddHoliday(t, name)
+	}
+
+	return nil
+}
+
+// Holiday returns the name of the holiday falling on the day of t, if any.
+func (bc *BusinessCalendar) Holiday(t time.Time) (string, bool) {
+
+	if bc == nil {
+		return "", false
+	}
+
+	name, ok := bc.holidays[t.Format(TeamworkDateFormatShort)]
+
+	return name, ok
+}
+
+// Holidays returns the dates (YYYYMMDD) of all holidays, sorted.
+func (bc *BusinessCalendar) Holidays() []string {
+
+	if bc == nil {
+		return nil
+	}
+
+	retVal := make([]string, 0, len(bc.holidays))
+	for d := range bc.holidays {
+		retVal = append(retVal, d)
+	}
+
+	sort.Strings(retVal)
+
+	return retVal
+}
+
+// IsWeekend determines if the day of t is a weekend day.
+func (bc *BusinessCalendar) IsWeekend(t time.Time) bool {
+
+	weekend := []time.Weekday{time.Saturday, time.Sunday}
+	if bc != nil && bc.Weekend != nil {
+		weekend = bc.Weekend
+	}
+
+	for _, d := range weekend {
+		if t.Weekday() == d {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsBusinessDay determines if the day of t is neither a weekend day nor a
+// holiday.
+func (bc *BusinessCalendar) IsBusinessDay(t time.Time) bool {
+
+	if bc.IsWeekend(t) {
+		return false
+	}
+
+	_, holiday := bc.Holiday(t)
+
+	return !holiday
+}
+
+// BusinessDaysBetween gets the number of business days from the day of from
+// (inclusive) to the day of to (exclusive).  The result is negative if to is
+// before from.
+func (bc *BusinessCalendar) BusinessDaysBetween(from time.Time, to time.Time) int {
+
+	start := dateOf(from)
+	end := dateOf(to)
+
+	sign := 1
+	if end.Before(start) {
+		start, end = end, start
+		sign = -1
+	}
+
+	days := 0
+	for d := start; d.Before(end); d = d.AddDate(0, 0, 1) {
+		if bc.IsBusinessDay(d) {
+			days++
+		}
+	}
+
+	return sign * days
+}
+
+// AddBusinessDays returns the date n business days after t (or before, if n is
+// negative).  The time of day and location of t are preserved.
+func (bc *BusinessCalendar) AddBusinessDays(t time.Time, n int) time.Time {
+
+	step := 1
+	if n < 0 {
+		step = -1
+		n = -n
+	}
+
+	for n > 0 {
+		t = t.AddDate(0, 0, step)
+		if bc.IsBusinessDay(t) {
+			n--
+		}
+	}
+
+	return t
+}
+
+// DurationInDays gets the number of days between the specified dates.
+func DurationInDays(from string, to string) (int, error) {
+
+	start, err := time.Parse(TeamworkDateFormatShort, from)
+	if err != nil {
+		return 0, err
+	}
+
+	end, err := time.Parse(TeamworkDateFormatShort, to)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(end.Sub(start).Hours() / 24), nil
+}
+
+// DurationInBusinessDays gets the number of business days between the
+// specified dates, provided in any of the Teamwork date formats, excluding
+// weekends and the holidays of cal.
+func DurationInBusinessDays(from string, to string, cal *BusinessCalendar) (int, error) {
+
+	start, err := ParseTeamworkDate(from)
+	if err != nil {
+		return 0, err
+	}
+
+	end, err := ParseTeamworkDate(to)
+	if err != nil {
+		return 0, err
+	}
+
+	return cal.BusinessDaysBetween(start, end), nil
+}
+
+// dateOf returns midnight (UTC) of the calendar day of t, as observed in
+// t's location.
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,177 @@
+package teamworkapi
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTeamworkDate(t *testing.T) {
+
+	var tests = []struct {
+		date string
+		want string
+	}{
+		{"20210301", "2021-03-01T00:00:00Z"},
+		{"2021-03-01", "2021-03-01T00:00:00Z"},
+		{"2021-03-01T14:30", "2021-03-01T14:30:00Z"},
+		{"2021-03-01T14:30:15Z", "2021-03-01T14:30:15Z"},
+		{"2021-03-01T14:30:15-05:00", "2021-03-01T19:30:15Z"},
+	}
+
+	for _, v := range tests {
+
+		d, err := ParseTeamworkDate(v.date)
+		if err != nil {
+			t.Errorf(err.Error())
+			continue
+		}
+
+		if d.UTC().Format(time.RFC3339) != v.want {
+			t.Errorf("expected %s to parse as %s but got %s", v.date, v.want, d.UTC().Format(time.RFC3339))
+		}
+	}
+
+	_, err := ParseTeamworkDate("03/01/2021")
+	if err == nil {
+		t.Errorf("expected error for invalid date")
+	}
+
+	loc := time.FixedZone("EST", -5*60*60)
+
+	d, err := ParseTeamworkDateInLocation("2021-03-01T14:30", loc)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if d.UTC().Hour() != 19 {
+		t.Errorf("expected 19:30 UTC but got %s", d.UTC().Format(time.RFC3339))
+	}
+}
+
+func TestConvertTeamworkDate(t *testing.T) {
+
+	var tests = []struct {
+		date   string
+		layout string
+		want   string
+	}{
+		{"20210301", TeamworkDateFormatV3, "2021-03-01"},
+		{"2021-03-01", TeamworkDateFormatShort, "20210301"},
+		{"2021-03-01T14:30:00Z", TeamworkDateFormatMed, "2021-03-01T14:30"},
+		{"20210301", TeamworkDateFormatLong, "2021-03-01T00:00:00Z"},
+	}
+
+	for _, v := range tests {
+
+		s, err := ConvertTeamworkDate(v.date, v.layout)
+		if err != nil {
+			t.Errorf(err.Error())
+		}
+
+		if s != v.want {
+			t.Errorf("expected %s but got %s", v.want, s)
+		}
+	}
+}
+
+func TestValidateDateParam(t *testing.T) {
+
+	var tests = []struct {
+		qp   QueryParamsV3
+		want string
+	}{
+		{&TimeQueryParamsV3{StartDate: "20210101"}, "invalid format for StartDate parameter.  Should be YYYY-MM-DD, but found 20210101"},
+		{&TimeQueryParamsV3{EndDate: "2021/01/01"}, "invalid format for EndDate parameter.  Should be YYYY-MM-DD, but found 2021/01/01"},
+		{CalendarEventQueryParamsV3{StartDate: "2021-01-01", EndDate: "01-02-2021"}, "invalid format for EndDate parameter.  Should be YYYY-MM-DD, but found 01-02-2021"},
+	}
+
+	for _, v := range tests {
+
+		_, err := v.qp.FormatQueryParamsV3()
+		if err == nil {
+			t.Errorf("expected error (%s)", v.want)
+			continue
+		}
+
+		if err.Error() != v.want {
+			t.Errorf("expected error (%s) but got (%s)", v.want, err.Error())
+		}
+	}
+
+	_, err := CalendarEventQueryParams{From: "20210101", To: "2021-01-02"}.FormatQueryParams()
+	if err == nil || err.Error() != "invalid format for To parameter.  Should be YYYYMMDD, but found 2021-01-02" {
+		t.Errorf("unexpected error for To parameter: %v", err)
+	}
+}
+
+func TestBusinessDaysBetween(t *testing.T) {
+
+	cal := NewBusinessCalendar()
+
+	err := cal.AddHolidays(map[string]string{"2021-07-05": "Independence Day (observed)"})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	var tests = []struct {
+		from string
+		to   string
+		cal  *BusinessCalendar
+		want int
+	}{
+		{"20210701", "20210701", cal, 0},
+		{"20210701", "20210702", cal, 1},
+		{"20210701", "20210706", cal, 2},
+		{"20210701", "20210706", nil, 3},
+		{"20210706", "20210701", cal, -2},
+		{"20210628", "20210712", cal, 9},
+	}
+
+	for _, v := range tests {
+
+		days, err := DurationInBusinessDays(v.from, v.to, v.cal)
+		if err != nil {
+			t.Errorf(err.Error())
+		}
+
+		if days != v.want {
+			t.Errorf("expected %d business day(s) between %s and %s but got %d", v.want, v.from, v.to, days)
+		}
+	}
+
+	if name, ok := cal.Holiday(time.Date(2021, 7, 5, 12, 0, 0, 0, time.UTC)); !ok || name != "Independence Day (observed)" {
+		t.Errorf("expected 2021-07-05 to be a holiday")
+	}
+
+	if len(cal.Holidays()) != 1 {
+		t.Errorf("expected 1 holiday but got %d", len(cal.Holidays()))
+	}
+}
+
+func TestAddBusinessDays(t *testing.T) {
+
+	cal := NewBusinessCalendar()
+	cal.AddHoliday(time.Date(2021, 7, 5, 0, 0, 0, 0, time.UTC), "Independence Day (observed)")
+
+	var tests = []struct {
+		from string
+		days int
+		want string
+	}{
+		{"20210701", 1, "20210702"},
+		{"20210702", 1, "20210706"},
+		{"20210706", -1, "20210702"},
+		{"20210701", 0, "20210701"},
+		{"20210701", 5, "20210709"},
+	}
+
+	for _, v := range tests {
+
+		start, _ := time.Parse(TeamworkDateFormatShort, v.from)
+
+		got := cal.AddBusinessDays(start, v.days).Format(TeamworkDateFormatShort)
+		if got != v.want {
+			t.Errorf("expected %s plus %d business day(s) to be %s but got %s", v.from, v.days, v.want, got)
+		}
+	}
+}
@@ -4,13 +4,14 @@ import(
 	"fmt"
 	"strings"
 	"strconv"
-	"io/ioutil"
 	"io"
 	"net/http"
 	"mime/multipart"
 	"bytes"
 	"os"
-	"github.com/imroc/req/v3"
+	"path/filepath"
+	"net/url"
+	"github.com/google/go-querystring/query"
 	"encoding/json"
 )
 
@@ -111,91 +112,202 @@ func NewFileConnection(SiteName string, FileName string, FullPathToFile string,
 } 
 
 
-//Specific Get Request to return the unique ref ID for said file and unique URL to PUT file to
-func getPreSignedData(SiteName string, FileName string, ContentLength string, APIKey string) (*PreSignedRes, error){
+// UploadProgressFunc is called as an upload proceeds with the number of bytes
+// of the file sent so far and the size of the file.
+type UploadProgressFunc func(sent int64, total int64)
 
-	url := "https://" + SiteName + ".teamwork.com/projects/api/v1/pendingfiles/presignedurl.json?fileName=" + FileName + "&fileSize=" + ContentLength
-	var preSignedRes *PreSignedRes
+// progressReader reports the bytes read from r to progress.
+type progressReader struct {
+	r        io.Reader
+	sent     int64
+	total    int64
+	progress UploadProgressFunc
+}
+
+func (pr *progressReader) Read(p []byte) (int, error) {
+
+	n, err := pr.r.Read(p)
+
+	if n > 0 {
+		pr.sent += int64(n)
+		if pr.progress != nil {
+			pr.progress(pr.sent, pr.total)
+		}
+	}
+
+	return n, err
+}
+
+//Specific Get Request to return the unique ref ID for said file and unique URL to PUT file to.
+//The TW API requires the size of the request body (not the file) as it is used to sign the URL.
+func getPreSignedData(siteURL string, fileName string, contentLength int64, apiKey string) (*PreSignedRes, error) {
+
+	params := url.Values{}
+	params.Set("fileName", fileName)
+	params.Set("fileSize", strconv.FormatInt(contentLength, 10))
+
+	r, err := http.NewRequest(http.MethodGet, siteURL+"projects/api/v1/pendingfiles/presignedurl.json?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	r.SetBasicAuth(apiKey, "p")
 
-	client := req.C().DevMode()
-	resp, err := client.R().
-		SetBasicAuth(APIKey, "p").
-		SetResult(&preSignedRes).
-		Get(url)
+	client := &http.Client{}
 
-	if err != nil{
+	resp, err := client.Do(r)
+	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 
-	if !resp.IsSuccess(){
-		return nil, fmt.Errorf("presigned URL request unsuccessful")
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("presigned URL request failed with response code: %d", resp.StatusCode)
+	}
+
+	preSignedRes := new(PreSignedRes)
+
+	err = json.NewDecoder(resp.Body).Decode(preSignedRes)
+	if err != nil {
+		return nil, err
+	}
+
+	if preSignedRes.URL == "" || preSignedRes.Ref == "" {
+		return nil, fmt.Errorf("no presigned URL returned for file (%s)", fileName)
 	}
 
 	return preSignedRes, nil
 }
 
-func (fc *FileConnection) PutFile() (string, error){
+// uploadPendingFile streams size bytes from src as a multipart form to a
+// presigned URL and returns the pending file reference.  The length of the
+// multipart body is computed from its header and trailer, so the file is never
+// buffered in memory.
+func uploadPendingFile(siteURL string, apiKey string, fileName string, src io.Reader, size int64, progress UploadProgressFunc) (string, error) {
 
-	filePath := fc.FullPathToFile
-	file, _ := os.Open(filePath)
-	defer file.Close()
+	errBuff := ""
 
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-	part, _ := writer.CreateFormFile("file", filePath)
-	io.Copy(part, file)
-	writer.Close()
-
-	//file.stat does not produce correct content length needed. Need actual req.body content length. 
-	//Because the TW API requires the fileSize (really its the req.body size) when generating the preSignedUrl, 
-	//we create a dummy request in order to get the actual req.body size. We then have the correct size/content-length 
-	//to generated the preSignedURL (content length is used to during signature creation) for "putting" file to.
-	req, err := http.NewRequest("PUT", "https://www.google.com", body)
-	if err != nil{
-		return "", err
+	if fileName == "" {
+		errBuff += "fileName"
+	}
+	if src == nil {
+		if errBuff != "" {
+			errBuff += ", "
+		}
+		errBuff += "src"
+	}
+
+	if errBuff != "" {
+		return "", fmt.Errorf("missing required parameter(s): %s", errBuff)
 	}
 
-	reqBody, err := ioutil.ReadAll(req.Body)
-	if err != nil{
+	if size < 0 {
+		return "", fmt.Errorf("invalid value (%d) for size", size)
+	}
+
+	head := &bytes.Buffer{}
+	writer := multipart.NewWriter(head)
+
+	_, err := writer.CreateFormFile("file", fileName)
+	if err != nil {
 		return "", err
 	}
 
-	contentLength := strconv.Itoa(len(reqBody))
-	preSignedData, err := getPreSignedData(fc.SiteName, fc.FileName, contentLength, fc.APIKey)
+	// matches the trailer written by writer.Close
+	tail := "\r\n--" + writer.Boundary() + "--\r\n"
 
+	contentLength := int64(head.Len()) + size + int64(len(tail))
 
-	//Because we have read off the request body above to figure out the content length, need to re-create the multipart data
-	filePath = fc.FullPathToFile
-	file, _ = os.Open(filePath)
-	defer file.Close()
+	preSignedData, err := getPreSignedData(siteURL, fileName, contentLength, apiKey)
+	if err != nil {
+		return "", err
+	}
 
-	body = &bytes.Buffer{}
-	writer = multipart.NewWriter(body)
-	part, _ = writer.CreateFormFile("file", filePath)
-	io.Copy(part, file)
-	writer.Close()
+	body := io.MultiReader(
+		head,
+		&progressReader{r: io.LimitReader(src, size), total: size, progress: progress},
+		strings.NewReader(tail),
+	)
 
-	r, err := http.NewRequest("PUT", preSignedData.URL, body)
-	if err != nil{
+	r, err := http.NewRequest(http.MethodPut, preSignedData.URL, body)
+	if err != nil {
 		return "", err
 	}
 
+	// a body shorter than ContentLength fails the request
+	r.ContentLength = contentLength
 	r.Header.Add("Content-Type", writer.FormDataContentType())
 	r.Header.Add("X-Amz-Acl", "public-read")
-	r.Header.Add("Content-Length", contentLength)
-	r.Header.Add("Host", "tw-bucket.s3-accelerate.amazonaws.com")
 
-	client := &http.Client {}
-	
-	rsp, _ := client.Do(r)
-	fmt.Println(rsp)
-    if rsp.StatusCode != 200 {
-        return "", fmt.Errorf("Request failed with response code: %d", rsp.StatusCode)
+	client := &http.Client{}
+
+	resp, err := client.Do(r)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("upload failed with response code: %d", resp.StatusCode)
 	}
 
 	return preSignedData.Ref, nil
 }
 
+// Upload streams size bytes from src to Teamwork as a pending file named
+// fc.FileName and returns its reference.  progress may be nil.
+func (fc *FileConnection) Upload(src io.Reader, size int64, progress UploadProgressFunc) (string, error) {
+	return uploadPendingFile("https://"+fc.SiteName+".teamwork.com/", fc.APIKey, fc.FileName, src, size, progress)
+}
+
+// PutFile uploads the file at fc.FullPathToFile and returns its pending file
+// reference.
+func (fc *FileConnection) PutFile() (string, error) {
+
+	file, err := os.Open(fc.FullPathToFile)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	return fc.Upload(file, info.Size(), nil)
+}
+
+// UploadFile streams size bytes from src to Teamwork as a pending file and
+// returns its reference, which can be attached to tasks, comments or file
+// versions.  progress may be nil.
+func (conn *Connection) UploadFile(fileName string, src io.Reader, size int64, progress UploadProgressFunc) (string, error) {
+	return uploadPendingFile(conn.siteURL(), conn.APIKey, fileName, src, size, progress)
+}
+
+// UploadLocalFile uploads the file at path and returns its pending file
+// reference.  progress may be nil.
+func (conn *Connection) UploadLocalFile(path string, progress UploadProgressFunc) (string, error) {
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	return conn.UploadFile(filepath.Base(path), file, info.Size(), progress)
+}
+
+// siteURL returns the root URL of the Teamwork site, regardless of API version.
+func (conn *Connection) siteURL() string {
+	return strings.TrimSuffix(conn.URL, "projects/api/v3/")
+}
+
 func (conn *Connection) PatchFile(fileID string, patchData FileVersion3) (*FileResponseHandlerV3, error) {
 
 	handler := new(FileResponseHandlerV3)
@@ -215,4 +327,268 @@ func (conn *Connection) PatchFile(fileID string, patchData FileVersion3) (*FileR
 	}
 
 	return handler, nil
-}
\ No newline at end of file
+}
+
+// File models a Teamwork file, as returned by version 1 of the API.  Versions
+// is only populated when retrieving a single file.
+type File struct {
+	ID               string         `json:"id"`
+	Name             string         `json:"name"`
+	OriginalName     string         `json:"originalName"`
+	Description      string         `json:"description"`
+	ProjectID        string         `json:"project-id"`
+	CategoryID       string         `json:"category-id"`
+	CategoryName     string         `json:"category-name"`
+	Size             string         `json:"size"`
+	VersionID        string         `json:"version-id"`
+	Version          string         `json:"version"`
+	UploadedByUserID string         `json:"uploaded-by-user-id"`
+	UploadedDate     string         `json:"uploaded-date"`
+	DownloadURL      string         `json:"download-URL"`
+	Versions         []*FileVersion `json:"versions,omitempty"`
+}
+
+// FileVersion models a single version of a Teamwork file.
+type FileVersion struct {
+	ID               string `json:"id"`
+	Version          string `json:"version"`
+	Name             string `json:"name"`
+	Description      string `json:"description"`
+	Size             string `json:"size"`
+	UploadedByUserID string `json:"uploaded-by-user-id"`
+	UploadedDate     string `json:"uploaded-date"`
+	DownloadURL      string `json:"download-URL"`
+}
+
+// FileJSON models the parent JSON structure of a single File and facilitates
+// unmarshalling.
+type FileJSON struct {
+	File *File `json:"file"`
+}
+
+// FilesJSON models the parent JSON structure of an array of Files and
+// facilitates unmarshalling.
+type FilesJSON struct {
+	Files []*File `json:"files"`
+}
+
+// ProjectFilesJSON models the parent JSON structure of the Files of a project
+// and facilitates unmarshalling.
+type ProjectFilesJSON struct {
+	Project struct {
+		Files []*File `json:"files"`
+	} `json:"project"`
+}
+
+// FileQueryParams defines valid query parameters for this resource.
+type FileQueryParams struct {
+	CategoryID string `url:"catId,omitempty"`
+}
+
+// FormatQueryParams formats query parameters for this resource.
+func (qp FileQueryParams) FormatQueryParams() (string, error) {
+
+	if qp.CategoryID != "" {
+		_, err := strconv.Atoi(qp.CategoryID)
+		if err != nil {
+			return "", fmt.Errorf("invalid value (%s) for CategoryID", qp.CategoryID)
+		}
+	}
+
+	params, err := query.Values(qp)
+	if err != nil {
+		return "", err
+	}
+
+	return params.Encode(), nil
+}
+
+// GetProjectFiles retrieves the files of a project, optionally limited to a
+// category.
+func (conn *Connection) GetProjectFiles(projectID string, queryParams FileQueryParams) ([]*File, error) {
+
+	err := validateID("projectID", projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := conn.GetRequest("projects/"+projectID+"/files", queryParams)
+	if err != nil {
+		return nil, err
+	}
+
+	files := new(ProjectFilesJSON)
+
+	err = json.Unmarshal(data, &files)
+	if err != nil {
+		return nil, err
+	}
+
+	return filterFilesByCategory(files.Project.Files, queryParams.CategoryID), nil
+}
+
+// GetTaskFiles retrieves the files attached to a task.
+func (conn *Connection) GetTaskFiles(taskID string) ([]*File, error) {
+
+	err := validateID("taskID", taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := conn.GetRequest("tasks/"+taskID+"/files", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	files := new(FilesJSON)
+
+	err = json.Unmarshal(data, &files)
+	if err != nil {
+		return nil, err
+	}
+
+	return files.Files, nil
+}
+
+// GetFileByID retrieves the metadata and version history of a file.
+func (conn *Connection) GetFileByID(fileID string) (*File, error) {
+
+	err := validateID("fileID", fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := conn.GetRequest("files/"+fileID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	f := new(FileJSON)
+
+	err = json.Unmarshal(data, &f)
+	if err != nil {
+		return nil, err
+	}
+
+	if f.File == nil {
+		return nil, fmt.Errorf("failed to retrieve file with ID (%s)", fileID)
+	}
+
+	return f.File, nil
+}
+
+// GetFileVersions retrieves the version history of a file.
+func (conn *Connection) GetFileVersions(fileID string) ([]*FileVersion, error) {
+
+	f, err := conn.GetFileByID(fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.Versions, nil
+}
+
+// DownloadFile streams the contents of a file to w and returns the number of
+// bytes written.  If versionID is empty, the current version is downloaded.
+func (conn *Connection) DownloadFile(fileID string, versionID string, w io.Writer) (int64, error) {
+
+	f, err := conn.GetFileByID(fileID)
+	if err != nil {
+		return 0, err
+	}
+
+	downloadURL := f.DownloadURL
+
+	if versionID != "" && versionID != f.VersionID {
+
+		downloadURL = ""
+
+		for _, v := range f.Versions {
+			if v.ID == versionID {
+				downloadURL = v.DownloadURL
+			}
+		}
+
+		if downloadURL == "" {
+			return 0, fmt.Errorf("version (%s) not found for file (%s)", versionID, fileID)
+		}
+	}
+
+	if downloadURL == "" {
+		return 0, fmt.Errorf("no download URL returned for file (%s)", fileID)
+	}
+
+	return conn.download(downloadURL, w)
+}
+
+// DeleteFile moves a file to the trash.
+func (conn *Connection) DeleteFile(fileID string) error {
+
+	err := validateID("fileID", fileID)
+	if err != nil {
+		return err
+	}
+
+	return conn.DeleteRequest("files/"+fileID, nil)
+}
+
+// RestoreFile restores a deleted file from the trash.
+func (conn *Connection) RestoreFile(fileID string) error {
+
+	err := validateID("fileID", fileID)
+	if err != nil {
+		return err
+	}
+
+	return conn.PutRequest("trashcan/files/"+fileID+"/restore", nil, nil)
+}
+
+// download streams the body of a GET request to w.  Download URLs on the
+// Teamwork site are authenticated; pre-signed storage URLs are not.
+func (conn *Connection) download(downloadURL string, w io.Writer) (int64, error) {
+
+	if !strings.HasPrefix(downloadURL, "http://") && !strings.HasPrefix(downloadURL, "https://") {
+		downloadURL = strings.TrimSuffix(conn.URL, "/") + "/" + strings.TrimPrefix(downloadURL, "/")
+	}
+
+	r, err := http.NewRequest(http.MethodGet, downloadURL, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	site, err := url.Parse(conn.URL)
+	if err == nil && r.URL.Host == site.Host {
+		r.Header.Add("Authorization", "Basic "+basicAuth(conn.APIKey))
+	}
+
+	client := &http.Client{}
+
+	resp, err := client.Do(r)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("download failed with response code: %d", resp.StatusCode)
+	}
+
+	return io.Copy(w, resp.Body)
+}
+
+func filterFilesByCategory(files []*File, categoryID string) []*File {
+
+	if categoryID == "" {
+		return files
+	}
+
+	var retVal []*File
+
+	for _, f := range files {
+		if f.CategoryID == categoryID {
+			retVal = append(retVal, f)
+		}
+	}
+
+	return retVal
+}
@@ -1,12 +1,15 @@
 package teamworkapi
 
 import(
+	"bytes"
+	"net/http"
+	"strconv"
+	"strings"
 	"testing"
 	"fmt"
 	"encoding/json"
 	"io/ioutil"
 	"os"
-	//"strconv"
 )
 
 func initFileTestConnectionV3(t *testing.T) *Connection {
@@ -95,4 +98,183 @@ func TestPutFile(t* testing.T){
 	}
 
 	fmt.Println(fileRef)
-}
\ No newline at end of file
+}
+
+func TestFileListingAndDownload(t *testing.T) {
+
+	var deleted, restored bool
+
+	conn := initMockConnection(t, func(w http.ResponseWriter, r *http.Request) {
+
+		switch {
+		case r.URL.Path == "/projects/1/files.json":
+			if r.URL.Query().Get("catId") != "7" {
+				t.Errorf("expected catId query parameter but got (%s)", r.URL.RawQuery)
+			}
+			fmt.Fprint(w, `{"STATUS": "OK", "project": {"files": [{"id": "10", "name": "spec.pdf", "category-id": "7"}, {"id": "11", "name": "logo.png", "category-id": "8"}]}}`)
+		case r.URL.Path == "/tasks/2/files.json":
+			fmt.Fprint(w, `{"STATUS": "OK", "files": [{"id": "10", "name": "spec.pdf"}]}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/files/10.json":
+			fmt.Fprintf(w, `{"STATUS": "OK", "file": {"id": "10", "version-id": "3", "download-URL": "/download/3", "versions": [{"id": "3", "version": "2", "download-URL": "/download/3"}, {"id": "2", "version": "1", "download-URL": "http://%s/download/2"}]}}`, r.Host)
+		case r.URL.Path == "/download/3" || r.URL.Path == "/download/2":
+			if r.Header.Get("Authorization") == "" {
+				t.Errorf("expected authorization header for site download")
+			}
+			fmt.Fprint(w, "contents of "+r.URL.Path)
+		case r.Method == http.MethodDelete && r.URL.Path == "/files/10.json":
+			deleted = true
+			fmt.Fprint(w, `{"STATUS": "OK"}`)
+		case r.Method == http.MethodPut && r.URL.Path == "/trashcan/files/10/restore.json":
+			restored = true
+			fmt.Fprint(w, `{"STATUS": "OK"}`)
+		default:
+			fmt.Fprint(w, `{"STATUS": "Error", "MESSAGE": "not found"}`)
+		}
+	})
+
+	files, err := conn.GetProjectFiles("1", FileQueryParams{CategoryID: "7"})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if len(files) != 1 || files[0].ID != "10" {
+		t.Errorf("expected 1 file in category 7 but got %d", len(files))
+	}
+
+	files, err = conn.GetTaskFiles("2")
+	if err != nil || len(files) != 1 {
+		t.Errorf("expected 1 task file but got %d (%v)", len(files), err)
+	}
+
+	versions, err := conn.GetFileVersions("10")
+	if err != nil || len(versions) != 2 {
+		t.Errorf("expected 2 versions but got %d (%v)", len(versions), err)
+	}
+
+	var tests = []struct {
+		versionID string
+		want      string
+	}{
+		{"", "contents of /download/3"},
+		{"2", "contents of /download/2"},
+	}
+
+	for _, v := range tests {
+
+		buf := new(bytes.Buffer)
+
+		n, err := conn.DownloadFile("10", v.versionID, buf)
+		if err != nil {
+			t.Errorf(err.Error())
+			continue
+		}
+
+		if buf.String() != v.want || n != int64(len(v.want)) {
+			t.Errorf("expected (%s) but got (%s)", v.want, buf.String())
+		}
+	}
+
+	_, err = conn.DownloadFile("10", "99", new(bytes.Buffer))
+	if err == nil || err.Error() != "version (99) not found for file (10)" {
+		t.Errorf("expected missing version error but got (%v)", err)
+	}
+
+	err = conn.DeleteFile("10")
+	if err != nil || !deleted {
+		t.Errorf("expected file to be deleted (%v)", err)
+	}
+
+	err = conn.RestoreFile("10")
+	if err != nil || !restored {
+		t.Errorf("expected file to be restored (%v)", err)
+	}
+
+	err = conn.DeleteFile("abc")
+	if err == nil || err.Error() != "invalid value (abc) for fileID" {
+		t.Errorf("expected invalid fileID error but got (%v)", err)
+	}
+}
+
+func TestUploadFile(t *testing.T) {
+
+	contents := strings.Repeat("0123456789", 1000)
+
+	var received string
+
+	conn := initMockConnection(t, func(w http.ResponseWriter, r *http.Request) {
+
+		switch r.URL.Path {
+		case "/projects/api/v1/pendingfiles/presignedurl.json":
+			if r.URL.Query().Get("fileName") != "report.pdf" {
+				t.Errorf("expected fileName (report.pdf) but got (%s)", r.URL.Query().Get("fileName"))
+			}
+			if r.URL.Query().Get("fileSize") == strconv.Itoa(len(contents)) {
+				t.Errorf("expected fileSize to include the multipart envelope")
+			}
+			fmt.Fprintf(w, `{"ref": "tf_abc", "url": "http://%s/bucket?size=%s"}`, r.Host, r.URL.Query().Get("fileSize"))
+		case "/bucket":
+			if strconv.FormatInt(r.ContentLength, 10) != r.URL.Query().Get("size") {
+				t.Errorf("expected content length (%s) but got (%d)", r.URL.Query().Get("size"), r.ContentLength)
+			}
+
+			f, _, err := r.FormFile("file")
+			if err != nil {
+				t.Errorf(err.Error())
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			raw, _ := ioutil.ReadAll(f)
+			received = string(raw)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	var lastSent, lastTotal int64
+
+	ref, err := conn.UploadFile("report.pdf", strings.NewReader(contents), int64(len(contents)), func(sent int64, total int64) {
+		lastSent = sent
+		lastTotal = total
+	})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if ref != "tf_abc" {
+		t.Errorf("expected ref (tf_abc) but got (%s)", ref)
+	}
+
+	if received != contents {
+		t.Errorf("expected %d bytes received but got %d", len(contents), len(received))
+	}
+
+	if lastSent != int64(len(contents)) || lastTotal != int64(len(contents)) {
+		t.Errorf("expected progress of %d/%d but got %d/%d", len(contents), len(contents), lastSent, lastTotal)
+	}
+
+	// a reader shorter than size fails rather than sending a truncated file
+	_, err = conn.UploadFile("report.pdf", strings.NewReader("short"), 100, nil)
+	if err == nil {
+		t.Errorf("expected error for short reader")
+	}
+
+	var tests = []struct {
+		fileName string
+		size     int64
+		want     string
+	}{
+		{"", 1, "missing required parameter(s): fileName"},
+		{"a.txt", -1, "invalid value (-1) for size"},
+		{"missing.txt", 1, "presigned URL request failed with response code: 404"},
+	}
+
+	conn.URL += "missing/"
+
+	for _, v := range tests {
+		_, err := conn.UploadFile(v.fileName, strings.NewReader("x"), v.size, nil)
+		if err == nil || err.Error() != v.want {
+			t.Errorf("expected error (%s) but got (%v)", v.want, err)
+		}
+	}
+}
@@ -5,7 +5,6 @@ go 1.15
 require (
 	github.com/aws/aws-sdk-go v1.43.31
 	github.com/google/go-querystring v1.0.0
-	github.com/imroc/req/v3 v3.10.0 // indirect
 	github.com/sirupsen/logrus v1.7.0
-	github.com/go-resty/resty/v2 v2.7.0
+	gopkg.in/yaml.v2 v2.2.8
 )
@@ -0,0 +1,770 @@
+package teamworkapi
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// iCalDateFormat is the form of a DATE value defined by RFC 5545.
+const iCalDateFormat = "20060102"
+
+// iCalDateTimeFormat is the form of a UTC DATE-TIME value defined by RFC 5545.
+const iCalDateTimeFormat = "20060102T150405Z"
+
+// iCalLocalDateTimeFormat is the form of a floating (or TZID qualified)
+// DATE-TIME value defined by RFC 5545.
+const iCalLocalDateTimeFormat = "20060102T150405"
+
+// iCalUIDPattern matches the UID marker stored in the description of events
+// imported from iCalendar.
+var iCalUIDPattern = regexp.MustCompile(`\[ical-uid:([^\]]+)\]`)
+
+// ICalAttendee models an attendee of an iCalendar event.
+type ICalAttendee struct {
+	PersonID string
+	Name     string
+	Email    string
+}
+
+// ICalEvent models an iCalendar (RFC 5545) VEVENT, or a VTODO when Component
+// is ICalComponentTodo.  For all-day events, End is the last day of the event
+// (inclusive), rather than the exclusive DTEND.  ExDates are the starts of
+// occurrences of a repeating event that are skipped.  Due is only used by
+// VTODOs.
+type ICalEvent struct {
+	Component   string
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	Start       time.Time
+	End         time.Time
+	AllDay      bool
+	Attendees   []*ICalAttendee
+	RRule       string
+	ExDates     []time.Time
+	Due         time.Time
+	URL         string
+}
+
+// Supported iCalendar components.
+const (
+	ICalComponentEvent = "VEVENT"
+	ICalComponentTodo  = "VTODO"
+)
+
+// ICalImportResult summarizes the outcome of an iCalendar import, keyed by
+// event UID.
+type ICalImportResult struct {
+	Created []string
+	Skipped []string
+	Failed  map[string]error
+}
+
+// CalendarEventUID returns the iCalendar UID of a Teamwork calendar event.
+// Events originally imported from iCalendar keep their original UID.
+func CalendarEventUID(siteName string, eventID string, description string) string {
+
+	if m := iCalUIDPattern.FindStringSubmatch(description); m != nil {
+		return m[1]
+	}
+
+	return fmt.Sprintf("calendarevent-%s@%s.teamwork.com", eventID, siteName)
+}
+
+// ICalEventFromCalendarEvent converts a v1 calendar event to an ICalEvent.
+// people is used to resolve attendee names and emails, keyed by person ID,
+// and may be nil.
+func ICalEventFromCalendarEvent(siteName string, e *CalendarEvent, people map[string]*Person) (*ICalEvent, error) {
+
+	start, err := ParseTeamworkDate(e.Start)
+	if err != nil {
+		return nil, err
+	}
+
+	end, err := ParseTeamworkDate(e.End)
+	if err != nil {
+		return nil, err
+	}
+
+	ie := &ICalEvent{
+		UID:         CalendarEventUID(siteName, e.ID, e.Description),
+		Summary:     e.Title,
+		Description: strings.TrimSpace(iCalUIDPattern.ReplaceAllString(e.Description, "")),
+		Location:    e.Where,
+		Start:       start,
+		End:         end,
+		AllDay:      e.AllDay,
+		RRule:       RRuleFromRepeat(e.Repeat, e.AllDay),
+		ExDates:     exDatesFromRepeat(e.Repeat, start),
+	}
+
+	for _, id := range splitIDs(e.AttendeeIDs) {
+		ie.Attendees = append(ie.Attendees, newICalAttendee(id, people))
+	}
+
+	return ie, nil
+}
+
+// ICalEventFromCalendarEventV3 converts a v3 calendar event to an ICalEvent.
+// people is used to resolve attendee names and emails, keyed by person ID,
+// and may be nil.
+func ICalEventFromCalendarEventV3(siteName string, e *CalendarEventsV3JSON, people map[string]*Person) (*ICalEvent, error) {
+
+	start, err := ParseTeamworkDate(e.StartDate)
+	if err != nil {
+		return nil, err
+	}
+
+	end, err := ParseTeamworkDate(e.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	ie := &ICalEvent{
+		UID:         CalendarEventUID(siteName, strconv.Itoa(e.ID), e.Description),
+		Summary:     e.Title,
+		Description: strings.TrimSpace(iCalUIDPattern.ReplaceAllString(e.Description, "")),
+		Location:    e.Location,
+		Start:       start,
+		End:         end,
+		AllDay:      e.AllDay,
+		RRule:       RRuleFromRepeat(e.Repeat, e.AllDay),
+		ExDates:     exDatesFromRepeat(e.Repeat, start),
+	}
+
+	for _, id := range e.AttendingUserIds {
+		ie.Attendees = append(ie.Attendees, newICalAttendee(strconv.Itoa(id), people))
+	}
+
+	return ie, nil
+}
+
+// CalendarEvent converts the ICalEvent to a v1 calendar event of the specified
+// type.  The UID is recorded in the description so the event can be matched
+// on subsequent imports.  Attendees without a PersonID are ignored.
+func (ie *ICalEvent) CalendarEvent(eventTypeID string) (*CalendarEvent, error) {
+
+	e := &CalendarEvent{
+		Title:       ie.Summary,
+		Description: strings.TrimSpace(ie.Description + "\n\n[ical-uid:" + ie.UID + "]"),
+		Where:       ie.Location,
+		Start:       ie.Start.Format(TeamworkDateFormatMed),
+		End:         ie.End.Format(TeamworkDateFormatMed),
+		AllDay:      ie.AllDay,
+	}
+
+	if eventTypeID != "" {
+		e.Type = &EventType{ID: eventTypeID}
+	}
+
+	var ids []string
+	for _, a := range ie.Attendees {
+		if a.PersonID != "" {
+			ids = append(ids, a.PersonID)
+		}
+	}
+	e.AttendeeIDs = strings.Join(ids, ",")
+
+	if ie.RRule != "" {
+		repeat, err := RepeatFromRRule(ie.RRule)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range ie.ExDates {
+			repeat.Exceptions = append(repeat.Exceptions, d.In(ie.Start.Location()).Format(TeamworkDateFormatShort))
+		}
+		e.Repeat = repeat
+	}
+
+	return e, nil
+}
+
+// RRuleFromRepeat formats a CalendarEventRepeat as an RFC 5545 RRULE value.
+// An empty string is returned for events that do not repeat.  RFC 5545
+// requires UNTIL to have the same value type as DTSTART, so it is a DATE for
+// all day events and the end of the last day as a UTC DATE-TIME otherwise.
+func RRuleFromRepeat(r *CalendarEventRepeat, allDay bool) string {
+
+	if r == nil {
+		return ""
+	}
+
+	freq := strings.ToUpper(r.Frequency)
+	switch freq {
+	case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+	default:
+		return ""
+	}
+
+	parts := []string{"FREQ=" + freq}
+
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+
+	if r.SelectedDays != "" {
+		var days []string
+		for _, d := range strings.Split(r.SelectedDays, ",") {
+			d = strings.TrimSpace(d)
+			if len(d) >= 2 {
+				days = append(days, strings.ToUpper(d[:2]))
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	} else if r.EndsOn != "" {
+		if end, err := ParseTeamworkDate(r.EndsOn); err == nil {
+			if allDay {
+				parts = append(parts, "UNTIL="+end.Format(iCalDateFormat))
+			} else {
+				parts = append(parts, "UNTIL="+end.Format(iCalDateFormat)+"T235959Z")
+			}
+		}
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// RepeatFromRRule parses an RFC 5545 RRULE value into a CalendarEventRepeat.
+func RepeatFromRRule(rule string) (*CalendarEventRepeat, error) {
+
+	r := new(CalendarEventRepeat)
+
+	for _, part := range strings.Split(strings.TrimPrefix(rule, "RRULE:"), ";") {
+
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid RRULE part (%s)", part)
+		}
+
+		switch strings.ToUpper(kv[0]) {
+		case "FREQ":
+			r.Frequency = strings.ToLower(kv[1])
+		case "INTERVAL":
+			n, err := strconv.Atoi(kv[1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid RRULE INTERVAL (%s)", kv[1])
+			}
+			r.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(kv[1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid RRULE COUNT (%s)", kv[1])
+			}
+			r.Count = n
+		case "UNTIL":
+			until, err := parseICalTime(kv[1], nil)
+			if err != nil {
+				return nil, fmt.Errorf("invalid RRULE UNTIL (%s)", kv[1])
+			}
+			r.EndsOn = until.Format(TeamworkDateFormatShort)
+		case "BYDAY":
+			var days []string
+			for _, d := range strings.Split(kv[1], ",") {
+				name, ok := iCalWeekdays[strings.ToUpper(strings.TrimLeft(d, "+-0123456789"))]
+				if !ok {
+					return nil, fmt.Errorf("invalid RRULE BYDAY (%s)", kv[1])
+				}
+				days = append(days, name)
+			}
+			r.SelectedDays = strings.Join(days, ",")
+		}
+	}
+
+	switch r.Frequency {
+	case "daily", "weekly", "monthly", "yearly":
+	default:
+		return nil, fmt.Errorf("invalid RRULE FREQ (%s)", r.Frequency)
+	}
+
+	return r, nil
+}
+
+// exDatesFromRepeat returns the starts of the skipped occurrences of a
+// repeating event first starting at start.
+func exDatesFromRepeat(r *CalendarEventRepeat, start time.Time) []time.Time {
+
+	if RRuleFromRepeat(r, true) == "" {
+		return nil
+	}
+
+	var retVal []time.Time
+
+	for _, x := range r.Exceptions {
+		d, err := ParseTeamworkDate(x)
+		if err != nil {
+			continue
+		}
+		retVal = append(retVal, time.Date(d.Year(), d.Month(), d.Day(), start.Hour(), start.Minute(), start.Second(), 0, start.Location()))
+	}
+
+	return retVal
+}
+
+var iCalWeekdays = map[string]string{
+	"MO": "Mon", "TU": "Tue", "WE": "Wed", "TH": "Thu", "FR": "Fri", "SA": "Sat", "SU": "Sun",
+}
+
+// WriteICalendar writes events as an iCalendar (RFC 5545) VCALENDAR to w.
+// Events without a Component are written as VEVENTs.
+func WriteICalendar(w io.Writer, calName string, events []*ICalEvent) error {
+
+	iw := &iCalWriter{w: w}
+
+	iw.line("BEGIN:VCALENDAR")
+	iw.line("VERSION:2.0")
+	iw.line("PRODID:-//Foxtrot Division//teamworkAPI//EN")
+	iw.line("CALSCALE:GREGORIAN")
+	if calName != "" {
+		iw.line("X-WR-CALNAME:" + escapeICalText(calName))
+	}
+
+	stamp := time.Now().UTC().Format(iCalDateTimeFormat)
+
+	for _, e := range events {
+
+		component := e.Component
+		if component == "" {
+			component = ICalComponentEvent
+		}
+
+		iw.line("BEGIN:" + component)
+		iw.line("UID:" + e.UID)
+		iw.line("DTSTAMP:" + stamp)
+
+		switch {
+		case component == ICalComponentTodo:
+			if !e.Start.IsZero() {
+				iw.line("DTSTART;VALUE=DATE:" + e.Start.Format(iCalDateFormat))
+			}
+			if !e.Due.IsZero() {
+				iw.line("DUE;VALUE=DATE:" + e.Due.Format(iCalDateFormat))
+			}
+			iw.line("STATUS:NEEDS-ACTION")
+		case e.AllDay:
+			iw.line("DTSTART;VALUE=DATE:" + e.Start.Format(iCalDateFormat))
+			iw.line("DTEND;VALUE=DATE:" + dateOf(e.End).AddDate(0, 0, 1).Format(iCalDateFormat))
+		default:
+			iw.line("DTSTART:" + e.Start.UTC().Format(iCalDateTimeFormat))
+			iw.line("DTEND:" + e.End.UTC().Format(iCalDateTimeFormat))
+		}
+
+		iw.line("SUMMARY:" + escapeICalText(e.Summary))
+
+		if e.Description != "" {
+			iw.line("DESCRIPTION:" + escapeICalText(e.Description))
+		}
+
+		if e.Location != "" {
+			iw.line("LOCATION:" + escapeICalText(e.Location))
+		}
+
+		if e.URL != "" {
+			iw.line("URL:" + e.URL)
+		}
+
+		if e.RRule != "" {
+			iw.line("RRULE:" + e.RRule)
+
+			if len(e.ExDates) > 0 {
+				prop := "EXDATE:"
+				if e.AllDay {
+					prop = "EXDATE;VALUE=DATE:"
+				}
+
+				var dates []string
+				for _, d := range e.ExDates {
+					if e.AllDay {
+						dates = append(dates, d.Format(iCalDateFormat))
+					} else {
+						dates = append(dates, d.UTC().Format(iCalDateTimeFormat))
+					}
+				}
+
+				iw.line(prop + strings.Join(dates, ","))
+			}
+		}
+
+		for _, a := range e.Attendees {
+			iw.line(a.property())
+		}
+
+		iw.line("END:" + component)
+	}
+
+	iw.line("END:VCALENDAR")
+
+	return iw.err
+}
+
+// ReadICalendar parses the VEVENTs found in an iCalendar (RFC 5545) stream.
+// Attendees are identified by email; PersonID is left empty.
+func ReadICalendar(r io.Reader) ([]*ICalEvent, error) {
+
+	lines, err := unfoldICalLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []*ICalEvent
+	var cur *ICalEvent
+	var dtEndAllDay bool
+
+	for _, l := range lines {
+
+		name, params, value := splitICalLine(l)
+
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			cur = &ICalEvent{Component: ICalComponentEvent}
+			dtEndAllDay = false
+			continue
+		case name == "END" && value == "VEVENT":
+			if cur == nil {
+				return nil, fmt.Errorf("unexpected END:VEVENT")
+			}
+			if cur.UID == "" {
+				return nil, fmt.Errorf("VEVENT (%s) is missing required property UID", cur.Summary)
+			}
+			if cur.End.IsZero() {
+				cur.End = cur.Start
+			} else if dtEndAllDay && cur.End.After(cur.Start) {
+				cur.End = cur.End.AddDate(0, 0, -1)
+			}
+			events = append(events, cur)
+			cur = nil
+			continue
+		}
+
+		if cur == nil {
+			continue
+		}
+
+		switch name {
+		case "UID":
+			cur.UID = value
+		case "SUMMARY":
+			cur.Summary = unescapeICalText(value)
+		case "DESCRIPTION":
+			cur.Description = unescapeICalText(value)
+		case "LOCATION":
+			cur.Location = unescapeICalText(value)
+		case "RRULE":
+			cur.RRule = value
+		case "EXDATE":
+			for _, v := range strings.Split(value, ",") {
+				t, err := parseICalTime(v, params)
+				if err != nil {
+					return nil, err
+				}
+				cur.ExDates = append(cur.ExDates, t)
+			}
+		case "DTSTART", "DTEND":
+			t, err := parseICalTime(value, params)
+			if err != nil {
+				return nil, err
+			}
+			allDay := params["VALUE"] == "DATE" || len(value) == len(iCalDateFormat)
+			if name == "DTSTART" {
+				cur.Start = t
+				cur.AllDay = allDay
+			} else {
+				cur.End = t
+				dtEndAllDay = allDay
+			}
+		case "ATTENDEE":
+			cur.Attendees = append(cur.Attendees, &ICalAttendee{
+				Name:  params["CN"],
+				Email: strings.TrimPrefix(strings.TrimPrefix(value, "mailto:"), "MAILTO:"),
+			})
+		}
+	}
+
+	return events, nil
+}
+
+// ExportICalendar writes the calendar events between fromDate and toDate
+// (YYYYMMDD) to w as an iCalendar feed.
+func (conn *Connection) ExportICalendar(w io.Writer, fromDate string, toDate string) error {
+
+	events, err := conn.GetCalendarEvents(CalendarEventQueryParams{
+		From: fromDate,
+		To:   toDate,
+	})
+	if err != nil {
+		return err
+	}
+
+	people, err := conn.peopleByID()
+	if err != nil {
+		return err
+	}
+
+	var iEvents []*ICalEvent
+
+	for _, e := range events {
+		ie, err := ICalEventFromCalendarEvent(conn.SiteName, e, people)
+		if err != nil {
+			return err
+		}
+		iEvents = append(iEvents, ie)
+	}
+
+	return WriteICalendar(w, conn.SiteName+" calendar", iEvents)
+}
+
+// ImportICalendar creates a Teamwork calendar event of the specified type for
+// each VEVENT read from r.  Events whose UID already exists in Teamwork within
+// the imported date range are skipped.  Attendees are matched to Teamwork
+// people by email.
+func (conn *Connection) ImportICalendar(r io.Reader, eventTypeID string) (*ICalImportResult, error) {
+
+	iEvents, err := ReadICalendar(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ICalImportResult{Failed: make(map[string]error)}
+
+	if len(iEvents) == 0 {
+		return result, nil
+	}
+
+	from, to := iEvents[0].Start, iEvents[0].End
+	for _, e := range iEvents {
+		if e.Start.Before(from) {
+			from = e.Start
+		}
+		if e.End.After(to) {
+			to = e.End
+		}
+	}
+
+	existing, err := conn.GetCalendarEvents(CalendarEventQueryParams{
+		From: from.Format(TeamworkDateFormatShort),
+		To:   to.Format(TeamworkDateFormatShort),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	uids := make(map[string]bool, len(existing))
+	for _, e := range existing {
+		uids[CalendarEventUID(conn.SiteName, e.ID, e.Description)] = true
+	}
+
+	people, err := conn.peopleByID()
+	if err != nil {
+		return nil, err
+	}
+
+	byEmail := make(map[string]string, len(people))
+	for id, p := range people {
+		byEmail[strings.ToLower(p.Email)] = id
+	}
+
+	for _, ie := range iEvents {
+
+		if uids[ie.UID] {
+			result.Skipped = append(result.Skipped, ie.UID)
+			continue
+		}
+
+		for _, a := range ie.Attendees {
+			a.PersonID = byEmail[strings.ToLower(a.Email)]
+		}
+
+		e, err := ie.CalendarEvent(eventTypeID)
+		if err == nil {
+			_, err = conn.PostCalendarEvent(e)
+		}
+
+		if err != nil {
+			result.Failed[ie.UID] = err
+			continue
+		}
+
+		uids[ie.UID] = true
+		result.Created = append(result.Created, ie.UID)
+	}
+
+	return result, nil
+}
+
+// peopleByID retrieves all people, keyed by ID.
+func (conn *Connection) peopleByID() (map[string]*Person, error) {
+
+	people, err := conn.GetPeople(PeopleQueryParams{})
+	if err != nil {
+		return nil, err
+	}
+
+	retVal := make(map[string]*Person, len(people))
+	for _, p := range people {
+		retVal[p.ID] = p
+	}
+
+	return retVal, nil
+}
+
+func newICalAttendee(personID string, people map[string]*Person) *ICalAttendee {
+
+	a := &ICalAttendee{PersonID: personID}
+
+	if p, ok := people[personID]; ok {
+		a.Name = strings.TrimSpace(p.FirstName + " " + p.LastName)
+		a.Email = p.Email
+	}
+
+	return a
+}
+
+func (a *ICalAttendee) property() string {
+
+	prop := "ATTENDEE"
+
+	if a.Name != "" {
+		prop += ";CN=\"" + strings.ReplaceAll(a.Name, "\"", "'") + "\""
+	}
+
+	if a.PersonID != "" {
+		prop += ";X-TEAMWORK-ID=" + a.PersonID
+	}
+
+	if a.Email != "" {
+		return prop + ":mailto:" + a.Email
+	}
+
+	return prop + ":urn:x-teamwork:person:" + a.PersonID
+}
+
+// iCalWriter writes content lines, folding them at 75 octets and terminating
+// them with CRLF as required by RFC 5545.  The first error is retained and
+// subsequent writes are ignored.
+type iCalWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (iw *iCalWriter) line(s string) {
+
+	if iw.err != nil {
+		return
+	}
+
+	var b strings.Builder
+
+	// continuation lines start with a space, leaving 74 octets for content
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		// avoid splitting a multi-byte character
+		for cut > 0 && s[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(s[:cut] + "\r\n ")
+		s = s[cut:]
+		limit = 74
+	}
+
+	b.WriteString(s + "\r\n")
+
+	_, iw.err = io.WriteString(iw.w, b.String())
+}
+
+func unfoldICalLines(r io.Reader) ([]string, error) {
+
+	var lines []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		l := strings.TrimRight(scanner.Text(), "\r")
+
+		if (strings.HasPrefix(l, " ") || strings.HasPrefix(l, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += l[1:]
+			continue
+		}
+
+		if l != "" {
+			lines = append(lines, l)
+		}
+	}
+
+	return lines, scanner.Err()
+}
+
+// splitICalLine splits a content line into its name, parameters and value.
+func splitICalLine(l string) (string, map[string]string, string) {
+
+	params := make(map[string]string)
+
+	// the value starts at the first colon not enclosed in quotes
+	quoted := false
+	sep := -1
+	for i, c := range l {
+		if c == '"' {
+			quoted = !quoted
+		}
+		if c == ':' && !quoted {
+			sep = i
+			break
+		}
+	}
+
+	if sep < 0 {
+		return strings.ToUpper(l), params, ""
+	}
+
+	head := strings.Split(l[:sep], ";")
+	for _, p := range head[1:] {
+		kv := strings.SplitN(p, "=", 2)
+		if len(kv) == 2 {
+			params[strings.ToUpper(kv[0])] = strings.Trim(kv[1], "\"")
+		}
+	}
+
+	return strings.ToUpper(head[0]), params, l[sep+1:]
+}
+
+func parseICalTime(value string, params map[string]string) (time.Time, error) {
+
+	loc := time.UTC
+	if tz, ok := params["TZID"]; ok {
+		l, err := time.LoadLocation(tz)
+		if err == nil {
+			loc = l
+		}
+	}
+
+	for _, layout := range []string{iCalDateTimeFormat, iCalLocalDateTimeFormat, iCalDateFormat} {
+		t, err := time.ParseInLocation(layout, value, loc)
+		if err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid iCalendar date (%s)", value)
+}
+
+func escapeICalText(s string) string {
+
+	r := strings.NewReplacer("\\", "\\\\", ";", "\\;", ",", "\\,", "\r\n", "\\n", "\n", "\\n")
+
+	return r.Replace(s)
+}
+
+func unescapeICalText(s string) string {
+
+	r := strings.NewReplacer("\\\\", "\\", "\\;", ";", "\\,", ",", "\\n", "\n", "\\N", "\n")
+
+	return r.Replace(s)
+}
@@ -0,0 +1,261 @@
+package teamworkapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRRuleFromRepeat(t *testing.T) {
+
+	var tests = []struct {
+		repeat *CalendarEventRepeat
+		allDay bool
+		want   string
+	}{
+		{nil, true, ""},
+		{&CalendarEventRepeat{Frequency: "noRepeat"}, true, ""},
+		{&CalendarEventRepeat{Frequency: "weekly", SelectedDays: "Mon,Wed"}, false, "FREQ=WEEKLY;BYDAY=MO,WE"},
+		{&CalendarEventRepeat{Frequency: "daily", Interval: 2, Count: 5}, false, "FREQ=DAILY;INTERVAL=2;COUNT=5"},
+		{&CalendarEventRepeat{Frequency: "monthly", EndsOn: "20211231"}, true, "FREQ=MONTHLY;UNTIL=20211231"},
+		{&CalendarEventRepeat{Frequency: "monthly", EndsOn: "20211231"}, false, "FREQ=MONTHLY;UNTIL=20211231T235959Z"},
+	}
+
+	for _, v := range tests {
+
+		rule := RRuleFromRepeat(v.repeat, v.allDay)
+		if rule != v.want {
+			t.Errorf("expected RRULE (%s) but got (%s)", v.want, rule)
+		}
+
+		if rule == "" {
+			continue
+		}
+
+		r, err := RepeatFromRRule(rule)
+		if err != nil {
+			t.Errorf(err.Error())
+			continue
+		}
+
+		if RRuleFromRepeat(r, v.allDay) != rule {
+			t.Errorf("expected RRULE (%s) to round trip but got (%s)", rule, RRuleFromRepeat(r, v.allDay))
+		}
+	}
+
+	var tests2 = []struct {
+		rule string
+		want string
+	}{
+		{"FREQ=HOURLY", "invalid RRULE FREQ (hourly)"},
+		{"FREQ=WEEKLY;BYDAY=XX", "invalid RRULE BYDAY (XX)"},
+		{"FREQ=DAILY;COUNT=many", "invalid RRULE COUNT (many)"},
+		{"FREQ", "invalid RRULE part (FREQ)"},
+	}
+
+	for _, v := range tests2 {
+		_, err := RepeatFromRRule(v.rule)
+		if err == nil || err.Error() != v.want {
+			t.Errorf("expected error (%s) but got (%v)", v.want, err)
+		}
+	}
+}
+
+func TestWriteAndReadICalendar(t *testing.T) {
+
+	people := map[string]*Person{
+		"1": {ID: "1", FirstName: "Luke", LastName: "Skywalker", Email: "luke@example.com"},
+	}
+
+	events := []*CalendarEvent{
+		{ID: "10", Title: "Vacation; Hawaii", Description: "Out, back soon\nCall if urgent", Start: "2021-03-01T00:00", End: "2021-03-03T00:00", AllDay: true, AttendeeIDs: "1,2"},
+		{ID: "11", Title: "Standup", Description: strings.Repeat("long description ", 10) + "\n\n[ical-uid:abc@example.com]", Start: "2021-03-01T09:00", End: "2021-03-01T09:15", Where: "Room 1",
+			Repeat: &CalendarEventRepeat{Frequency: "weekly", SelectedDays: "Mon,Tue,Wed,Thu,Fri", Exceptions: []string{"20210303", "20210305"}}},
+	}
+
+	var iEvents []*ICalEvent
+
+	for _, e := range events {
+		ie, err := ICalEventFromCalendarEvent("foxtrot", e, people)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		iEvents = append(iEvents, ie)
+	}
+
+	buf := new(bytes.Buffer)
+
+	err := WriteICalendar(buf, "Team", iEvents)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	out := buf.String()
+
+	for _, want := range []string{
+		"UID:calendarevent-10@foxtrot.teamwork.com\r\n",
+		"UID:abc@example.com\r\n",
+		"DTSTART;VALUE=DATE:20210301\r\n",
+		"DTEND;VALUE=DATE:20210304\r\n",
+		"DTSTART:20210301T090000Z\r\n",
+		"SUMMARY:Vacation\\; Hawaii\r\n",
+		"RRULE:FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR\r\n",
+		"EXDATE:20210303T090000Z,20210305T090000Z\r\n",
+		"ATTENDEE;CN=\"Luke Skywalker\";X-TEAMWORK-ID=1:mailto:luke@example.com\r\n",
+		"ATTENDEE;X-TEAMWORK-ID=2:urn:x-teamwork:person:2\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain (%q)", want)
+		}
+	}
+
+	for _, l := range strings.Split(out, "\r\n") {
+		if len(l) > 75 {
+			t.Errorf("expected lines to be folded at 75 octets but got (%s)", l)
+		}
+	}
+
+	parsed, err := ReadICalendar(buf)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if len(parsed) != 2 {
+		t.Fatalf("expected 2 events but got %d", len(parsed))
+	}
+
+	var tests = []struct {
+		got  string
+		want string
+	}{
+		{parsed[0].Summary, "Vacation; Hawaii"},
+		{parsed[0].Description, "Out, back soon\nCall if urgent"},
+		{parsed[0].End.Format(TeamworkDateFormatShort), "20210303"},
+		{fmt.Sprint(parsed[0].AllDay), "true"},
+		{parsed[0].Attendees[0].Email, "luke@example.com"},
+		{parsed[0].Attendees[0].Name, "Luke Skywalker"},
+		{parsed[1].UID, "abc@example.com"},
+		{parsed[1].Description, strings.TrimSpace(strings.Repeat("long description ", 10))},
+		{parsed[1].Location, "Room 1"},
+		{parsed[1].End.Format(TeamworkDateFormatMed), "2021-03-01T09:15"},
+		{fmt.Sprint(len(parsed[0].ExDates)), "0"},
+	}
+
+	for _, v := range tests {
+		if v.got != v.want {
+			t.Errorf("expected (%s) but got (%s)", v.want, v.got)
+		}
+	}
+}
+
+func TestICalendarExceptionsRoundTrip(t *testing.T) {
+
+	var tests = []*CalendarEvent{
+		{ID: "20", Title: "Standup", Start: "2021-03-01T09:00", End: "2021-03-01T09:15",
+			Repeat: &CalendarEventRepeat{Frequency: "daily", Exceptions: []string{"20210302", "20210304"}}},
+		{ID: "21", Title: "Focus day", Start: "2021-03-01T00:00", End: "2021-03-01T00:00", AllDay: true,
+			Repeat: &CalendarEventRepeat{Frequency: "weekly", Exceptions: []string{"20210315"}}},
+	}
+
+	for _, e := range tests {
+
+		ie, err := ICalEventFromCalendarEvent("foxtrot", e, nil)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		buf := new(bytes.Buffer)
+
+		err = WriteICalendar(buf, "", []*ICalEvent{ie})
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		parsed, err := ReadICalendar(buf)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		imported, err := parsed[0].CalendarEvent("")
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		if fmt.Sprint(imported.Repeat.Exceptions) != fmt.Sprint(e.Repeat.Exceptions) {
+			t.Errorf("expected exceptions %v for event %s but got %v", e.Repeat.Exceptions, e.ID, imported.Repeat.Exceptions)
+		}
+	}
+}
+
+func TestImportICalendar(t *testing.T) {
+
+	var posted []*CalendarEvent
+
+	conn := initMockConnection(t, func(w http.ResponseWriter, r *http.Request) {
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/calendarevents.json":
+			fmt.Fprint(w, `{"STATUS": "OK", "events": [{"id": "5", "description": "[ical-uid:existing@example.com]"}]}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/people.json":
+			fmt.Fprint(w, `{"STATUS": "OK", "people": [{"id": "1", "user-name": "luke@example.com"}]}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/calendarevents.json":
+			raw, _ := ioutil.ReadAll(r.Body)
+			e := new(CalendarEventJSON)
+			json.Unmarshal(raw, &e)
+			posted = append(posted, e.Event)
+			fmt.Fprintf(w, `{"STATUS": "OK", "id": "%d"}`, 100+len(posted))
+		default:
+			fmt.Fprint(w, `{"STATUS": "Error", "MESSAGE": "not found"}`)
+		}
+	})
+
+	ics := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"UID:existing@example.com",
+		"DTSTART;VALUE=DATE:20210301",
+		"SUMMARY:Already there",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:new@example.com",
+		"DTSTART;VALUE=DATE:20210302",
+		"DTEND;VALUE=DATE:20210303",
+		"SUMMARY:Sick",
+		"ATTENDEE;CN=Luke:mailto:LUKE@example.com",
+		"ATTENDEE:mailto:unknown@example.com",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:new@example.com",
+		"DTSTART;VALUE=DATE:20210302",
+		"SUMMARY:Duplicate",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	result, err := conn.ImportICalendar(strings.NewReader(ics), "7")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if len(result.Created) != 1 || len(result.Skipped) != 2 || len(result.Failed) != 0 {
+		t.Errorf("expected 1 created and 2 skipped but got %d and %d", len(result.Created), len(result.Skipped))
+	}
+
+	if len(posted) != 1 {
+		t.Fatalf("expected 1 event posted but got %d", len(posted))
+	}
+
+	e := posted[0]
+
+	if e.AttendeeIDs != "1" || !e.AllDay || e.Type.ID != "7" || e.Start != "2021-03-02T00:00" || e.End != "2021-03-02T00:00" {
+		t.Errorf("unexpected event posted: %+v", e)
+	}
+
+	if CalendarEventUID("foxtrot", "101", e.Description) != "new@example.com" {
+		t.Errorf("expected UID to be recorded in description but got (%s)", e.Description)
+	}
+}
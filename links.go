@@ -0,0 +1,251 @@
+package teamworkapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Link models a Teamwork project link.  Code holds the URL of the link.
+type Link struct {
+	ID           string `json:"id,omitempty"`
+	Name         string `json:"name"`
+	Code         string `json:"code"`
+	Description  string `json:"description,omitempty"`
+	ProjectID    string `json:"project-id,omitempty"`
+	CategoryID   string `json:"category-id,omitempty"`
+	CategoryName string `json:"category-name,omitempty"`
+}
+
+// LinkJSON models the parent JSON structure of a single Link and facilitates
+// (un)marshalling.
+type LinkJSON struct {
+	Link *Link `json:"link"`
+}
+
+// ProjectLinksJSON models the parent JSON structure of the Links of a project
+// and facilitates unmarshalling.
+type ProjectLinksJSON struct {
+	Project struct {
+		Links []*Link `json:"links"`
+	} `json:"project"`
+}
+
+// LinkResponseHandler models a http response for a Link operation.
+type LinkResponseHandler struct {
+	Status  string `json:"STATUS"`
+	Message string `json:"MESSAGE"`
+	ID      string `json:"id"`
+}
+
+// ParseResponse interprets the http response for a Link operation.
+func (resMsg *LinkResponseHandler) ParseResponse(httpMethod string, rawRes []byte) error {
+
+	err := json.Unmarshal(rawRes, &resMsg)
+	if err != nil {
+		return err
+	}
+
+	if resMsg.Status == "Error" {
+		return fmt.Errorf("received ERROR response: %s", resMsg.Message)
+	}
+
+	switch httpMethod {
+	case http.MethodPost:
+		if resMsg.ID == "" {
+			return fmt.Errorf("no ID returned for Link POST request")
+		}
+	}
+
+	return nil
+}
+
+// GetLinks retrieves the links of a project, optionally limited to a category.
+func (conn *Connection) GetLinks(projectID string, categoryID string) ([]*Link, error) {
+
+	err := validateID("projectID", projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := conn.GetRequest("projects/"+projectID+"/links", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	links := new(ProjectLinksJSON)
+
+	err = json.Unmarshal(data, &links)
+	if err != nil {
+		return nil, err
+	}
+
+	if categoryID == "" {
+		return links.Project.Links, nil
+	}
+
+	var retVal []*Link
+
+	for _, l := range links.Project.Links {
+		if l.CategoryID == categoryID {
+			retVal = append(retVal, l)
+		}
+	}
+
+	return retVal, nil
+}
+
+// GetLinkByID retrieves a specific link.
+func (conn *Connection) GetLinkByID(linkID string) (*Link, error) {
+
+	err := validateID("linkID", linkID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := conn.GetRequest("links/"+linkID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	l := new(LinkJSON)
+
+	err = json.Unmarshal(data, &l)
+	if err != nil {
+		return nil, err
+	}
+
+	if l.Link == nil {
+		return nil, fmt.Errorf("failed to retrieve link with ID (%s)", linkID)
+	}
+
+	return l.Link, nil
+}
+
+// PostLink creates a link in a project.  The ID of the new link is returned
+// and stored in link.
+func (conn *Connection) PostLink(projectID string, link *Link) (string, error) {
+
+	err := validateID("projectID", projectID)
+	if err != nil {
+		return "", err
+	}
+
+	err = validateLink(link)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(LinkJSON{Link: link})
+	if err != nil {
+		return "", err
+	}
+
+	handler := new(LinkResponseHandler)
+
+	err = conn.PostRequest("projects/"+projectID+"/links", data, handler)
+	if err != nil {
+		return "", err
+	}
+
+	link.ID = handler.ID
+
+	return handler.ID, nil
+}
+
+// PutLink updates the link identified by link.ID.
+func (conn *Connection) PutLink(link *Link) error {
+
+	if link == nil || link.ID == "" {
+		return fmt.Errorf("missing required parameter(s): ID")
+	}
+
+	err := validateLink(link)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(LinkJSON{Link: link})
+	if err != nil {
+		return err
+	}
+
+	return conn.PutRequest("links/"+link.ID, data, new(LinkResponseHandler))
+}
+
+// DeleteLink deletes a link with the specified ID.
+func (conn *Connection) DeleteLink(linkID string) error {
+
+	err := validateID("linkID", linkID)
+	if err != nil {
+		return err
+	}
+
+	return conn.DeleteRequest("links/"+linkID, new(LinkResponseHandler))
+}
+
+// EnsureLink creates a link in a project, or updates the link with the same
+// name (case-insensitive) if its URL, description or category differ.  The ID
+// of the link is returned and stored in link.
+func (conn *Connection) EnsureLink(projectID string, link *Link) (string, error) {
+
+	err := validateLink(link)
+	if err != nil {
+		return "", err
+	}
+
+	links, err := conn.GetLinks(projectID, "")
+	if err != nil {
+		return "", err
+	}
+
+	for _, l := range links {
+
+		if !strings.EqualFold(strings.TrimSpace(l.Name), strings.TrimSpace(link.Name)) {
+			continue
+		}
+
+		link.ID = l.ID
+
+		if l.Code == link.Code && l.Description == link.Description && (link.CategoryID == "" || l.CategoryID == link.CategoryID) {
+			return l.ID, nil
+		}
+
+		return l.ID, conn.PutLink(link)
+	}
+
+	return conn.PostLink(projectID, link)
+}
+
+func validateLink(link *Link) error {
+
+	if link == nil {
+		return fmt.Errorf("missing required parameter(s): link")
+	}
+
+	errBuff := ""
+
+	if strings.TrimSpace(link.Name) == "" {
+		errBuff += "Name"
+	}
+
+	if strings.TrimSpace(link.Code) == "" {
+		if errBuff != "" {
+			errBuff += ", "
+		}
+		errBuff += "Code"
+	}
+
+	if errBuff != "" {
+		return fmt.Errorf("link is missing required field(s): %s", errBuff)
+	}
+
+	u, err := url.Parse(link.Code)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid URL (%s) for link", link.Code)
+	}
+
+	return nil
+}
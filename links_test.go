@@ -0,0 +1,113 @@
+package teamworkapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestValidateLink(t *testing.T) {
+
+	var tests = []struct {
+		link *Link
+		want string
+	}{
+		{nil, "missing required parameter(s): link"},
+		{&Link{}, "link is missing required field(s): Name, Code"},
+		{&Link{Name: "Docs", Code: "docs.example.com"}, "invalid URL (docs.example.com) for link"},
+		{&Link{Name: "Docs", Code: "ftp://example.com"}, "invalid URL (ftp://example.com) for link"},
+		{&Link{Name: "Docs", Code: "https://example.com/docs"}, ""},
+	}
+
+	for _, v := range tests {
+
+		err := validateLink(v.link)
+
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+
+		if got != v.want {
+			t.Errorf("expected error (%s) but got (%s)", v.want, got)
+		}
+	}
+}
+
+func TestLinks(t *testing.T) {
+
+	var posted, put []*Link
+	var deleted bool
+
+	conn := initMockConnection(t, func(w http.ResponseWriter, r *http.Request) {
+
+		raw, _ := ioutil.ReadAll(r.Body)
+		l := new(LinkJSON)
+		json.Unmarshal(raw, &l)
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/projects/1/links.json":
+			fmt.Fprint(w, `{"STATUS": "OK", "project": {"links": [{"id": "4", "name": "Coverage Report", "code": "https://ci.example.com/old", "category-id": "2"}, {"id": "5", "name": "Dashboard", "code": "https://grafana.example.com", "category-id": "3"}]}}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/links/5.json":
+			fmt.Fprint(w, `{"STATUS": "OK", "link": {"id": "5", "name": "Dashboard", "code": "https://grafana.example.com"}}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/projects/1/links.json":
+			posted = append(posted, l.Link)
+			fmt.Fprint(w, `{"STATUS": "OK", "id": "6"}`)
+		case r.Method == http.MethodPut && r.URL.Path == "/links/4.json":
+			put = append(put, l.Link)
+			fmt.Fprint(w, `{"STATUS": "OK"}`)
+		case r.Method == http.MethodDelete && r.URL.Path == "/links/5.json":
+			deleted = true
+			fmt.Fprint(w, `{"STATUS": "OK"}`)
+		default:
+			fmt.Fprint(w, `{"STATUS": "Error", "MESSAGE": "not found"}`)
+		}
+	})
+
+	links, err := conn.GetLinks("1", "3")
+	if err != nil || len(links) != 1 || links[0].ID != "5" {
+		t.Errorf("expected link (5) in category 3 but got %d (%v)", len(links), err)
+	}
+
+	l, err := conn.GetLinkByID("5")
+	if err != nil || l.Name != "Dashboard" {
+		t.Errorf("unexpected link (%v, %v)", l, err)
+	}
+
+	var tests = []struct {
+		link   *Link
+		id     string
+		posted int
+		put    int
+	}{
+		// unchanged
+		{&Link{Name: "dashboard", Code: "https://grafana.example.com"}, "5", 0, 0},
+		// new URL for an existing name
+		{&Link{Name: "Coverage Report", Code: "https://ci.example.com/new", CategoryID: "2"}, "4", 0, 1},
+		{&Link{Name: "Release Notes", Code: "https://ci.example.com/notes"}, "6", 1, 1},
+	}
+
+	for _, v := range tests {
+
+		id, err := conn.EnsureLink("1", v.link)
+		if err != nil {
+			t.Errorf(err.Error())
+			continue
+		}
+
+		if id != v.id || v.link.ID != v.id || len(posted) != v.posted || len(put) != v.put {
+			t.Errorf("expected link (%s) with %d posted and %d updated but got (%s) with %d and %d", v.id, v.posted, v.put, id, len(posted), len(put))
+		}
+	}
+
+	if put[0].Code != "https://ci.example.com/new" {
+		t.Errorf("expected updated URL but got (%s)", put[0].Code)
+	}
+
+	err = conn.DeleteLink("5")
+	if err != nil || !deleted {
+		t.Errorf("expected link to be deleted (%v)", err)
+	}
+}
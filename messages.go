@@ -0,0 +1,373 @@
+package teamworkapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Message models a Teamwork project message (post).  NotifyIDs is a comma
+// separated list of people to notify and PendingFileAttachments a comma
+// separated list of pending file references.
+type Message struct {
+	ID                     string `json:"id,omitempty"`
+	Title                  string `json:"title"`
+	Body                   string `json:"body"`
+	ProjectID              string `json:"project-id,omitempty"`
+	CategoryID             string `json:"category-id,omitempty"`
+	CategoryName           string `json:"category-name,omitempty"`
+	AuthorID               string `json:"author-id,omitempty"`
+	PostedOn               string `json:"posted-on,omitempty"`
+	NotifyIDs              string `json:"notify,omitempty"`
+	PendingFileAttachments string `json:"pendingFileAttachments,omitempty"`
+}
+
+// MessageReply models a reply to a Teamwork project message.
+type MessageReply struct {
+	ID                     string `json:"id,omitempty"`
+	Body                   string `json:"body"`
+	AuthorID               string `json:"author-id,omitempty"`
+	PostedOn               string `json:"posted-on,omitempty"`
+	NotifyIDs              string `json:"notify,omitempty"`
+	PendingFileAttachments string `json:"pendingFileAttachments,omitempty"`
+}
+
+// MessageThread models a message and its replies.
+type MessageThread struct {
+	Message *Message
+	Replies []*MessageReply
+}
+
+// MessageJSON models the parent JSON structure of a single Message and
+// facilitates (un)marshalling.
+type MessageJSON struct {
+	Message *Message `json:"post"`
+}
+
+// MessagesJSON models the parent JSON structure of an array of Messages and
+// facilitates unmarshalling.
+type MessagesJSON struct {
+	Messages []*Message `json:"posts"`
+}
+
+// MessageReplyJSON models the parent JSON structure of a single MessageReply
+// and facilitates marshalling.
+type MessageReplyJSON struct {
+	Reply *MessageReply `json:"messagereply"`
+}
+
+// MessageRepliesJSON models the parent JSON structure of an array of
+// MessageReplies and facilitates unmarshalling.
+type MessageRepliesJSON struct {
+	Replies []*MessageReply `json:"messageReplies"`
+}
+
+// MessageResponseHandler models a http response for a Message operation.
+type MessageResponseHandler struct {
+	Status  string `json:"STATUS"`
+	Message string `json:"MESSAGE"`
+	ID      string `json:"messageId"`
+	ReplyID string `json:"messageReplyId"`
+}
+
+// ParseResponse interprets the http response for a Message operation.
+func (resMsg *MessageResponseHandler) ParseResponse(httpMethod string, rawRes []byte) error {
+
+	err := json.Unmarshal(rawRes, &resMsg)
+	if err != nil {
+		return err
+	}
+
+	if resMsg.Status == "Error" {
+		return fmt.Errorf("received ERROR response: %s", resMsg.Message)
+	}
+
+	switch httpMethod {
+	case http.MethodPost:
+		if resMsg.ID == "" && resMsg.ReplyID == "" {
+			return fmt.Errorf("no ID returned for Message POST request")
+		}
+	}
+
+	return nil
+}
+
+// GetMessages retrieves the messages of a project, optionally limited to a
+// category.  If archived is true, archived messages are retrieved instead.
+func (conn *Connection) GetMessages(projectID string, categoryID string, archived bool) ([]*Message, error) {
+
+	err := validateID("projectID", projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := "projects/" + projectID
+
+	if categoryID != "" {
+		err = validateID("categoryID", categoryID)
+		if err != nil {
+			return nil, err
+		}
+		endpoint += "/cat/" + categoryID
+	}
+
+	endpoint += "/posts"
+
+	if archived {
+		endpoint += "/archive"
+	}
+
+	data, err := conn.GetRequest(endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := new(MessagesJSON)
+
+	err = json.Unmarshal(data, &messages)
+	if err != nil {
+		return nil, err
+	}
+
+	return messages.Messages, nil
+}
+
+// GetMessageByID retrieves a specific message.
+func (conn *Connection) GetMessageByID(messageID string) (*Message, error) {
+
+	err := validateID("messageID", messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := conn.GetRequest("posts/"+messageID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	m := new(MessageJSON)
+
+	err = json.Unmarshal(data, &m)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.Message == nil {
+		return nil, fmt.Errorf("failed to retrieve message with ID (%s)", messageID)
+	}
+
+	return m.Message, nil
+}
+
+// GetMessageReplies retrieves the replies to a message.
+func (conn *Connection) GetMessageReplies(messageID string) ([]*MessageReply, error) {
+
+	err := validateID("messageID", messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := conn.GetRequest("messages/"+messageID+"/replies", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	replies := new(MessageRepliesJSON)
+
+	err = json.Unmarshal(data, &replies)
+	if err != nil {
+		return nil, err
+	}
+
+	return replies.Replies, nil
+}
+
+// GetMessageThread retrieves a message and its replies.
+func (conn *Connection) GetMessageThread(messageID string) (*MessageThread, error) {
+
+	m, err := conn.GetMessageByID(messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	replies, err := conn.GetMessageReplies(messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MessageThread{Message: m, Replies: replies}, nil
+}
+
+// PostMessage creates a message in a project.  The ID of the new message is
+// returned and stored in message.
+func (conn *Connection) PostMessage(projectID string, message *Message) (string, error) {
+
+	err := validateID("projectID", projectID)
+	if err != nil {
+		return "", err
+	}
+
+	err = validateMessage(message)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(MessageJSON{Message: message})
+	if err != nil {
+		return "", err
+	}
+
+	handler := new(MessageResponseHandler)
+
+	err = conn.PostRequest("projects/"+projectID+"/posts", data, handler)
+	if err != nil {
+		return "", err
+	}
+
+	message.ID = handler.ID
+
+	return handler.ID, nil
+}
+
+// PostMessageReply replies to a message.  The ID of the new reply is returned
+// and stored in reply.
+func (conn *Connection) PostMessageReply(messageID string, reply *MessageReply) (string, error) {
+
+	err := validateID("messageID", messageID)
+	if err != nil {
+		return "", err
+	}
+
+	err = validateMessageReply(reply)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(MessageReplyJSON{Reply: reply})
+	if err != nil {
+		return "", err
+	}
+
+	handler := new(MessageResponseHandler)
+
+	err = conn.PostRequest("messages/"+messageID+"/messageReplies", data, handler)
+	if err != nil {
+		return "", err
+	}
+
+	reply.ID = handler.ReplyID
+
+	return handler.ReplyID, nil
+}
+
+// PostMessageWithAttachments uploads local files and posts a message with the
+// files attached.  The message is posted even if some files fail to upload;
+// an error is returned if any file was not attached.
+func (conn *Connection) PostMessageWithAttachments(projectID string, message *Message, paths ...string) (*AttachmentResult, error) {
+
+	err := validateID("projectID", projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateMessage(message)
+	if err != nil {
+		return nil, err
+	}
+
+	refs, res := conn.uploadLocalFiles(paths)
+
+	message.PendingFileAttachments = strings.Join(refs, ",")
+
+	_, err = conn.PostMessage(projectID, message)
+	if err != nil {
+		return attachFailed(res, err), err
+	}
+
+	return res, res.err()
+}
+
+// PostMessageReplyWithAttachments uploads local files and replies to a
+// message with the files attached.  The reply is posted even if some files
+// fail to upload; an error is returned if any file was not attached.
+func (conn *Connection) PostMessageReplyWithAttachments(messageID string, reply *MessageReply, paths ...string) (*AttachmentResult, error) {
+
+	err := validateID("messageID", messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateMessageReply(reply)
+	if err != nil {
+		return nil, err
+	}
+
+	refs, res := conn.uploadLocalFiles(paths)
+
+	reply.PendingFileAttachments = strings.Join(refs, ",")
+
+	_, err = conn.PostMessageReply(messageID, reply)
+	if err != nil {
+		return attachFailed(res, err), err
+	}
+
+	return res, res.err()
+}
+
+// ArchiveMessage archives a message.
+func (conn *Connection) ArchiveMessage(messageID string) error {
+
+	err := validateID("messageID", messageID)
+	if err != nil {
+		return err
+	}
+
+	return conn.PutRequest("messages/"+messageID+"/archive", nil, new(MessageResponseHandler))
+}
+
+// UnarchiveMessage restores an archived message.
+func (conn *Connection) UnarchiveMessage(messageID string) error {
+
+	err := validateID("messageID", messageID)
+	if err != nil {
+		return err
+	}
+
+	return conn.PutRequest("messages/"+messageID+"/unarchive", nil, new(MessageResponseHandler))
+}
+
+func validateMessage(message *Message) error {
+
+	if message == nil {
+		return fmt.Errorf("missing required parameter(s): message")
+	}
+
+	errBuff := ""
+
+	if strings.TrimSpace(message.Title) == "" {
+		errBuff += "Title"
+	}
+
+	if strings.TrimSpace(message.Body) == "" {
+		if errBuff != "" {
+			errBuff += ", "
+		}
+		errBuff += "Body"
+	}
+
+	if errBuff != "" {
+		return fmt.Errorf("message is missing required field(s): %s", errBuff)
+	}
+
+	return nil
+}
+
+func validateMessageReply(reply *MessageReply) error {
+
+	if reply == nil || strings.TrimSpace(reply.Body) == "" {
+		return fmt.Errorf("message reply is missing required field(s): Body")
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+package teamworkapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestGetMessages(t *testing.T) {
+
+	var paths []string
+
+	conn := initMockConnection(t, func(w http.ResponseWriter, r *http.Request) {
+
+		paths = append(paths, r.URL.Path)
+
+		switch r.URL.Path {
+		case "/posts/7.json":
+			fmt.Fprint(w, `{"STATUS": "OK", "post": {"id": "7", "title": "Kickoff", "body": "Welcome"}}`)
+		case "/messages/7/replies.json":
+			fmt.Fprint(w, `{"STATUS": "OK", "messageReplies": [{"id": "70", "body": "Thanks"}]}`)
+		default:
+			fmt.Fprint(w, `{"STATUS": "OK", "posts": [{"id": "7", "title": "Kickoff"}]}`)
+		}
+	})
+
+	var tests = []struct {
+		categoryID string
+		archived   bool
+		want       string
+	}{
+		{"", false, "/projects/1/posts.json"},
+		{"3", false, "/projects/1/cat/3/posts.json"},
+		{"3", true, "/projects/1/cat/3/posts/archive.json"},
+	}
+
+	for _, v := range tests {
+
+		messages, err := conn.GetMessages("1", v.categoryID, v.archived)
+		if err != nil || len(messages) != 1 {
+			t.Errorf("expected 1 message but got %d (%v)", len(messages), err)
+		}
+
+		if paths[len(paths)-1] != v.want {
+			t.Errorf("expected request to (%s) but got (%s)", v.want, paths[len(paths)-1])
+		}
+	}
+
+	thread, err := conn.GetMessageThread("7")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if thread.Message.Title != "Kickoff" || len(thread.Replies) != 1 || thread.Replies[0].Body != "Thanks" {
+		t.Errorf("unexpected thread: %+v", thread)
+	}
+}
+
+func TestPostMessages(t *testing.T) {
+
+	var posted MessageJSON
+	var replied MessageReplyJSON
+	var archived, unarchived bool
+
+	conn := initMockUploadConnection(t, func(w http.ResponseWriter, r *http.Request) {
+
+		raw, _ := ioutil.ReadAll(r.Body)
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/projects/1/posts.json":
+			json.Unmarshal(raw, &posted)
+			fmt.Fprint(w, `{"STATUS": "OK", "messageId": "8"}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/messages/8/messageReplies.json":
+			json.Unmarshal(raw, &replied)
+			fmt.Fprint(w, `{"STATUS": "OK", "messageReplyId": "80"}`)
+		case r.Method == http.MethodPut && r.URL.Path == "/messages/8/archive.json":
+			archived = true
+			fmt.Fprint(w, `{"STATUS": "OK"}`)
+		case r.Method == http.MethodPut && r.URL.Path == "/messages/8/unarchive.json":
+			unarchived = true
+			fmt.Fprint(w, `{"STATUS": "OK"}`)
+		default:
+			fmt.Fprint(w, `{"STATUS": "Error", "MESSAGE": "not found"}`)
+		}
+	})
+
+	paths := writeTestFiles(t, "agenda.pdf")
+
+	m := &Message{Title: "Release", Body: "Notes attached", NotifyIDs: "1,2"}
+
+	res, err := conn.PostMessageWithAttachments("1", m, paths...)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if m.ID != "8" || len(res.Attached) != 1 || posted.Message.PendingFileAttachments != "tf_1" || posted.Message.NotifyIDs != "1,2" {
+		t.Errorf("unexpected message posted: %+v", posted.Message)
+	}
+
+	reply := &MessageReply{Body: "Looks good"}
+
+	id, err := conn.PostMessageReply("8", reply)
+	if err != nil || id != "80" || reply.ID != "80" || replied.Reply.Body != "Looks good" {
+		t.Errorf("unexpected reply posted (%s, %v)", id, err)
+	}
+
+	err = conn.ArchiveMessage("8")
+	if err != nil || !archived {
+		t.Errorf("expected message to be archived (%v)", err)
+	}
+
+	err = conn.UnarchiveMessage("8")
+	if err != nil || !unarchived {
+		t.Errorf("expected message to be unarchived (%v)", err)
+	}
+
+	_, err = conn.PostMessage("1", &Message{})
+	if err == nil || err.Error() != "message is missing required field(s): Title, Body" {
+		t.Errorf("expected missing field error but got (%v)", err)
+	}
+
+	_, err = conn.PostMessageReply("8", &MessageReply{Body: " "})
+	if err == nil || !strings.HasSuffix(err.Error(), "Body") {
+		t.Errorf("expected missing body error but got (%v)", err)
+	}
+}
+
+func TestPostMessageAttachmentsValidation(t *testing.T) {
+
+	conn := initMockConnection(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request (%s %s) before validation", r.Method, r.URL.Path)
+	})
+
+	paths := writeTestFiles(t, "agenda.pdf")
+
+	var tests = []struct {
+		post func() error
+		want string
+	}{
+		{func() error {
+			_, err := conn.PostMessageWithAttachments("", &Message{Title: "Release", Body: "Notes"}, paths...)
+			return err
+		}, "missing required parameter(s): projectID"},
+		{func() error {
+			_, err := conn.PostMessageWithAttachments("1", &Message{Title: "Release"}, paths...)
+			return err
+		}, "message is missing required field(s): Body"},
+		{func() error {
+			_, err := conn.PostMessageReplyWithAttachments("abc", &MessageReply{Body: "Looks good"}, paths...)
+			return err
+		}, "invalid value (abc) for messageID"},
+		{func() error {
+			_, err := conn.PostMessageReplyWithAttachments("8", nil, paths...)
+			return err
+		}, "message reply is missing required field(s): Body"},
+	}
+
+	for _, v := range tests {
+		err := v.post()
+		if err == nil || err.Error() != v.want {
+			t.Errorf("expected error (%s) but got (%v)", v.want, err)
+		}
+	}
+}
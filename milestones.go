@@ -0,0 +1,61 @@
+package teamworkapi
+
+import (
+	"encoding/json"
+
+	"github.com/google/go-querystring/query"
+)
+
+// Milestone models a Teamwork milestone.
+type Milestone struct {
+	ID                  string `json:"id"`
+	Title               string `json:"title"`
+	Description         string `json:"description"`
+	Deadline            string `json:"deadline"` // expected format is YYYYMMDD
+	Completed           bool   `json:"completed"`
+	ProjectID           string `json:"project-id"`
+	ProjectName         string `json:"project-name"`
+	ResponsiblePartyIDs string `json:"responsible-party-ids"`
+}
+
+// MilestonesJSON models the parent JSON structure of an array of Milestones and
+// facilitates unmarshalling.
+type MilestonesJSON struct {
+	Milestones []*Milestone `json:"milestones"`
+}
+
+// MilestoneQueryParams defines valid query parameters for this resource.  Find
+// is one of "all", "completed", "incomplete", "late" or "upcoming".
+type MilestoneQueryParams struct {
+	Find string `url:"find,omitempty"`
+}
+
+// FormatQueryParams formats query parameters for this resource.
+func (qp MilestoneQueryParams) FormatQueryParams() (string, error) {
+
+	params, err := query.Values(qp)
+	if err != nil {
+		return "", err
+	}
+
+	return params.Encode(), nil
+}
+
+// GetMilestones retrieves milestones across all projects based on query
+// parameters.
+func (conn *Connection) GetMilestones(queryParams MilestoneQueryParams) ([]*Milestone, error) {
+
+	data, err := conn.GetRequest("milestones", queryParams)
+	if err != nil {
+		return nil, err
+	}
+
+	milestones := new(MilestonesJSON)
+
+	err = json.Unmarshal(data, &milestones)
+	if err != nil {
+		return nil, err
+	}
+
+	return milestones.Milestones, nil
+}
@@ -0,0 +1,28 @@
+package teamworkapi
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestGetMilestones(t *testing.T) {
+
+	conn := initMockConnection(t, func(w http.ResponseWriter, r *http.Request) {
+
+		if r.URL.Path != "/milestones.json" || r.URL.Query().Get("find") != "upcoming" {
+			t.Errorf("unexpected request (%s)", r.URL.String())
+		}
+
+		fmt.Fprint(w, `{"STATUS": "OK", "milestones": [{"id": "10", "title": "Release", "deadline": "20210331", "completed": false}]}`)
+	})
+
+	milestones, err := conn.GetMilestones(MilestoneQueryParams{Find: "upcoming"})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if len(milestones) != 1 || milestones[0].Deadline != "20210331" {
+		t.Errorf("expected 1 milestone due 20210331 but got %d", len(milestones))
+	}
+}
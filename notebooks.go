@@ -0,0 +1,414 @@
+package teamworkapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Notebook content types.
+const (
+	NotebookTypeMarkdown = "MARKDOWN"
+	NotebookTypeHTML     = "HTML"
+)
+
+// Notebook models a Teamwork notebook.  Type is one of NotebookTypeMarkdown or
+// NotebookTypeHTML.
+type Notebook struct {
+	ID           string `json:"id,omitempty"`
+	Name         string `json:"name"`
+	Description  string `json:"description,omitempty"`
+	Content      string `json:"content"`
+	Type         string `json:"notebook-type,omitempty"`
+	CategoryID   string `json:"category-id,omitempty"`
+	CategoryName string `json:"category-name,omitempty"`
+	ProjectID    string `json:"project-id,omitempty"`
+	Locked       bool   `json:"locked,omitempty"`
+	Version      string `json:"version,omitempty"`
+	UpdatedDate  string `json:"last-changed-on,omitempty"`
+}
+
+// NotebookVersion models a single version of a Teamwork notebook.
+type NotebookVersion struct {
+	ID              string `json:"id"`
+	Version         string `json:"version"`
+	Content         string `json:"content"`
+	UpdatedByUserID string `json:"updated-by-user-id"`
+	UpdatedDate     string `json:"updated-date"`
+}
+
+// NotebookJSON models the parent JSON structure of a single Notebook and
+// facilitates (un)marshalling.
+type NotebookJSON struct {
+	Notebook *Notebook `json:"notebook"`
+}
+
+// ProjectNotebooksJSON models the parent JSON structure of the Notebooks of a
+// project and facilitates unmarshalling.
+type ProjectNotebooksJSON struct {
+	Project struct {
+		Notebooks []*Notebook `json:"notebooks"`
+	} `json:"project"`
+}
+
+// NotebookVersionsJSON models the parent JSON structure of an array of
+// NotebookVersions and facilitates unmarshalling.
+type NotebookVersionsJSON struct {
+	Versions []*NotebookVersion `json:"versions"`
+}
+
+// NotebookResponseHandler models a http response for a Notebook operation.
+type NotebookResponseHandler struct {
+	Status  string `json:"STATUS"`
+	Message string `json:"MESSAGE"`
+	ID      string `json:"id"`
+}
+
+// ParseResponse interprets the http response for a Notebook operation.
+func (resMsg *NotebookResponseHandler) ParseResponse(httpMethod string, rawRes []byte) error {
+
+	err := json.Unmarshal(rawRes, &resMsg)
+	if err != nil {
+		return err
+	}
+
+	if resMsg.Status == "Error" {
+		return fmt.Errorf("received ERROR response: %s", resMsg.Message)
+	}
+
+	switch httpMethod {
+	case http.MethodPost:
+		if resMsg.ID == "" {
+			return fmt.Errorf("no ID returned for Notebook POST request")
+		}
+	}
+
+	return nil
+}
+
+// NotebookSyncOptions configures SyncNotebooks.  New notebooks are created in
+// CategoryID.  If DryRun is true, the result is reported without making
+// changes.
+type NotebookSyncOptions struct {
+	CategoryID string
+	DryRun     bool
+}
+
+// NotebookSyncFailure records a notebook that could not be published.
+type NotebookSyncFailure struct {
+	Title string
+	Err   error
+}
+
+// NotebookSyncResult reports the titles of the notebooks created, updated and
+// left unchanged by SyncNotebooks, and those that failed.
+type NotebookSyncResult struct {
+	Created   []string
+	Updated   []string
+	Unchanged []string
+	Failed    []*NotebookSyncFailure
+}
+
+// GetNotebooks retrieves the notebooks of a project.
+func (conn *Connection) GetNotebooks(projectID string) ([]*Notebook, error) {
+
+	err := validateID("projectID", projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := conn.GetRequest("projects/"+projectID+"/notebooks", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	notebooks := new(ProjectNotebooksJSON)
+
+	err = json.Unmarshal(data, &notebooks)
+	if err != nil {
+		return nil, err
+	}
+
+	return notebooks.Project.Notebooks, nil
+}
+
+// GetNotebookByID retrieves a specific notebook, including its content.
+func (conn *Connection) GetNotebookByID(notebookID string) (*Notebook, error) {
+
+	err := validateID("notebookID", notebookID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := conn.GetRequest("notebooks/"+notebookID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	n := new(NotebookJSON)
+
+	err = json.Unmarshal(data, &n)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.Notebook == nil {
+		return nil, fmt.Errorf("failed to retrieve notebook with ID (%s)", notebookID)
+	}
+
+	return n.Notebook, nil
+}
+
+// GetNotebookVersions retrieves the version history of a notebook.
+func (conn *Connection) GetNotebookVersions(notebookID string) ([]*NotebookVersion, error) {
+
+	err := validateID("notebookID", notebookID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := conn.GetRequest("notebooks/"+notebookID+"/versions", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := new(NotebookVersionsJSON)
+
+	err = json.Unmarshal(data, &versions)
+	if err != nil {
+		return nil, err
+	}
+
+	return versions.Versions, nil
+}
+
+// PostNotebook creates a notebook in a project.  The ID of the new notebook is
+// returned and stored in notebook.
+func (conn *Connection) PostNotebook(projectID string, notebook *Notebook) (string, error) {
+
+	err := validateID("projectID", projectID)
+	if err != nil {
+		return "", err
+	}
+
+	err = validateNotebook(notebook)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(NotebookJSON{Notebook: notebook})
+	if err != nil {
+		return "", err
+	}
+
+	handler := new(NotebookResponseHandler)
+
+	err = conn.PostRequest("projects/"+projectID+"/notebooks", data, handler)
+	if err != nil {
+		return "", err
+	}
+
+	notebook.ID = handler.ID
+
+	return handler.ID, nil
+}
+
+// PutNotebook updates the notebook identified by notebook.ID.
+func (conn *Connection) PutNotebook(notebook *Notebook) error {
+
+	if notebook == nil || notebook.ID == "" {
+		return fmt.Errorf("missing required parameter(s): ID")
+	}
+
+	err := validateNotebook(notebook)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(NotebookJSON{Notebook: notebook})
+	if err != nil {
+		return err
+	}
+
+	return conn.PutRequest("notebooks/"+notebook.ID, data, new(NotebookResponseHandler))
+}
+
+// DeleteNotebook deletes a notebook with the specified ID.
+func (conn *Connection) DeleteNotebook(notebookID string) error {
+
+	err := validateID("notebookID", notebookID)
+	if err != nil {
+		return err
+	}
+
+	return conn.DeleteRequest("notebooks/"+notebookID, new(NotebookResponseHandler))
+}
+
+// LockNotebook locks a notebook so that only its owner can edit it.
+func (conn *Connection) LockNotebook(notebookID string) error {
+
+	err := validateID("notebookID", notebookID)
+	if err != nil {
+		return err
+	}
+
+	return conn.PutRequest("notebooks/"+notebookID+"/lock", nil, new(NotebookResponseHandler))
+}
+
+// UnlockNotebook unlocks a notebook.
+func (conn *Connection) UnlockNotebook(notebookID string) error {
+
+	err := validateID("notebookID", notebookID)
+	if err != nil {
+		return err
+	}
+
+	return conn.PutRequest("notebooks/"+notebookID+"/unlock", nil, new(NotebookResponseHandler))
+}
+
+// NotebookFromMarkdown creates a markdown notebook from content.  The title is
+// the first level one heading of content or, if there is none, fallback.
+func NotebookFromMarkdown(content string, fallback string) *Notebook {
+
+	title := fallback
+
+	for _, l := range strings.Split(content, "\n") {
+		l = strings.TrimSpace(l)
+		if strings.HasPrefix(l, "# ") {
+			title = strings.TrimSpace(strings.TrimPrefix(l, "# "))
+			break
+		}
+	}
+
+	return &Notebook{
+		Name:    title,
+		Content: content,
+		Type:    NotebookTypeMarkdown,
+	}
+}
+
+// SyncNotebooks publishes the markdown (.md) files in dir to the notebooks of
+// a project, matching existing notebooks by title.  Notebooks are created if
+// missing and updated if their content differs.  Notebooks not found in dir
+// are left alone.
+func (conn *Connection) SyncNotebooks(projectID string, dir string, opts NotebookSyncOptions) (*NotebookSyncResult, error) {
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.md"))
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(paths)
+
+	existing, err := conn.GetNotebooks(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	byTitle := make(map[string]*Notebook)
+	for _, n := range existing {
+		byTitle[strings.ToLower(strings.TrimSpace(n.Name))] = n
+	}
+
+	res := new(NotebookSyncResult)
+
+	for _, p := range paths {
+
+		raw, err := ioutil.ReadFile(p)
+		if err != nil {
+			res.Failed = append(res.Failed, &NotebookSyncFailure{Title: filepath.Base(p), Err: err})
+			continue
+		}
+
+		local := NotebookFromMarkdown(string(raw), strings.TrimSuffix(filepath.Base(p), filepath.Ext(p)))
+
+		key := strings.ToLower(strings.TrimSpace(local.Name))
+
+		remote, ok := byTitle[key]
+		if !ok {
+
+			local.CategoryID = opts.CategoryID
+
+			if !opts.DryRun {
+				_, err = conn.PostNotebook(projectID, local)
+				if err != nil {
+					res.Failed = append(res.Failed, &NotebookSyncFailure{Title: local.Name, Err: err})
+					continue
+				}
+			}
+
+			// later files with the same title update this notebook
+			byTitle[key] = local
+
+			res.Created = append(res.Created, local.Name)
+			continue
+		}
+
+		// the list of notebooks may not include content
+		if remote.Content == "" {
+			remote, err = conn.GetNotebookByID(remote.ID)
+			if err != nil {
+				res.Failed = append(res.Failed, &NotebookSyncFailure{Title: local.Name, Err: err})
+				continue
+			}
+		}
+
+		if strings.TrimSpace(remote.Content) == strings.TrimSpace(local.Content) {
+			res.Unchanged = append(res.Unchanged, local.Name)
+			continue
+		}
+
+		if !opts.DryRun {
+
+			update := *remote
+			update.Content = local.Content
+			update.Type = NotebookTypeMarkdown
+
+			err = conn.PutNotebook(&update)
+			if err != nil {
+				res.Failed = append(res.Failed, &NotebookSyncFailure{Title: local.Name, Err: err})
+				continue
+			}
+		}
+
+		res.Updated = append(res.Updated, local.Name)
+	}
+
+	return res, nil
+}
+
+func validateNotebook(notebook *Notebook) error {
+
+	if notebook == nil {
+		return fmt.Errorf("missing required parameter(s): notebook")
+	}
+
+	errBuff := ""
+
+	if strings.TrimSpace(notebook.Name) == "" {
+		errBuff += "Name"
+	}
+
+	if notebook.Content == "" {
+		if errBuff != "" {
+			errBuff += ", "
+		}
+		errBuff += "Content"
+	}
+
+	if errBuff != "" {
+		return fmt.Errorf("notebook is missing required field(s): %s", errBuff)
+	}
+
+	switch notebook.Type {
+	case "", NotebookTypeMarkdown, NotebookTypeHTML:
+	default:
+		return fmt.Errorf("invalid notebook type (%s)", notebook.Type)
+	}
+
+	return nil
+}
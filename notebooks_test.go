@@ -0,0 +1,184 @@
+package teamworkapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNotebookFromMarkdown(t *testing.T) {
+
+	var tests = []struct {
+		content string
+		want    string
+	}{
+		{"intro\n\n# Deploy Runbook\n\nsteps", "Deploy Runbook"},
+		{"## Not a title\nbody", "fallback"},
+		{"", "fallback"},
+	}
+
+	for _, v := range tests {
+
+		n := NotebookFromMarkdown(v.content, "fallback")
+
+		if n.Name != v.want || n.Type != NotebookTypeMarkdown || n.Content != v.content {
+			t.Errorf("expected notebook titled (%s) but got (%s)", v.want, n.Name)
+		}
+	}
+
+	var tests2 = []struct {
+		notebook *Notebook
+		want     string
+	}{
+		{nil, "missing required parameter(s): notebook"},
+		{&Notebook{}, "notebook is missing required field(s): Name, Content"},
+		{&Notebook{Name: "a", Content: "b", Type: "PDF"}, "invalid notebook type (PDF)"},
+	}
+
+	for _, v := range tests2 {
+		err := validateNotebook(v.notebook)
+		if err == nil || err.Error() != v.want {
+			t.Errorf("expected error (%s) but got (%v)", v.want, err)
+		}
+	}
+}
+
+func TestNotebooks(t *testing.T) {
+
+	var calls []string
+
+	conn := initMockConnection(t, func(w http.ResponseWriter, r *http.Request) {
+
+		calls = append(calls, r.Method+" "+r.URL.Path)
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/notebooks/5.json":
+			fmt.Fprint(w, `{"STATUS": "OK", "notebook": {"id": "5", "name": "Runbook", "content": "# Runbook", "locked": true}}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/notebooks/5/versions.json":
+			fmt.Fprint(w, `{"STATUS": "OK", "versions": [{"id": "1", "version": "1"}, {"id": "2", "version": "2"}]}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/projects/1/notebooks.json":
+			fmt.Fprint(w, `{"STATUS": "OK", "id": "6"}`)
+		default:
+			fmt.Fprint(w, `{"STATUS": "OK"}`)
+		}
+	})
+
+	n, err := conn.GetNotebookByID("5")
+	if err != nil || !n.Locked || n.Content != "# Runbook" {
+		t.Errorf("unexpected notebook (%v, %v)", n, err)
+	}
+
+	versions, err := conn.GetNotebookVersions("5")
+	if err != nil || len(versions) != 2 {
+		t.Errorf("expected 2 versions but got %d (%v)", len(versions), err)
+	}
+
+	nb := &Notebook{Name: "New", Content: "<p>hi</p>", Type: NotebookTypeHTML}
+
+	id, err := conn.PostNotebook("1", nb)
+	if err != nil || id != "6" || nb.ID != "6" {
+		t.Errorf("expected notebook (6) but got (%s, %v)", id, err)
+	}
+
+	for _, f := range []func() error{
+		func() error { return conn.PutNotebook(nb) },
+		func() error { return conn.LockNotebook("6") },
+		func() error { return conn.UnlockNotebook("6") },
+		func() error { return conn.DeleteNotebook("6") },
+	} {
+		err = f()
+		if err != nil {
+			t.Errorf(err.Error())
+		}
+	}
+
+	want := "GET /notebooks/5.json,GET /notebooks/5/versions.json,POST /projects/1/notebooks.json," +
+		"PUT /notebooks/6.json,PUT /notebooks/6/lock.json,PUT /notebooks/6/unlock.json,DELETE /notebooks/6.json"
+
+	if strings.Join(calls, ",") != want {
+		t.Errorf("expected calls (%s) but got (%s)", want, strings.Join(calls, ","))
+	}
+}
+
+func TestSyncNotebooks(t *testing.T) {
+
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"deploy.md":  "# Deploy\n\nnew steps\n",
+		"restore.md": "# Restore\n\nsame steps\n",
+		"oncall.md":  "no heading",
+		"rota.md":    "# Oncall\n\nweekly rota\n",
+		"notes.txt":  "ignored",
+	}
+
+	for name, content := range files {
+		err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+	}
+
+	var posted, put []*Notebook
+
+	conn := initMockConnection(t, func(w http.ResponseWriter, r *http.Request) {
+
+		raw, _ := ioutil.ReadAll(r.Body)
+		n := new(NotebookJSON)
+		json.Unmarshal(raw, &n)
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/projects/1/notebooks.json":
+			fmt.Fprint(w, `{"STATUS": "OK", "project": {"notebooks": [{"id": "1", "name": "deploy", "content": "# Deploy\n\nold steps"}, {"id": "2", "name": "Restore"}]}}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/notebooks/2.json":
+			fmt.Fprint(w, `{"STATUS": "OK", "notebook": {"id": "2", "name": "Restore", "content": "# Restore\n\nsame steps"}}`)
+		case r.Method == http.MethodPost:
+			posted = append(posted, n.Notebook)
+			fmt.Fprint(w, `{"STATUS": "OK", "id": "3"}`)
+		case r.Method == http.MethodPut:
+			put = append(put, n.Notebook)
+			fmt.Fprint(w, `{"STATUS": "OK"}`)
+		}
+	})
+
+	res, err := conn.SyncNotebooks("1", dir, NotebookSyncOptions{CategoryID: "9", DryRun: true})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if len(posted) != 0 || len(put) != 0 {
+		t.Errorf("expected no changes in a dry run")
+	}
+
+	res, err = conn.SyncNotebooks("1", dir, NotebookSyncOptions{CategoryID: "9"})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	var tests = []struct {
+		got  []string
+		want string
+	}{
+		{res.Created, "oncall"},
+		{res.Updated, "Deploy,Oncall"},
+		{res.Unchanged, "Restore"},
+	}
+
+	for _, v := range tests {
+		if strings.Join(v.got, ",") != v.want {
+			t.Errorf("expected (%s) but got (%s)", v.want, strings.Join(v.got, ","))
+		}
+	}
+
+	if len(posted) != 1 || posted[0].CategoryID != "9" || posted[0].Content != "no heading" {
+		t.Errorf("unexpected notebooks posted: %+v", posted)
+	}
+
+	if len(put) != 2 || put[0].Name != "deploy" || put[0].Content != files["deploy.md"] || put[1].ID != "3" || put[1].Content != files["rota.md"] {
+		t.Errorf("unexpected notebooks updated: %+v", put)
+	}
+}
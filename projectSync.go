@@ -0,0 +1,656 @@
+package teamworkapi
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sync actions.
+const (
+	SyncCreate    = "create"
+	SyncUpdate    = "update"
+	SyncDelete    = "delete"
+	SyncUnchanged = "unchanged"
+)
+
+// Sync resources.
+const (
+	SyncTaskList = "tasklist"
+	SyncTask     = "task"
+)
+
+// ProjectState models the desired task lists and tasks of a project, defined
+// in YAML or JSON.  Only the task lists named in the state are managed.
+type ProjectState struct {
+	TaskLists []*TaskListState `json:"tasklists" yaml:"tasklists"`
+}
+
+// TaskListState models the desired tasks of a task list.
+type TaskListState struct {
+	Name  string       `json:"name" yaml:"name"`
+	Tasks []*TaskState `json:"tasks" yaml:"tasks"`
+}
+
+// TaskState models a desired task, and its subtasks.
+//
+// Key identifies the task across syncs and must be unique in the state.  It
+// is stored in the description of the task as a marker.  Fields left empty
+// are not managed, except Description.  StartAt and DueAt are expected in
+// format YYYY-MM-DD, Assignees are email addresses and Tags the names of
+// existing tags.
+type TaskState struct {
+	Key              string       `json:"key" yaml:"key"`
+	Name             string       `json:"name" yaml:"name"`
+	Description      string       `json:"description" yaml:"description"`
+	EstimatedMinutes int          `json:"estimatedMinutes" yaml:"estimatedMinutes"`
+	StartAt          string       `json:"startAt" yaml:"startAt"`
+	DueAt            string       `json:"dueAt" yaml:"dueAt"`
+	Assignees        []string     `json:"assignees" yaml:"assignees"`
+	Tags             []string     `json:"tags" yaml:"tags"`
+	Subtasks         []*TaskState `json:"subtasks" yaml:"subtasks"`
+}
+
+// SyncOptions configures SyncProject.  If PlanOnly is true, the plan is
+// returned without being applied.
+type SyncOptions struct {
+	PlanOnly bool
+}
+
+// SyncChange models a change to a task list or task.  ID is the ID of the
+// existing resource, or of the created resource once applied.  Fields lists
+// the fields changed by an update.
+type SyncChange struct {
+	Resource string
+	Action   string
+	TaskList string
+	Key      string
+	Name     string
+	ID       string
+	Fields   []string
+
+	list   *SyncChange
+	parent *SyncChange
+	create TaskV3JSON
+	update *TaskItem
+}
+
+// SyncPlan models the changes needed to bring a project to a desired state,
+// in the order they are applied.
+type SyncPlan struct {
+	ProjectID string
+	Changes   []*SyncChange
+}
+
+// Count returns the number of changes of a resource with the specified action.
+func (p *SyncPlan) Count(resource string, action string) int {
+
+	n := 0
+
+	for _, c := range p.Changes {
+		if c.Resource == resource && c.Action == action {
+			n++
+		}
+	}
+
+	return n
+}
+
+var syncMarkerPattern = regexp.MustCompile(`\[sync:([^\]]+)\]`)
+
+// syncMarker returns the marker identifying the task in its description.
+func syncMarker(key string) string {
+	return "[sync:" + key + "]"
+}
+
+// ReadProjectState parses a YAML or JSON project state.
+func ReadProjectState(r io.Reader) (*ProjectState, error) {
+
+	state := new(ProjectState)
+
+	err := decodeDefinition(r, state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse project state: %s", err)
+	}
+
+	return state, nil
+}
+
+// ReadProjectStateFile parses the YAML or JSON project state at path.
+func ReadProjectStateFile(path string) (*ProjectState, error) {
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ReadProjectState(f)
+}
+
+// SyncProject brings the task lists and tasks of a project to the desired
+// state.  Tasks are matched by key within their task list or parent task;
+// tasks with a key that are no longer desired are deleted, and tasks without
+// one are left alone.  The plan is returned, along with the error of the
+// change that failed, if any.
+func (conn *Connection) SyncProject(projectID string, state *ProjectState, opts SyncOptions) (*SyncPlan, error) {
+
+	plan, err := conn.PlanProjectSync(projectID, state)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.PlanOnly {
+		return plan, nil
+	}
+
+	return plan, conn.ApplySyncPlan(plan)
+}
+
+// PlanProjectSync computes the changes needed to bring a project to the
+// desired state, without making them.  Every page of tasks is examined, and
+// the subtasks of existing tasks are retrieved with API v3.
+func (conn *Connection) PlanProjectSync(projectID string, state *ProjectState) (*SyncPlan, error) {
+
+	err := validateID("projectID", projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateProjectState(state)
+	if err != nil {
+		return nil, err
+	}
+
+	v1 := conn.v1()
+
+	lists, err := v1.GetTaskLists(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := v1.GetAllTasks(TaskQueryParams{ProjectIDs: projectID, IncludeCompleted: true})
+	if err != nil {
+		return nil, err
+	}
+
+	// top level tasks with a key, by task list.  Subtasks are retrieved by
+	// parent when planned.
+	byList := make(map[string][]*Task)
+
+	for _, t := range tasks {
+
+		if !syncMarkerPattern.MatchString(t.Description) || (t.ParentTaskID != "" && t.ParentTaskID != "0") {
+			continue
+		}
+
+		listID := strconv.Itoa(t.TaskListID)
+		byList[listID] = append(byList[listID], t)
+	}
+
+	p := &syncPlanner{
+		plan:     &SyncPlan{ProjectID: projectID},
+		resolver: &templateResolver{conn: v1},
+		conn:     conn.v3(),
+	}
+
+	for _, l := range state.TaskLists {
+
+		lc := &SyncChange{Resource: SyncTaskList, Action: SyncCreate, TaskList: l.Name, Name: l.Name}
+
+		for _, existing := range lists {
+			if strings.EqualFold(strings.TrimSpace(existing.Name), strings.TrimSpace(l.Name)) {
+				lc.Action = SyncUnchanged
+				lc.ID = existing.ID
+				break
+			}
+		}
+
+		p.plan.Changes = append(p.plan.Changes, lc)
+
+		var existing []*Task
+		if lc.ID != "" {
+			existing = byList[lc.ID]
+		}
+
+		err = p.planTasks(lc, nil, l.Tasks, existing)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return p.plan, nil
+}
+
+// ApplySyncPlan makes the changes of a plan, in order, stopping at the first
+// that fails.  The IDs of created resources are stored in the plan.  Tasks are
+// created with API v3, and task lists created and tasks updated or deleted
+// with API v1, so conn may target either version.
+func (conn *Connection) ApplySyncPlan(plan *SyncPlan) error {
+
+	if plan == nil {
+		return fmt.Errorf("missing required parameter(s): plan")
+	}
+
+	v1 := conn.v1()
+	v3 := conn.v3()
+
+	for _, c := range plan.Changes {
+
+		var err error
+
+		switch {
+		case c.Resource == SyncTaskList && c.Action == SyncCreate:
+			c.ID, err = v1.PostTaskList(plan.ProjectID, &TaskList{Name: c.Name})
+		case c.Action == SyncCreate:
+			var id int
+			if c.parent == nil {
+				id, err = v3.PostTask(c.list.ID, c.create)
+			} else {
+				id, err = v3.PostSubTask(c.parent.ID, c.create)
+			}
+			if err == nil {
+				c.ID = strconv.Itoa(id)
+			}
+		case c.Action == SyncUpdate:
+			err = v1.PutTaskItem(c.ID, c.update)
+		case c.Action == SyncDelete:
+			err = v1.DeleteTask(c.ID)
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to %s %s (%s): %s", c.Action, c.Resource, c.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// syncPlanner accumulates the changes of a plan.  conn targets API v3, and is
+// used to retrieve subtasks.
+type syncPlanner struct {
+	plan     *SyncPlan
+	resolver *templateResolver
+	conn     *Connection
+}
+
+// planTasks plans the changes to the tasks of a task list, or the subtasks of
+// parent if not nil, given the existing tasks with a key.
+func (p *syncPlanner) planTasks(list *SyncChange, parent *SyncChange, desired []*TaskState, existing []*Task) error {
+
+	byKey := make(map[string]*Task)
+	var duplicates []*Task
+
+	for _, t := range existing {
+
+		key := syncMarkerPattern.FindStringSubmatch(t.Description)[1]
+
+		if _, ok := byKey[key]; ok {
+			duplicates = append(duplicates, t)
+			continue
+		}
+
+		byKey[key] = t
+	}
+
+	for _, d := range desired {
+
+		c, err := p.planTask(list, parent, d, byKey[d.Key])
+		if err != nil {
+			return fmt.Errorf("invalid task (%s): %s", d.Key, err)
+		}
+
+		delete(byKey, d.Key)
+
+		p.plan.Changes = append(p.plan.Changes, c)
+
+		var children []*Task
+		if c.Action != SyncCreate {
+			children, err = p.subtasks(c.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = p.planTasks(list, c, d.Subtasks, children)
+		if err != nil {
+			return err
+		}
+	}
+
+	var obsolete []*Task
+
+	for _, t := range byKey {
+		obsolete = append(obsolete, t)
+	}
+
+	sort.Slice(obsolete, func(i, j int) bool {
+		return obsolete[i].ID < obsolete[j].ID
+	})
+
+	for _, t := range append(obsolete, duplicates...) {
+
+		key := syncMarkerPattern.FindStringSubmatch(t.Description)[1]
+
+		p.plan.Changes = append(p.plan.Changes, &SyncChange{Resource: SyncTask, Action: SyncDelete, TaskList: list.Name,
+			Key: key, Name: t.Title, ID: strconv.Itoa(t.ID), list: list, parent: parent})
+	}
+
+	return nil
+}
+
+// subtasks retrieves the subtasks of a task that have a key, in the form of
+// the v1 tasks they are compared with.
+func (p *syncPlanner) subtasks(parentID string) ([]*Task, error) {
+
+	res, err := p.conn.GetAllSubtasksV3(parentID, TaskQueryParamsV3{IncludeCompletedTasks: true})
+	if err != nil {
+		return nil, err
+	}
+
+	var retVal []*Task
+
+	for _, s := range res.Tasks {
+
+		if !syncMarkerPattern.MatchString(s.Description) {
+			continue
+		}
+
+		t := &Task{
+			ID:             s.Id,
+			Title:          s.Name,
+			Description:    s.Description,
+			TaskListID:     s.TaskListID,
+			ParentTaskID:   parentID,
+			Status:         s.Status,
+			EstimatedMin:   s.EstimateMinutes,
+			AssignedUserID: strings.Join(s.AssigneeIDs(), ","),
+		}
+
+		t.StartDate, err = shortDateV3(s.StartDate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start date (%s) for task (%d)", s.StartDate, s.Id)
+		}
+
+		t.DueDate, err = shortDateV3(s.DueDate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid due date (%s) for task (%d)", s.DueDate, s.Id)
+		}
+
+		for _, id := range s.TagIDs {
+			name, err := p.resolver.tagName(id)
+			if err != nil {
+				return nil, err
+			}
+			t.Tags = append(t.Tags, Tag{IDBuff: float64(id), Name: name})
+		}
+
+		retVal = append(retVal, t)
+	}
+
+	return retVal, nil
+}
+
+// planTask plans the change to a task, given the existing task with its key,
+// if any.
+func (p *syncPlanner) planTask(list *SyncChange, parent *SyncChange, d *TaskState, existing *Task) (*SyncChange, error) {
+
+	c := &SyncChange{Resource: SyncTask, TaskList: list.Name, Key: d.Key, Name: d.Name, list: list, parent: parent}
+
+	desc := syncMarker(d.Key)
+	if d.Description != "" {
+		desc = d.Description + "\n\n" + desc
+	}
+
+	var userIDs []int64
+	var tagIDs []int
+
+	for _, email := range d.Assignees {
+
+		if !strings.Contains(email, "@") {
+			return nil, fmt.Errorf("invalid email (%s) for assignee", email)
+		}
+
+		id, err := p.resolver.person(email)
+		if err != nil {
+			return nil, err
+		}
+
+		userIDs = append(userIDs, id)
+	}
+
+	for _, name := range d.Tags {
+
+		id, err := p.resolver.tag(name)
+		if err != nil {
+			return nil, err
+		}
+
+		tagIDs = append(tagIDs, id)
+	}
+
+	if existing == nil {
+
+		c.Action = SyncCreate
+
+		c.create.Task = TaskV3{
+			Name:             d.Name,
+			Description:      desc,
+			EstimatedMinutes: d.EstimatedMinutes,
+			StartAt:          d.StartAt,
+			DueAt:            d.DueAt,
+			TagIDs:           tagIDs,
+		}
+
+		if len(userIDs) > 0 {
+			c.create.Task.Assignees = map[string][]int64{"userIds": userIDs}
+		}
+
+		return c, nil
+	}
+
+	c.ID = strconv.Itoa(existing.ID)
+
+	item := &TaskItem{Content: d.Name, Description: desc, EstimatedMinutes: d.EstimatedMinutes}
+
+	if existing.Title != d.Name {
+		c.Fields = append(c.Fields, "Name")
+	}
+
+	if existing.Description != desc {
+		c.Fields = append(c.Fields, "Description")
+	}
+
+	if d.EstimatedMinutes != 0 && existing.EstimatedMin != d.EstimatedMinutes {
+		c.Fields = append(c.Fields, "EstimatedMinutes")
+	}
+
+	if d.StartAt != "" {
+		item.StartDate = shortDate(d.StartAt)
+		if existing.StartDate != item.StartDate {
+			c.Fields = append(c.Fields, "StartAt")
+		}
+	}
+
+	if d.DueAt != "" {
+		item.DueDate = shortDate(d.DueAt)
+		if existing.DueDate != item.DueDate {
+			c.Fields = append(c.Fields, "DueAt")
+		}
+	}
+
+	if len(userIDs) > 0 {
+
+		var ids []string
+		for _, id := range userIDs {
+			ids = append(ids, strconv.FormatInt(id, 10))
+		}
+
+		item.ResponsiblePartyID = strings.Join(ids, ",")
+
+		if !sameSet(splitIDs(existing.AssignedUserID), ids) {
+			c.Fields = append(c.Fields, "Assignees")
+		}
+	}
+
+	if len(d.Tags) > 0 {
+
+		var names []string
+		for _, t := range existing.Tags {
+			names = append(names, strings.ToLower(t.Name))
+		}
+
+		var want []string
+		for _, t := range d.Tags {
+			want = append(want, strings.ToLower(t))
+		}
+
+		item.Tags = strings.Join(d.Tags, ",")
+
+		if !sameSet(names, want) {
+			c.Fields = append(c.Fields, "Tags")
+		}
+	}
+
+	c.Action = SyncUnchanged
+
+	if len(c.Fields) > 0 {
+		c.Action = SyncUpdate
+		c.update = item
+	}
+
+	return c, nil
+}
+
+// shortDate converts a YYYY-MM-DD date to format YYYYMMDD.
+func shortDate(s string) string {
+
+	t, err := time.Parse(TeamworkDateFormatV3, s)
+	if err != nil {
+		return s
+	}
+
+	return t.Format(TeamworkDateFormatShort)
+}
+
+// shortDateV3 converts a date of a v3 task to format YYYYMMDD, as used by v1
+// tasks.
+func shortDateV3(s string) (string, error) {
+
+	if s == "" {
+		return "", nil
+	}
+
+	d, err := ParseTeamworkDate(s)
+	if err != nil {
+		return "", err
+	}
+
+	return d.Format(TeamworkDateFormatShort), nil
+}
+
+// sameSet reports whether a and b hold the same values, ignoring order and
+// duplicates.
+func sameSet(a []string, b []string) bool {
+
+	for _, v := range a {
+		if !containsString(b, v) {
+			return false
+		}
+	}
+
+	for _, v := range b {
+		if !containsString(a, v) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func validateProjectState(state *ProjectState) error {
+
+	if state == nil {
+		return fmt.Errorf("missing required parameter(s): state")
+	}
+
+	keys := make(map[string]bool)
+	lists := make(map[string]bool)
+
+	var validateTasks func(tasks []*TaskState) error
+
+	validateTasks = func(tasks []*TaskState) error {
+
+		for _, t := range tasks {
+
+			errBuff := ""
+
+			if strings.TrimSpace(t.Key) == "" {
+				errBuff += "Key"
+			}
+
+			if strings.TrimSpace(t.Name) == "" {
+				if errBuff != "" {
+					errBuff += ", "
+				}
+				errBuff += "Name"
+			}
+
+			if errBuff != "" {
+				return fmt.Errorf("task (%s) is missing required field(s): %s", t.Name, errBuff)
+			}
+
+			if strings.ContainsAny(t.Key, "[]") {
+				return fmt.Errorf("invalid value (%s) for Key", t.Key)
+			}
+
+			if keys[t.Key] {
+				return fmt.Errorf("duplicate task key (%s)", t.Key)
+			}
+			keys[t.Key] = true
+
+			err := validateDateParam("StartAt", t.StartAt, TeamworkDateFormatV3)
+			if err != nil {
+				return err
+			}
+
+			err = validateDateParam("DueAt", t.DueAt, TeamworkDateFormatV3)
+			if err != nil {
+				return err
+			}
+
+			err = validateTasks(t.Subtasks)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	for i, l := range state.TaskLists {
+
+		name := strings.ToLower(strings.TrimSpace(l.Name))
+
+		if name == "" {
+			return fmt.Errorf("task list %d of state is missing required field(s): Name", i+1)
+		}
+
+		if lists[name] {
+			return fmt.Errorf("duplicate task list (%s)", l.Name)
+		}
+		lists[name] = true
+
+		err := validateTasks(l.Tasks)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,179 @@
+package teamworkapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testProjectStateYAML = `
+tasklists:
+  - name: Release
+    tasks:
+      - key: tag
+        name: Tag release
+        subtasks:
+          - key: build
+            name: Build
+            dueAt: "2021-03-05"
+          - key: sign
+            name: Sign artifacts
+      - key: docs
+        name: Publish docs
+        assignees: [writer@example.com]
+        tags: [Docs]
+  - name: Hotfix
+    tasks:
+      - key: hotfix
+        name: Patch
+`
+
+func TestSyncProject(t *testing.T) {
+
+	var calls []string
+
+	// a full first page of tasks, so that the duplicate on the second page is
+	// only found by paging
+	page1 := []string{
+		`{"id": 40, "todo-list-id": 30, "content": "Tag", "description": "[sync:tag]"}`,
+		`{"id": 41, "todo-list-id": 30, "content": "Notes", "description": "[sync:notes]"}`,
+		`{"id": 42, "todo-list-id": 30, "parentTaskId": "40", "content": "Build", "description": "[sync:build]", "due-date": "20210305"}`,
+	}
+	for i := len(page1); i < 250; i++ {
+		page1 = append(page1, fmt.Sprintf(`{"id": %d, "todo-list-id": 30, "content": "Manual task", "description": ""}`, 1000+i))
+	}
+
+	conn := initMockConnection(t, func(w http.ResponseWriter, r *http.Request) {
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/projects/1/tasklists.json":
+			fmt.Fprint(w, `{"STATUS": "OK", "tasklists": [{"id": "30", "name": "release"}]}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/tasks.json" && r.URL.Query().Get("page") == "1":
+			fmt.Fprintf(w, `{"STATUS": "OK", "todo-items": [%s]}`, strings.Join(page1, ","))
+		case r.Method == http.MethodGet && r.URL.Path == "/tasks.json":
+			fmt.Fprint(w, `{"STATUS": "OK", "todo-items": [
+				{"id": 44, "todo-list-id": 30, "content": "Tag", "description": "[sync:tag]"}
+			]}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/projects/api/v3/tasks/40/subtasks.json":
+			fmt.Fprint(w, `{"tasks": [
+				{"id": 42, "tasklistId": 30, "parentTaskId": 40, "name": "Build", "description": "[sync:build]", "dueDate": "2021-03-05T00:00:00Z"},
+				{"id": 45, "tasklistId": 30, "parentTaskId": 40, "name": "Manual subtask", "description": ""}
+			]}`)
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/projects/api/v3/tasks/"):
+			fmt.Fprint(w, `{"tasks": []}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/people.json":
+			fmt.Fprint(w, `{"STATUS": "OK", "people": [{"id": "7", "user-name": "writer@example.com"}]}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/tags.json":
+			fmt.Fprint(w, `{"STATUS": "OK", "tags": [{"id": 3, "name": "docs"}]}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/projects/1/tasklists.json":
+			calls = append(calls, r.Method+" "+r.URL.Path)
+			fmt.Fprint(w, `{"STATUS": "OK", "TASKLISTID": "31"}`)
+		case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/projects/api/v3/"):
+			calls = append(calls, r.Method+" "+r.URL.Path)
+			fmt.Fprintf(w, `{"task": {"id": %d}}`, 50+len(calls))
+		default:
+			calls = append(calls, r.Method+" "+r.URL.Path)
+			fmt.Fprint(w, `{"STATUS": "OK"}`)
+		}
+	})
+
+	conn.URL += "projects/api/v3/"
+
+	state, err := ReadProjectState(strings.NewReader(testProjectStateYAML))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	plan, err := conn.SyncProject("1", state, SyncOptions{PlanOnly: true})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	var got []string
+	for _, c := range plan.Changes {
+		got = append(got, fmt.Sprintf("%s %s %s%s", c.Action, c.Resource, c.Key, c.Name)+":"+c.ID+strings.Join(c.Fields, ","))
+	}
+
+	want := []string{
+		"unchanged tasklist Release:30",
+		"update task tagTag release:40Name",
+		"unchanged task buildBuild:42",
+		"create task signSign artifacts:",
+		"create task docsPublish docs:",
+		"delete task notesNotes:41",
+		"delete task tagTag:44",
+		"create tasklist Hotfix:",
+		"create task hotfixPatch:",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected plan %v but got %v", want, got)
+	}
+
+	if len(calls) != 0 {
+		t.Errorf("expected no changes when planning but got %v", calls)
+	}
+
+	if plan.Count(SyncTask, SyncCreate) != 3 || plan.Count(SyncTask, SyncDelete) != 2 {
+		t.Errorf("unexpected plan counts")
+	}
+
+	docs := plan.Changes[4].create.Task
+	if !reflect.DeepEqual(docs.Assignees["userIds"], []int64{7}) || !reflect.DeepEqual(docs.TagIDs, []int{3}) || docs.Description != "[sync:docs]" {
+		t.Errorf("unexpected task to create: %+v", docs)
+	}
+
+	err = conn.ApplySyncPlan(plan)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	wantCalls := []string{
+		"PUT /tasks/40.json",
+		"POST /projects/api/v3/tasks/40/subtasks.json",
+		"POST /projects/api/v3/tasklists/30/tasks.json",
+		"DELETE /tasks/41.json",
+		"DELETE /tasks/44.json",
+		"POST /projects/1/tasklists.json",
+		"POST /projects/api/v3/tasklists/31/tasks.json",
+	}
+
+	if !reflect.DeepEqual(calls, wantCalls) {
+		t.Errorf("expected calls %v but got %v", wantCalls, calls)
+	}
+
+	if plan.Changes[3].ID != "52" || plan.Changes[7].ID != "31" {
+		t.Errorf("expected created IDs to be stored in the plan but got (%s, %s)", plan.Changes[3].ID, plan.Changes[7].ID)
+	}
+}
+
+func TestValidateProjectState(t *testing.T) {
+
+	var tests = []struct {
+		state string
+		want  string
+	}{
+		{"tasklists: [{tasks: []}]", "task list 1 of state is missing required field(s): Name"},
+		{"tasklists: [{name: A}, {name: a}]", "duplicate task list (a)"},
+		{"tasklists: [{name: A, tasks: [{}]}]", "task () is missing required field(s): Key, Name"},
+		{"tasklists: [{name: A, tasks: [{key: x, name: X, subtasks: [{key: x, name: Y}]}]}]", "duplicate task key (x)"},
+		{"tasklists: [{name: A, tasks: [{key: 'x]', name: X}]}]", "invalid value (x]) for Key"},
+		{"tasklists: [{name: A, tasks: [{key: x, name: X, dueAt: '20210305'}]}]", "invalid format for DueAt parameter.  Should be YYYY-MM-DD, but found 20210305"},
+	}
+
+	for _, v := range tests {
+
+		state, err := ReadProjectState(strings.NewReader(v.state))
+		if err != nil {
+			t.Errorf(err.Error())
+			continue
+		}
+
+		err = validateProjectState(state)
+		if err == nil || err.Error() != v.want {
+			t.Errorf("expected error (%s) but got (%v)", v.want, err)
+		}
+	}
+}
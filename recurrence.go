@@ -0,0 +1,375 @@
+package teamworkapi
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxRecurrenceIterations bounds the number of candidate dates examined when
+// expanding a recurrence, guarding against rules that never match.
+const maxRecurrenceIterations = 100000
+
+// RecurrenceRule is the parsed form of a CalendarEventRepeat (or RRULE).
+// Exceptions holds the dates (YYYYMMDD) of skipped occurrences.
+type RecurrenceRule struct {
+	Frequency  string
+	Interval   int
+	ByDay      []time.Weekday
+	Count      int
+	Until      time.Time
+	Exceptions map[string]bool
+}
+
+// Occurrence models a single instance of a recurring event.
+type Occurrence struct {
+	Start time.Time
+	End   time.Time
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// weekdayKey returns the weekdayNames key of a day name, e.g. "Monday".
+func weekdayKey(day string) string {
+
+	key := strings.ToLower(day)
+	if len(key) > 3 {
+		key = key[:3]
+	}
+
+	return key
+}
+
+// ParseRecurrence parses a CalendarEventRepeat.  A nil rule is returned for
+// events that do not repeat.
+func ParseRecurrence(r *CalendarEventRepeat) (*RecurrenceRule, error) {
+
+	if r == nil {
+		return nil, nil
+	}
+
+	rr := &RecurrenceRule{
+		Frequency:  strings.ToLower(r.Frequency),
+		Interval:   r.Interval,
+		Count:      r.Count,
+		Exceptions: make(map[string]bool),
+	}
+
+	switch rr.Frequency {
+	case "", "norepeat":
+		return nil, nil
+	case "daily", "weekly", "monthly", "yearly":
+	default:
+		return nil, fmt.Errorf("invalid recurrence frequency (%s)", r.Frequency)
+	}
+
+	if rr.Interval < 1 {
+		rr.Interval = 1
+	}
+
+	for _, d := range splitIDs(r.SelectedDays) {
+		wd, ok := weekdayNames[weekdayKey(d)]
+		if !ok {
+			return nil, fmt.Errorf("invalid recurrence day (%s)", d)
+		}
+
+		rr.ByDay = append(rr.ByDay, wd)
+	}
+
+	if r.EndsOn != "" {
+		until, err := ParseTeamworkDate(r.EndsOn)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recurrence end date (%s)", r.EndsOn)
+		}
+		rr.Until = until
+	}
+
+	for _, d := range r.Exceptions {
+		ex, err := ParseTeamworkDate(d)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recurrence exception (%s)", d)
+		}
+		rr.Exceptions[ex.Format(TeamworkDateFormatShort)] = true
+	}
+
+	return rr, nil
+}
+
+// ParseRRule parses an iCalendar RRULE value, e.g. "FREQ=WEEKLY;BYDAY=MO,WE".
+func ParseRRule(rule string) (*RecurrenceRule, error) {
+
+	r, err := RepeatFromRRule(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseRecurrence(r)
+}
+
+// Occurrences returns the instances of a recurring event, whose first
+// instance spans start to end, that overlap the range from to to.  COUNT is
+// applied before exceptions are removed, as described by RFC 5545.
+func (rr *RecurrenceRule) Occurrences(start time.Time, end time.Time, from time.Time, to time.Time) []Occurrence {
+
+	duration := end.Sub(start)
+
+	var retVal []Occurrence
+
+	n := 0
+	for _, s := range rr.candidates(start, to) {
+
+		if rr.Count > 0 && n >= rr.Count {
+			break
+		}
+
+		if !rr.Until.IsZero() && dateOf(s).After(dateOf(rr.Until)) {
+			break
+		}
+
+		n++
+
+		if rr.Exceptions[s.Format(TeamworkDateFormatShort)] {
+			continue
+		}
+
+		e := s.Add(duration)
+		if s.After(to) || e.Before(from) {
+			continue
+		}
+
+		retVal = append(retVal, Occurrence{Start: s, End: e})
+	}
+
+	return retVal
+}
+
+// candidates returns the start of each instance, in order, from start until
+// the first instance after to.
+func (rr *RecurrenceRule) candidates(start time.Time, to time.Time) []time.Time {
+
+	var retVal []time.Time
+
+	if rr.Frequency == "weekly" {
+
+		days := rr.ByDay
+		if len(days) == 0 {
+			days = []time.Weekday{start.Weekday()}
+		}
+
+		// weeks are counted from the week of the first instance, and start on
+		// Monday.  They are counted by calendar day rather than elapsed time so
+		// that daylight saving changes cannot shift an instance between weeks.
+		week := 0
+
+		for i := 0; i < maxRecurrenceIterations; i++ {
+
+			d := start.AddDate(0, 0, i)
+			if d.After(to) {
+				break
+			}
+
+			if i > 0 && d.Weekday() == time.Monday {
+				week++
+			}
+
+			if week%rr.Interval != 0 {
+				continue
+			}
+
+			for _, wd := range days {
+				if d.Weekday() == wd {
+					retVal = append(retVal, d)
+				}
+			}
+		}
+
+		return retVal
+	}
+
+	for i := 0; i < maxRecurrenceIterations; i++ {
+
+		var d time.Time
+
+		switch rr.Frequency {
+		case "daily":
+			d = start.AddDate(0, 0, i*rr.Interval)
+		case "monthly":
+			d = start.AddDate(0, i*rr.Interval, 0)
+		case "yearly":
+			d = start.AddDate(i*rr.Interval, 0, 0)
+		}
+
+		if d.After(to) {
+			break
+		}
+
+		// skip months (or leap days) without the day of the first instance
+		if rr.Frequency != "daily" && d.Day() != start.Day() {
+			continue
+		}
+
+		retVal = append(retVal, d)
+	}
+
+	return retVal
+}
+
+// endOfDay returns the last instant of the day of t.
+func endOfDay(t time.Time) time.Time {
+	return dateOf(t).AddDate(0, 0, 1).Add(-time.Nanosecond)
+}
+
+// ExpandCalendarEvent returns a copy of the event for each of its occurrences
+// that overlap the range from to to.  Events that do not repeat are returned
+// as-is if they overlap the range.  Copies have no Repeat.
+func ExpandCalendarEvent(e *CalendarEvent, from time.Time, to time.Time) ([]*CalendarEvent, error) {
+
+	start, err := ParseTeamworkDate(e.Start)
+	if err != nil {
+		return nil, err
+	}
+
+	end, err := ParseTeamworkDate(e.End)
+	if err != nil {
+		return nil, err
+	}
+
+	rr, err := ParseRecurrence(e.Repeat)
+	if err != nil {
+		return nil, err
+	}
+
+	if rr == nil {
+		if start.After(to) || end.Before(from) {
+			return nil, nil
+		}
+		return []*CalendarEvent{e}, nil
+	}
+
+	var retVal []*CalendarEvent
+
+	for _, o := range rr.Occurrences(start, end, from, to) {
+		c := *e
+		c.Start = o.Start.Format(TeamworkDateFormatMed)
+		c.End = o.End.Format(TeamworkDateFormatMed)
+		c.Repeat = nil
+		retVal = append(retVal, &c)
+	}
+
+	return retVal, nil
+}
+
+// ExpandCalendarEventV3 returns a copy of the event for each of its
+// occurrences that overlap the range from to to.  Events that do not repeat
+// are returned as-is if they overlap the range.  Copies have no Repeat.
+func ExpandCalendarEventV3(e *CalendarEventsV3JSON, from time.Time, to time.Time) ([]*CalendarEventsV3JSON, error) {
+
+	start, err := ParseTeamworkDate(e.StartDate)
+	if err != nil {
+		return nil, err
+	}
+
+	end, err := ParseTeamworkDate(e.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	rr, err := ParseRecurrence(e.Repeat)
+	if err != nil {
+		return nil, err
+	}
+
+	if rr == nil {
+		if start.After(to) || end.Before(from) {
+			return nil, nil
+		}
+		return []*CalendarEventsV3JSON{e}, nil
+	}
+
+	var retVal []*CalendarEventsV3JSON
+
+	for _, o := range rr.Occurrences(start, end, from, to) {
+		c := *e
+		c.StartDate = o.Start.Format(TeamworkDateTimeFormatV3)
+		c.EndDate = o.End.Format(TeamworkDateTimeFormatV3)
+		c.Repeat = nil
+		retVal = append(retVal, &c)
+	}
+
+	return retVal, nil
+}
+
+// ExpandErrors reports the calendar events that could not be expanded, keyed
+// by event ID.
+type ExpandErrors map[string]error
+
+// Error lists the failed events in order of ID.
+func (e ExpandErrors) Error() string {
+
+	ids := make([]string, 0, len(e))
+	for id := range e {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	msgs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		msgs = append(msgs, fmt.Sprintf("failed to expand calendar event (%s): %s", id, e[id]))
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+// ExpandCalendarEvents expands each event into its occurrences that overlap
+// the range from to to.  An event that cannot be expanded does not prevent
+// the others from being expanded; the occurrences found are returned along
+// with an ExpandErrors for the failed events.
+func ExpandCalendarEvents(events []*CalendarEvent, from time.Time, to time.Time) ([]*CalendarEvent, error) {
+
+	var retVal []*CalendarEvent
+	errs := make(ExpandErrors)
+
+	for _, e := range events {
+		expanded, err := ExpandCalendarEvent(e, from, to)
+		if err != nil {
+			errs[e.ID] = err
+			continue
+		}
+		retVal = append(retVal, expanded...)
+	}
+
+	if len(errs) > 0 {
+		return retVal, errs
+	}
+
+	return retVal, nil
+}
+
+// ExpandCalendarEventsV3 expands each event into its occurrences that overlap
+// the range from to to.  As with ExpandCalendarEvents, failed events are
+// reported in an ExpandErrors alongside the occurrences found.
+func ExpandCalendarEventsV3(events []*CalendarEventsV3JSON, from time.Time, to time.Time) ([]*CalendarEventsV3JSON, error) {
+
+	var retVal []*CalendarEventsV3JSON
+	errs := make(ExpandErrors)
+
+	for _, e := range events {
+		expanded, err := ExpandCalendarEventV3(e, from, to)
+		if err != nil {
+			errs[strconv.Itoa(e.ID)] = err
+			continue
+		}
+		retVal = append(retVal, expanded...)
+	}
+
+	if len(errs) > 0 {
+		return retVal, errs
+	}
+
+	return retVal, nil
+}
@@ -0,0 +1,194 @@
+package teamworkapi
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRecurrence(t *testing.T) {
+
+	var tests = []struct {
+		repeat *CalendarEventRepeat
+		want   string
+	}{
+		{&CalendarEventRepeat{Frequency: "hourly"}, "invalid recurrence frequency (hourly)"},
+		{&CalendarEventRepeat{Frequency: "weekly", SelectedDays: "Mon,Xyz"}, "invalid recurrence day (Xyz)"},
+		{&CalendarEventRepeat{Frequency: "daily", EndsOn: "2021-13"}, "invalid recurrence end date (2021-13)"},
+		{&CalendarEventRepeat{Frequency: "daily", Exceptions: []string{"bad"}}, "invalid recurrence exception (bad)"},
+	}
+
+	for _, v := range tests {
+		_, err := ParseRecurrence(v.repeat)
+		if err == nil || err.Error() != v.want {
+			t.Errorf("expected error (%s) but got (%v)", v.want, err)
+		}
+	}
+
+	rr, err := ParseRecurrence(&CalendarEventRepeat{Frequency: "noRepeat"})
+	if err != nil || rr != nil {
+		t.Errorf("expected no rule for noRepeat but got (%v, %v)", rr, err)
+	}
+
+	rr, err = ParseRRule("FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,TH")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if rr.Interval != 2 || len(rr.ByDay) != 2 || rr.ByDay[0] != time.Tuesday || rr.ByDay[1] != time.Thursday {
+		t.Errorf("unexpected rule parsed: %+v", rr)
+	}
+}
+
+func TestOccurrences(t *testing.T) {
+
+	d := func(s string) time.Time {
+		v, err := ParseTeamworkDate(s)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		return v
+	}
+
+	fmtAll := func(occ []Occurrence) string {
+		var s []string
+		for _, o := range occ {
+			s = append(s, o.Start.Format(TeamworkDateFormatShort))
+		}
+		return strings.Join(s, ",")
+	}
+
+	var tests = []struct {
+		repeat *CalendarEventRepeat
+		start  string
+		from   string
+		to     string
+		want   string
+	}{
+		// weekly standup on weekdays, with a holiday exception
+		{&CalendarEventRepeat{Frequency: "weekly", SelectedDays: "Mon,Tue,Wed,Thu,Fri", Exceptions: []string{"20210310"}},
+			"2021-03-01T09:00", "20210308", "20210314", "20210308,20210309,20210311,20210312"},
+		// every other week, without days defaulting to the start weekday
+		{&CalendarEventRepeat{Frequency: "weekly", Interval: 2},
+			"2021-03-03T09:00", "20210301", "20210331", "20210303,20210317,20210331"},
+		{&CalendarEventRepeat{Frequency: "daily", Interval: 3, Count: 3},
+			"2021-03-01T09:00", "20210301", "20210331", "20210301,20210304,20210307"},
+		// count is applied before exceptions
+		{&CalendarEventRepeat{Frequency: "daily", Count: 3, Exceptions: []string{"2021-03-02"}},
+			"2021-03-01T09:00", "20210301", "20210331", "20210301,20210303"},
+		{&CalendarEventRepeat{Frequency: "daily", EndsOn: "20210303"},
+			"2021-03-01T09:00", "20210301", "20210331", "20210301,20210302,20210303"},
+		// months without the 31st are skipped
+		{&CalendarEventRepeat{Frequency: "monthly"},
+			"2021-01-31T09:00", "20210101", "20210630", "20210131,20210331,20210531"},
+		{&CalendarEventRepeat{Frequency: "yearly"},
+			"2020-02-29T09:00", "20200101", "20241231", "20200229,20240229"},
+	}
+
+	for _, v := range tests {
+
+		rr, err := ParseRecurrence(v.repeat)
+		if err != nil {
+			t.Errorf(err.Error())
+			continue
+		}
+
+		start := d(v.start)
+
+		got := fmtAll(rr.Occurrences(start, start.Add(15*time.Minute), d(v.from), endOfDay(d(v.to))))
+		if got != v.want {
+			t.Errorf("expected occurrences (%s) but got (%s)", v.want, got)
+		}
+	}
+}
+
+func TestOccurrencesAcrossDST(t *testing.T) {
+
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("time zone data unavailable")
+	}
+
+	rr, err := ParseRecurrence(&CalendarEventRepeat{Frequency: "weekly", Interval: 2, SelectedDays: "Mon,Sun"})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// daylight saving time starts on 2021-03-14
+	start := time.Date(2021, 3, 7, 9, 0, 0, 0, loc)
+	to := time.Date(2021, 3, 31, 23, 59, 0, 0, loc)
+
+	var got []string
+	for _, o := range rr.Occurrences(start, start.Add(time.Hour), start, to) {
+		got = append(got, o.Start.Format(TeamworkDateFormatShort))
+		if o.Start.Hour() != 9 {
+			t.Errorf("expected occurrence at 09:00 but got %s", o.Start.Format(time.Kitchen))
+		}
+	}
+
+	want := "20210307,20210315,20210321,20210329"
+	if strings.Join(got, ",") != want {
+		t.Errorf("expected occurrences (%s) but got (%s)", want, strings.Join(got, ","))
+	}
+}
+
+func TestExpandCalendarEvents(t *testing.T) {
+
+	from := time.Date(2021, 3, 8, 0, 0, 0, 0, time.UTC)
+	to := endOfDay(time.Date(2021, 3, 12, 0, 0, 0, 0, time.UTC))
+
+	events := []*CalendarEvent{
+		{ID: "1", Title: "Standup", Start: "2021-03-01T09:00", End: "2021-03-01T09:15",
+			Repeat: &CalendarEventRepeat{Frequency: "weekly", SelectedDays: "Mon,Wed"}},
+		{ID: "2", Title: "Offsite", Start: "2021-03-09T00:00", End: "2021-03-09T00:00", AllDay: true},
+		{ID: "3", Title: "Past", Start: "2021-03-01T00:00", End: "2021-03-01T00:00"},
+	}
+
+	expanded, err := ExpandCalendarEvents(events, from, to)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	var got []string
+	for _, e := range expanded {
+		if e.Repeat != nil {
+			t.Errorf("expected expanded event (%s) to have no repeat", e.ID)
+		}
+		got = append(got, e.ID+"@"+e.Start+"-"+e.End)
+	}
+
+	// order follows the input events, then occurrences
+	want := "1@2021-03-08T09:00-2021-03-08T09:15,1@2021-03-10T09:00-2021-03-10T09:15,2@2021-03-09T00:00-2021-03-09T00:00"
+
+	if strings.Join(got, ",") != want {
+		t.Errorf("expected events (%s) but got (%s)", want, strings.Join(got, ","))
+	}
+
+	eventsV3 := []*CalendarEventsV3JSON{
+		{ID: 4, StartDate: "2021-03-01T00:00:00Z", EndDate: "2021-03-01T23:59:00Z",
+			Repeat: &CalendarEventRepeat{Frequency: "daily", Count: 10, Exceptions: []string{"20210309"}}},
+	}
+
+	expandedV3, err := ExpandCalendarEventsV3(eventsV3, from, to)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if len(expandedV3) != 2 || expandedV3[0].StartDate != "2021-03-08T00:00:00Z" || expandedV3[1].EndDate != "2021-03-10T23:59:00Z" {
+		t.Errorf("unexpected v3 occurrences: %d", len(expandedV3))
+	}
+
+	// a bad event is reported without preventing the others from expanding
+	expanded, err = ExpandCalendarEvents(append([]*CalendarEvent{{ID: "5", Start: "bad"}}, events...), from, to)
+	if err == nil || !strings.HasPrefix(err.Error(), "failed to expand calendar event (5)") {
+		t.Errorf("expected expand error but got (%v)", err)
+	}
+
+	if errs, ok := err.(ExpandErrors); !ok || len(errs) != 1 {
+		t.Errorf("expected a single ExpandErrors entry but got (%v)", err)
+	}
+
+	if len(expanded) != 3 {
+		t.Errorf("expected 3 occurrences despite the bad event but got %d", len(expanded))
+	}
+}
@@ -0,0 +1,194 @@
+package teamworkapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Reminder types.
+const (
+	ReminderTypeEmail        = "EMAIL"
+	ReminderTypeSMS          = "SMS"
+	ReminderTypeNotification = "NOTIFICATION"
+)
+
+// TaskReminder models a reminder sent to a person about a task at a specific
+// time.  DateTime is expected in format YYYY-MM-DDTHH:MM.
+type TaskReminder struct {
+	ID       string `json:"id,omitempty"`
+	UserID   string `json:"user-id"`
+	DateTime string `json:"datetime"`
+	Type     string `json:"type,omitempty"`
+	Note     string `json:"note,omitempty"`
+}
+
+// TaskReminderJSON models the parent JSON structure of a single TaskReminder
+// and facilitates marshalling.
+type TaskReminderJSON struct {
+	Reminder *TaskReminder `json:"reminder"`
+}
+
+// TaskRemindersJSON models the parent JSON structure of an array of
+// TaskReminders and facilitates unmarshalling.
+type TaskRemindersJSON struct {
+	Reminders []*TaskReminder `json:"reminders"`
+}
+
+// TaskReminderResponseHandler models a http response for a TaskReminder
+// operation.
+type TaskReminderResponseHandler struct {
+	Status  string `json:"STATUS"`
+	Message string `json:"MESSAGE"`
+	ID      string `json:"id"`
+}
+
+// ParseResponse interprets the http response for a TaskReminder operation.
+func (resMsg *TaskReminderResponseHandler) ParseResponse(httpMethod string, rawRes []byte) error {
+
+	err := json.Unmarshal(rawRes, &resMsg)
+	if err != nil {
+		return err
+	}
+
+	if resMsg.Status == "Error" {
+		return fmt.Errorf("received ERROR response: %s", resMsg.Message)
+	}
+
+	switch httpMethod {
+	case http.MethodPost:
+		if resMsg.ID == "" {
+			return fmt.Errorf("no ID returned for TaskReminder POST request")
+		}
+	}
+
+	return nil
+}
+
+// GetTaskReminders retrieves the reminders of a task.
+func (conn *Connection) GetTaskReminders(taskID string) ([]*TaskReminder, error) {
+
+	err := validateID("taskID", taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := conn.GetRequest("tasks/"+taskID+"/reminders", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	reminders := new(TaskRemindersJSON)
+
+	err = json.Unmarshal(data, &reminders)
+	if err != nil {
+		return nil, err
+	}
+
+	return reminders.Reminders, nil
+}
+
+// PostTaskReminder creates a reminder on a task.  The ID of the new reminder
+// is returned and stored in reminder.
+func (conn *Connection) PostTaskReminder(taskID string, reminder *TaskReminder) (string, error) {
+
+	err := validateID("taskID", taskID)
+	if err != nil {
+		return "", err
+	}
+
+	err = validateTaskReminder(reminder)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(TaskReminderJSON{Reminder: reminder})
+	if err != nil {
+		return "", err
+	}
+
+	handler := new(TaskReminderResponseHandler)
+
+	err = conn.PostRequest("tasks/"+taskID+"/reminders", data, handler)
+	if err != nil {
+		return "", err
+	}
+
+	reminder.ID = handler.ID
+
+	return handler.ID, nil
+}
+
+// PutTaskReminder updates the reminder identified by reminder.ID.
+func (conn *Connection) PutTaskReminder(reminder *TaskReminder) error {
+
+	if reminder == nil || reminder.ID == "" {
+		return fmt.Errorf("missing required parameter(s): ID")
+	}
+
+	err := validateTaskReminder(reminder)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(TaskReminderJSON{Reminder: reminder})
+	if err != nil {
+		return err
+	}
+
+	return conn.PutRequest("reminders/"+reminder.ID, data, new(TaskReminderResponseHandler))
+}
+
+// DeleteTaskReminder deletes a reminder with the specified ID.
+func (conn *Connection) DeleteTaskReminder(reminderID string) error {
+
+	err := validateID("reminderID", reminderID)
+	if err != nil {
+		return err
+	}
+
+	return conn.DeleteRequest("reminders/"+reminderID, new(TaskReminderResponseHandler))
+}
+
+func validateTaskReminder(reminder *TaskReminder) error {
+
+	if reminder == nil {
+		return fmt.Errorf("missing required parameter(s): reminder")
+	}
+
+	errBuff := ""
+
+	if reminder.UserID == "" {
+		errBuff += "UserID"
+	}
+
+	if reminder.DateTime == "" {
+		if errBuff != "" {
+			errBuff += ", "
+		}
+		errBuff += "DateTime"
+	}
+
+	if errBuff != "" {
+		return fmt.Errorf("reminder is missing required field(s): %s", errBuff)
+	}
+
+	err := validateID("UserID", reminder.UserID)
+	if err != nil {
+		return err
+	}
+
+	_, err = time.Parse(TeamworkDateFormatMed, reminder.DateTime)
+	if err != nil {
+		return fmt.Errorf("invalid format for DateTime field (%s)", reminder.DateTime)
+	}
+
+	switch reminder.Type {
+	case "", ReminderTypeEmail, ReminderTypeSMS, ReminderTypeNotification:
+	default:
+		return fmt.Errorf("invalid value (%s) for Type", reminder.Type)
+	}
+
+	return nil
+}
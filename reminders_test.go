@@ -0,0 +1,99 @@
+package teamworkapi
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestTaskReminders(t *testing.T) {
+
+	var calls, bodies []string
+
+	conn := initMockConnection(t, func(w http.ResponseWriter, r *http.Request) {
+
+		raw, _ := ioutil.ReadAll(r.Body)
+
+		switch r.Method {
+		case http.MethodGet:
+			fmt.Fprint(w, `{"STATUS": "OK", "reminders": [{"id": "8", "user-id": "2", "datetime": "2021-03-01T09:00", "type": "EMAIL"}]}`)
+		case http.MethodPost:
+			calls = append(calls, r.Method+" "+r.URL.Path)
+			bodies = append(bodies, string(raw))
+			fmt.Fprint(w, `{"STATUS": "OK", "id": "9"}`)
+		default:
+			calls = append(calls, r.Method+" "+r.URL.Path)
+			bodies = append(bodies, string(raw))
+			fmt.Fprint(w, `{"STATUS": "OK"}`)
+		}
+	})
+
+	reminders, err := conn.GetTaskReminders("5")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if len(reminders) != 1 || reminders[0].ID != "8" || reminders[0].UserID != "2" {
+		t.Errorf("unexpected reminders returned: %+v", reminders)
+	}
+
+	reminder := &TaskReminder{UserID: "2", DateTime: "2021-03-02T09:00", Type: ReminderTypeNotification, Note: "Standup"}
+
+	id, err := conn.PostTaskReminder("5", reminder)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if id != "9" || reminder.ID != "9" {
+		t.Errorf("expected reminder ID 9 but got (%s)", id)
+	}
+
+	reminder.DateTime = "2021-03-03T09:00"
+
+	err = conn.PutTaskReminder(reminder)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+
+	err = conn.DeleteTaskReminder("9")
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+
+	wantCalls := []string{"POST /tasks/5/reminders.json", "PUT /reminders/9.json", "DELETE /reminders/9.json"}
+	if !reflect.DeepEqual(calls, wantCalls) {
+		t.Errorf("expected calls %v but got %v", wantCalls, calls)
+	}
+
+	wantBody := `{"reminder":{"user-id":"2","datetime":"2021-03-02T09:00","type":"NOTIFICATION","note":"Standup"}}`
+	if len(bodies) == 0 || bodies[0] != wantBody {
+		t.Errorf("expected body (%s) but got %v", wantBody, bodies)
+	}
+}
+
+func TestValidateTaskReminder(t *testing.T) {
+
+	var tests = []struct {
+		reminder *TaskReminder
+		want     string
+	}{
+		{&TaskReminder{}, "reminder is missing required field(s): UserID, DateTime"},
+		{&TaskReminder{UserID: "abc", DateTime: "2021-03-02T09:00"}, "invalid value (abc) for UserID"},
+		{&TaskReminder{UserID: "2", DateTime: "20210302"}, "invalid format for DateTime field (20210302)"},
+		{&TaskReminder{UserID: "2", DateTime: "2021-03-02T09:00", Type: "FAX"}, "invalid value (FAX) for Type"},
+	}
+
+	for _, v := range tests {
+		err := validateTaskReminder(v.reminder)
+		if err == nil || err.Error() != v.want {
+			t.Errorf("expected error (%s) but got (%v)", v.want, err)
+		}
+	}
+
+	err := validateTaskReminder(&TaskReminder{UserID: "2", DateTime: "2021-03-02T09:00"})
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+}
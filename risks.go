@@ -0,0 +1,250 @@
+package teamworkapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Risk statuses.
+const (
+	RiskStatusOpen   = "open"
+	RiskStatusClosed = "closed"
+)
+
+// Risk probability and impact levels.
+const (
+	RiskLevelVeryLow  = 1
+	RiskLevelLow      = 2
+	RiskLevelMedium   = 3
+	RiskLevelHigh     = 4
+	RiskLevelVeryHigh = 5
+)
+
+// Risk models an entry in the risk register of a Teamwork project.
+// Probability and Impact range from RiskLevelVeryLow to RiskLevelVeryHigh.
+type Risk struct {
+	ID             string `json:"id,omitempty"`
+	ProjectID      string `json:"projectId,omitempty"`
+	Source         string `json:"source"`
+	Status         string `json:"status,omitempty"`
+	Probability    int    `json:"probability"`
+	Impact         int    `json:"impact"`
+	MitigationPlan string `json:"mitigationPlan,omitempty"`
+	OwnerID        string `json:"ownerId,omitempty"`
+	CreatedOn      string `json:"createdOn,omitempty"`
+	LastChangedOn  string `json:"lastChangedOn,omitempty"`
+}
+
+// RiskJSON models the parent JSON structure of a single Risk and facilitates
+// (un)marshalling.
+type RiskJSON struct {
+	Risk *Risk `json:"risk"`
+}
+
+// RisksJSON models the parent JSON structure of an array of Risks and
+// facilitates unmarshalling.
+type RisksJSON struct {
+	Risks []*Risk `json:"risks"`
+}
+
+// RiskResponseHandler models a http response for a Risk operation.
+type RiskResponseHandler struct {
+	Status  string `json:"STATUS"`
+	Message string `json:"MESSAGE"`
+	ID      string `json:"riskId"`
+}
+
+// ParseResponse interprets the http response for a Risk operation.
+func (resMsg *RiskResponseHandler) ParseResponse(httpMethod string, rawRes []byte) error {
+
+	err := json.Unmarshal(rawRes, &resMsg)
+	if err != nil {
+		return err
+	}
+
+	if resMsg.Status == "Error" {
+		return fmt.Errorf("received ERROR response: %s", resMsg.Message)
+	}
+
+	switch httpMethod {
+	case http.MethodPost:
+		if resMsg.ID == "" {
+			return fmt.Errorf("no ID returned for Risk POST request")
+		}
+	}
+
+	return nil
+}
+
+// Score returns the product of the probability and impact of the risk.
+func (r *Risk) Score() int {
+	return r.Probability * r.Impact
+}
+
+// GetRisks retrieves the risks of a project, optionally limited to a status.
+// Risks are sorted by descending score.
+func (conn *Connection) GetRisks(projectID string, status string) ([]*Risk, error) {
+
+	err := validateID("projectID", projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := conn.GetRequest("projects/"+projectID+"/risks", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	risks := new(RisksJSON)
+
+	err = json.Unmarshal(data, &risks)
+	if err != nil {
+		return nil, err
+	}
+
+	var retVal []*Risk
+
+	for _, r := range risks.Risks {
+		if status == "" || strings.EqualFold(r.Status, status) {
+			retVal = append(retVal, r)
+		}
+	}
+
+	sort.SliceStable(retVal, func(i, j int) bool {
+		return retVal[i].Score() > retVal[j].Score()
+	})
+
+	return retVal, nil
+}
+
+// GetRiskByID retrieves a specific risk.
+func (conn *Connection) GetRiskByID(riskID string) (*Risk, error) {
+
+	err := validateID("riskID", riskID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := conn.GetRequest("risks/"+riskID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	r := new(RiskJSON)
+
+	err = json.Unmarshal(data, &r)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Risk == nil {
+		return nil, fmt.Errorf("failed to retrieve risk with ID (%s)", riskID)
+	}
+
+	return r.Risk, nil
+}
+
+// PostRisk creates a risk in a project.  The ID of the new risk is returned
+// and stored in risk.
+func (conn *Connection) PostRisk(projectID string, risk *Risk) (string, error) {
+
+	err := validateID("projectID", projectID)
+	if err != nil {
+		return "", err
+	}
+
+	err = validateRisk(risk)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(RiskJSON{Risk: risk})
+	if err != nil {
+		return "", err
+	}
+
+	handler := new(RiskResponseHandler)
+
+	err = conn.PostRequest("projects/"+projectID+"/risks", data, handler)
+	if err != nil {
+		return "", err
+	}
+
+	risk.ID = handler.ID
+
+	return handler.ID, nil
+}
+
+// PutRisk updates the risk identified by risk.ID.
+func (conn *Connection) PutRisk(risk *Risk) error {
+
+	if risk == nil || risk.ID == "" {
+		return fmt.Errorf("missing required parameter(s): ID")
+	}
+
+	err := validateRisk(risk)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(RiskJSON{Risk: risk})
+	if err != nil {
+		return err
+	}
+
+	return conn.PutRequest("risks/"+risk.ID, data, new(RiskResponseHandler))
+}
+
+// CloseRisk sets the status of a risk to closed.
+func (conn *Connection) CloseRisk(riskID string) error {
+
+	risk, err := conn.GetRiskByID(riskID)
+	if err != nil {
+		return err
+	}
+
+	risk.Status = RiskStatusClosed
+
+	return conn.PutRisk(risk)
+}
+
+// DeleteRisk deletes a risk with the specified ID.
+func (conn *Connection) DeleteRisk(riskID string) error {
+
+	err := validateID("riskID", riskID)
+	if err != nil {
+		return err
+	}
+
+	return conn.DeleteRequest("risks/"+riskID, new(RiskResponseHandler))
+}
+
+func validateRisk(risk *Risk) error {
+
+	if risk == nil {
+		return fmt.Errorf("missing required parameter(s): risk")
+	}
+
+	if strings.TrimSpace(risk.Source) == "" {
+		return fmt.Errorf("risk is missing required field(s): Source")
+	}
+
+	if risk.Probability < RiskLevelVeryLow || risk.Probability > RiskLevelVeryHigh {
+		return fmt.Errorf("invalid value (%d) for Probability", risk.Probability)
+	}
+
+	if risk.Impact < RiskLevelVeryLow || risk.Impact > RiskLevelVeryHigh {
+		return fmt.Errorf("invalid value (%d) for Impact", risk.Impact)
+	}
+
+	switch risk.Status {
+	case "", RiskStatusOpen, RiskStatusClosed:
+	default:
+		return fmt.Errorf("invalid value (%s) for Status", risk.Status)
+	}
+
+	return nil
+}
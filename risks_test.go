@@ -0,0 +1,93 @@
+package teamworkapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestValidateRisk(t *testing.T) {
+
+	var tests = []struct {
+		risk *Risk
+		want string
+	}{
+		{nil, "missing required parameter(s): risk"},
+		{&Risk{Probability: 1, Impact: 1}, "risk is missing required field(s): Source"},
+		{&Risk{Source: "a", Probability: 0, Impact: 1}, "invalid value (0) for Probability"},
+		{&Risk{Source: "a", Probability: 1, Impact: 6}, "invalid value (6) for Impact"},
+		{&Risk{Source: "a", Probability: 1, Impact: 1, Status: "pending"}, "invalid value (pending) for Status"},
+		{&Risk{Source: "a", Probability: 1, Impact: 1, Status: RiskStatusOpen}, ""},
+	}
+
+	for _, v := range tests {
+
+		err := validateRisk(v.risk)
+
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+
+		if got != v.want {
+			t.Errorf("expected error (%s) but got (%s)", v.want, got)
+		}
+	}
+}
+
+func TestRisks(t *testing.T) {
+
+	var posted, put []*Risk
+
+	conn := initMockConnection(t, func(w http.ResponseWriter, r *http.Request) {
+
+		raw, _ := ioutil.ReadAll(r.Body)
+		risk := new(RiskJSON)
+		json.Unmarshal(raw, &risk)
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/projects/1/risks.json":
+			fmt.Fprint(w, `{"STATUS": "OK", "risks": [
+				{"id": "1", "source": "Vendor delay", "status": "open", "probability": 2, "impact": 3},
+				{"id": "2", "source": "Data loss", "status": "open", "probability": 3, "impact": 5},
+				{"id": "3", "source": "Old risk", "status": "closed", "probability": 5, "impact": 5}]}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/risks/2.json":
+			fmt.Fprint(w, `{"STATUS": "OK", "risk": {"id": "2", "source": "Data loss", "status": "open", "probability": 3, "impact": 5, "mitigationPlan": "Backups"}}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/projects/1/risks.json":
+			posted = append(posted, risk.Risk)
+			fmt.Fprint(w, `{"STATUS": "OK", "riskId": "4"}`)
+		case r.Method == http.MethodPut && r.URL.Path == "/risks/2.json":
+			put = append(put, risk.Risk)
+			fmt.Fprint(w, `{"STATUS": "OK"}`)
+		default:
+			fmt.Fprint(w, `{"STATUS": "Error", "MESSAGE": "not found"}`)
+		}
+	})
+
+	risks, err := conn.GetRisks("1", RiskStatusOpen)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if len(risks) != 2 || risks[0].ID != "2" || risks[0].Score() != 15 {
+		t.Errorf("expected 2 open risks led by risk (2) but got %d", len(risks))
+	}
+
+	r := &Risk{Source: "Key person leaves", Probability: RiskLevelLow, Impact: RiskLevelHigh, OwnerID: "7", MitigationPlan: "Cross-train"}
+
+	id, err := conn.PostRisk("1", r)
+	if err != nil || id != "4" || r.ID != "4" || posted[0].OwnerID != "7" {
+		t.Errorf("unexpected risk posted (%s, %v)", id, err)
+	}
+
+	err = conn.CloseRisk("2")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if len(put) != 1 || put[0].Status != RiskStatusClosed || put[0].MitigationPlan != "Backups" {
+		t.Errorf("expected risk to be closed with its fields kept but got %+v", put)
+	}
+}
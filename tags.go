@@ -1,6 +1,12 @@
 package teamworkapi
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
 
 // Tag models an individual tag in Teamwork.
 type Tag struct {
@@ -9,6 +15,24 @@ type Tag struct {
 	Color	string		`json:"color"`
 }
 
+// ID returns the ID of the tag, which the API reports as either a number or
+// a string.
+func (t *Tag) ID() (string, error) {
+
+	switch v := t.IDBuff.(type) {
+	case float64:
+		if v > 0 && v == math.Trunc(v) {
+			return strconv.FormatFloat(v, 'f', -1, 64), nil
+		}
+	case string:
+		if validateID("ID", v) == nil {
+			return v, nil
+		}
+	}
+
+	return "", fmt.Errorf("invalid ID (%v) for tag (%s)", t.IDBuff, t.Name)
+}
+
 // TagJSON provides a wrapper around Tag to properly marshal json
 // data when posting to API.
 type TagJSON struct {
@@ -37,4 +61,24 @@ func (conn Connection) GetTags() ([]*Tag, error) {
 	}
 
 	return raw.Tags, nil
-}
\ No newline at end of file
+}
+
+// SetTaskTags replaces the tags of a task with the tags named.  Tags that do
+// not exist are created.
+func (conn *Connection) SetTaskTags(taskID string, names []string) error {
+
+	err := validateID("taskID", taskID)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(map[string]interface{}{
+		"tags":                map[string]string{"content": strings.Join(names, ",")},
+		"replaceExistingTags": true,
+	})
+	if err != nil {
+		return err
+	}
+
+	return conn.PutRequest("tasks/"+taskID+"/tags", data, nil)
+}
@@ -1,6 +1,9 @@
 package teamworkapi
 
 import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
 	"testing"
 )
 
@@ -24,4 +27,72 @@ func TestGetTags(t *testing.T) {
 	if len(tags) < 1 {
 		t.Errorf("no tags returned")
 	}
-}
\ No newline at end of file
+}
+func TestSetTaskTags(t *testing.T) {
+
+	var body string
+
+	conn := initMockConnection(t, func(w http.ResponseWriter, r *http.Request) {
+
+		if r.Method != http.MethodPut || r.URL.Path != "/tasks/5/tags.json" {
+			t.Errorf("unexpected request (%s %s)", r.Method, r.URL.Path)
+		}
+
+		raw, _ := ioutil.ReadAll(r.Body)
+		body = string(raw)
+
+		fmt.Fprint(w, `{"STATUS": "OK"}`)
+	})
+
+	err := conn.SetTaskTags("5", []string{"Ops", "Audit"})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	want := `{"replaceExistingTags":true,"tags":{"content":"Ops,Audit"}}`
+	if body != want {
+		t.Errorf("expected body (%s) but got (%s)", want, body)
+	}
+
+	err = conn.SetTaskTags("", []string{"Ops"})
+	if err == nil || err.Error() != "missing required parameter(s): taskID" {
+		t.Errorf("expected missing taskID error but got (%v)", err)
+	}
+}
+
+func TestTagID(t *testing.T) {
+
+	var tests = []struct {
+		id   interface{}
+		want string
+		err  bool
+	}{
+		{float64(12), "12", false},
+		{float64(1234567), "1234567", false},
+		{"1234567", "1234567", false},
+		{"abc", "", true},
+		{float64(1.5), "", true},
+		{nil, "", true},
+	}
+
+	for _, v := range tests {
+
+		tag := &Tag{IDBuff: v.id, Name: "Ops"}
+
+		id, err := tag.ID()
+		if v.err {
+			if err == nil {
+				t.Errorf("expected error for ID (%v)", v.id)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf(err.Error())
+		}
+
+		if id != v.want {
+			t.Errorf("expected ID (%s) but got (%s)", v.want, id)
+		}
+	}
+}
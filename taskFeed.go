@@ -0,0 +1,176 @@
+package teamworkapi
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// TaskFeedOptions configures a task iCalendar feed.  AsEvents renders tasks as
+// all-day VEVENTs on their due date instead of VTODOs, for calendar apps that
+// do not display VTODOs.  Incomplete milestones are included unless
+// ExcludeMilestones is set.
+type TaskFeedOptions struct {
+	AsEvents          bool
+	ExcludeMilestones bool
+}
+
+// TaskFeed holds the items of a task iCalendar feed.  Tasks and milestones that
+// cannot be converted, such as those with an invalid due date, are left out of
+// Events and reported in Skipped, keyed by UID.
+type TaskFeed struct {
+	Events  []*ICalEvent
+	Skipped map[string]error
+}
+
+// ICalEventFromTask converts a task with a due date to an ICalEvent, as a VTODO
+// or, if asEvent is true, an all-day VEVENT on the due date.
+func ICalEventFromTask(siteName string, t *Task, asEvent bool) (*ICalEvent, error) {
+
+	due, err := ParseTeamworkDate(t.DueDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid due date (%s) for task (%d)", t.DueDate, t.ID)
+	}
+
+	ie := &ICalEvent{
+		Component:   ICalComponentTodo,
+		UID:         taskUID(siteName, t.ID),
+		Summary:     t.Title,
+		Description: t.Description,
+		Due:         due,
+		URL:         fmt.Sprintf("https://%s.teamwork.com/#/tasks/%d", siteName, t.ID),
+	}
+
+	if asEvent {
+		ie.Component = ICalComponentEvent
+		ie.Start = due
+		ie.End = due
+		ie.AllDay = true
+	}
+
+	return ie, nil
+}
+
+// ICalEventFromMilestone converts a milestone to an ICalEvent, as a VTODO or,
+// if asEvent is true, an all-day VEVENT on the deadline.
+func ICalEventFromMilestone(siteName string, m *Milestone, asEvent bool) (*ICalEvent, error) {
+
+	deadline, err := ParseTeamworkDate(m.Deadline)
+	if err != nil {
+		return nil, fmt.Errorf("invalid deadline (%s) for milestone (%s)", m.Deadline, m.ID)
+	}
+
+	summary := "Milestone: " + m.Title
+	if m.ProjectName != "" {
+		summary += " (" + m.ProjectName + ")"
+	}
+
+	ie := &ICalEvent{
+		Component:   ICalComponentTodo,
+		UID:         milestoneUID(siteName, m.ID),
+		Summary:     summary,
+		Description: m.Description,
+		Due:         deadline,
+		URL:         fmt.Sprintf("https://%s.teamwork.com/#/milestones/%s", siteName, m.ID),
+	}
+
+	if asEvent {
+		ie.Component = ICalComponentEvent
+		ie.Start = deadline
+		ie.End = deadline
+		ie.AllDay = true
+	}
+
+	return ie, nil
+}
+
+// BuildTaskFeed converts the open tasks with a due date, and the incomplete
+// milestones, assigned to personID to a TaskFeed.  A task or milestone that
+// cannot be converted is skipped rather than failing the whole feed.
+func BuildTaskFeed(siteName string, personID string, tasks []*Task, milestones []*Milestone, opts TaskFeedOptions) *TaskFeed {
+
+	feed := &TaskFeed{Skipped: make(map[string]error)}
+
+	for _, t := range tasks {
+
+		if t.DueDate == "" || t.Status == "completed" || !containsString(splitIDs(t.AssignedUserID), personID) {
+			continue
+		}
+
+		ie, err := ICalEventFromTask(siteName, t, opts.AsEvents)
+		if err != nil {
+			feed.Skipped[taskUID(siteName, t.ID)] = err
+			continue
+		}
+
+		feed.Events = append(feed.Events, ie)
+	}
+
+	if opts.ExcludeMilestones {
+		return feed
+	}
+
+	for _, m := range milestones {
+
+		if m.Deadline == "" || m.Completed || !containsString(splitIDs(m.ResponsiblePartyIDs), personID) {
+			continue
+		}
+
+		ie, err := ICalEventFromMilestone(siteName, m, opts.AsEvents)
+		if err != nil {
+			feed.Skipped[milestoneUID(siteName, m.ID)] = err
+			continue
+		}
+
+		feed.Events = append(feed.Events, ie)
+	}
+
+	return feed
+}
+
+// ExportTaskFeed writes the open tasks with a due date assigned to personID,
+// and their incomplete milestones, to w as an iCalendar feed.  The feed is
+// returned so that skipped tasks and milestones can be reported.
+func (conn *Connection) ExportTaskFeed(w io.Writer, personID string, opts TaskFeedOptions) (*TaskFeed, error) {
+
+	_, err := strconv.Atoi(personID)
+	if err != nil {
+		if personID == "" {
+			return nil, fmt.Errorf("missing required parameter(s): personID")
+		}
+		return nil, fmt.Errorf("invalid value (%s) for personID", personID)
+	}
+
+	tasks, err := conn.GetTasks(TaskQueryParams{
+		AssignedUserID: personID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var milestones []*Milestone
+
+	if !opts.ExcludeMilestones {
+		milestones, err = conn.GetMilestones(MilestoneQueryParams{Find: "incomplete"})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	feed := BuildTaskFeed(conn.SiteName, personID, tasks, milestones, opts)
+
+	err = WriteICalendar(w, conn.SiteName+" tasks", feed.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	return feed, nil
+}
+
+func taskUID(siteName string, taskID int) string {
+	return fmt.Sprintf("task-%d@%s.teamwork.com", taskID, siteName)
+}
+
+func milestoneUID(siteName string, milestoneID string) string {
+	return fmt.Sprintf("milestone-%s@%s.teamwork.com", milestoneID, siteName)
+}
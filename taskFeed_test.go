@@ -0,0 +1,121 @@
+package teamworkapi
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestBuildTaskFeed(t *testing.T) {
+
+	tasks := []*Task{
+		{ID: 1, Title: "Write docs", AssignedUserID: "7", DueDate: "20210315"},
+		{ID: 2, Title: "Done already", AssignedUserID: "7", DueDate: "20210316", Status: "completed"},
+		{ID: 3, Title: "No due date", AssignedUserID: "7"},
+		{ID: 4, Title: "Someone else", AssignedUserID: "8", DueDate: "20210317"},
+		{ID: 5, Title: "Shared", AssignedUserID: "8,7", DueDate: "20210318"},
+	}
+
+	milestones := []*Milestone{
+		{ID: "10", Title: "Release", Deadline: "20210331", ProjectName: "API", ResponsiblePartyIDs: "7"},
+		{ID: "11", Title: "Shipped", Deadline: "20210301", Completed: true, ResponsiblePartyIDs: "7"},
+	}
+
+	var tests = []struct {
+		opts      TaskFeedOptions
+		want      int
+		component string
+	}{
+		{TaskFeedOptions{ExcludeMilestones: true}, 2, ICalComponentTodo},
+		{TaskFeedOptions{}, 3, ICalComponentTodo},
+		{TaskFeedOptions{AsEvents: true}, 3, ICalComponentEvent},
+	}
+
+	for _, v := range tests {
+
+		feed := BuildTaskFeed("foxtrot", "7", tasks, milestones, v.opts)
+
+		if len(feed.Events) != v.want {
+			t.Errorf("expected %d feed item(s) but got %d", v.want, len(feed.Events))
+		}
+
+		if len(feed.Skipped) != 0 {
+			t.Errorf("expected no skipped items but got %d", len(feed.Skipped))
+		}
+
+		for _, e := range feed.Events {
+			if e.Component != v.component {
+				t.Errorf("expected component %s but got %s", v.component, e.Component)
+			}
+		}
+	}
+
+	// a bad date skips the item rather than failing the feed
+	tasks = append(tasks, &Task{ID: 6, AssignedUserID: "7", DueDate: "next week"})
+	milestones = append(milestones, &Milestone{ID: "12", Deadline: "soon", ResponsiblePartyIDs: "7"})
+
+	feed := BuildTaskFeed("foxtrot", "7", tasks, milestones, TaskFeedOptions{})
+
+	if len(feed.Events) != 3 {
+		t.Errorf("expected 3 feed items but got %d", len(feed.Events))
+	}
+
+	err := feed.Skipped["task-6@foxtrot.teamwork.com"]
+	if err == nil || err.Error() != "invalid due date (next week) for task (6)" {
+		t.Errorf("expected invalid due date error but got (%v)", err)
+	}
+
+	err = feed.Skipped["milestone-12@foxtrot.teamwork.com"]
+	if err == nil || err.Error() != "invalid deadline (soon) for milestone (12)" {
+		t.Errorf("expected invalid deadline error but got (%v)", err)
+	}
+}
+
+func TestExportTaskFeed(t *testing.T) {
+
+	conn := initMockConnection(t, func(w http.ResponseWriter, r *http.Request) {
+
+		switch r.URL.Path {
+		case "/tasks.json":
+			if r.URL.Query().Get("responsible-party-ids") != "7" {
+				t.Errorf("expected tasks to be filtered by person but got (%s)", r.URL.RawQuery)
+			}
+			fmt.Fprint(w, `{"STATUS": "OK", "todo-items": [{"id": 1, "content": "Write docs", "responsible-party-id": "7", "due-date": "20210315"}]}`)
+		case "/milestones.json":
+			fmt.Fprint(w, `{"STATUS": "OK", "milestones": [{"id": "10", "title": "Release", "deadline": "20210331", "responsible-party-ids": "7"}]}`)
+		default:
+			fmt.Fprint(w, `{"STATUS": "Error", "MESSAGE": "not found"}`)
+		}
+	})
+	conn.SiteName = "foxtrot"
+
+	buf := new(bytes.Buffer)
+
+	feed, err := conn.ExportTaskFeed(buf, "7", TaskFeedOptions{})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if len(feed.Events) != 2 {
+		t.Errorf("expected 2 feed items but got %d", len(feed.Events))
+	}
+
+	for _, want := range []string{
+		"BEGIN:VTODO\r\nUID:task-1@foxtrot.teamwork.com\r\n",
+		"DUE;VALUE=DATE:20210315\r\n",
+		"URL:https://foxtrot.teamwork.com/#/tasks/1\r\n",
+		"UID:milestone-10@foxtrot.teamwork.com\r\n",
+		"SUMMARY:Milestone: Release\r\n",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("expected feed to contain (%q)", want)
+		}
+	}
+
+	_, err = conn.ExportTaskFeed(buf, "", TaskFeedOptions{})
+	if err == nil || err.Error() != "missing required parameter(s): personID" {
+		t.Errorf("expected missing personID error but got (%v)", err)
+	}
+}
@@ -0,0 +1,317 @@
+package teamworkapi
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Task repeat frequencies.
+const (
+	TaskRepeatNone    = "noRepeat"
+	TaskRepeatDaily   = "daily"
+	TaskRepeatWeekly  = "weekly"
+	TaskRepeatMonthly = "monthly"
+	TaskRepeatYearly  = "yearly"
+)
+
+// Monthly repeat types, determining the day of the month a monthly task
+// repeats on.
+const (
+	TaskRepeatMonthDay = "monthDay" // the same day of the month (or DayOfMonth)
+	TaskRepeatWeekDay  = "weekDay"  // the same weekday of the same week, e.g. 2nd Tuesday
+	TaskRepeatLastDay  = "lastDay"  // the last day of the month
+)
+
+// TaskRepeatOptions models the repeat configuration of a task.
+//
+// Interval defaults to 1.  SelectedDays (e.g. "Mon", "Friday") applies to
+// weekly tasks only, and MonthlyRepeatType and DayOfMonth to monthly tasks
+// only.  In months without DayOfMonth (or the day of the first instance) the
+// last day of the month is used.  Repeats limits the number of instances,
+// including the first, and EndsAt (YYYY-MM-DD) the date of the last.
+type TaskRepeatOptions struct {
+	Frequency         string   `json:"frequency"`
+	Interval          int      `json:"interval,omitempty"`
+	SelectedDays      []string `json:"selectedDays,omitempty"`
+	MonthlyRepeatType string   `json:"monthlyRepeatType,omitempty"`
+	DayOfMonth        int      `json:"dayOfMonth,omitempty"`
+	Repeats           int      `json:"repeats,omitempty"`
+	EndsAt            string   `json:"endsAt,omitempty"`
+}
+
+// RecurringTask pairs a repeating task with its next occurrence.  Next is
+// zero if the task has stopped repeating or has no start or due date.
+type RecurringTask struct {
+	Task *TaskRes
+	Next time.Time
+}
+
+// Occurrences returns the instances, in order, of a task first due on start
+// up to and including to.
+func (o *TaskRepeatOptions) Occurrences(start time.Time, to time.Time) ([]time.Time, error) {
+
+	err := validateTaskRepeatOptions(o)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.Frequency == TaskRepeatNone {
+		if start.After(to) {
+			return nil, nil
+		}
+		return []time.Time{start}, nil
+	}
+
+	interval := o.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	var candidates []time.Time
+
+	if o.Frequency == TaskRepeatMonthly {
+		candidates = o.monthlyCandidates(start, to, interval)
+	} else {
+		rr := &RecurrenceRule{Frequency: o.Frequency, Interval: interval}
+		for _, d := range o.SelectedDays {
+			rr.ByDay = append(rr.ByDay, weekdayNames[weekdayKey(d)])
+		}
+		candidates = rr.candidates(start, to)
+	}
+
+	var until time.Time
+	if o.EndsAt != "" {
+		until, _ = time.Parse(TeamworkDateFormatV3, o.EndsAt)
+	}
+
+	var retVal []time.Time
+
+	for _, d := range candidates {
+
+		if d.Before(start) || d.After(to) {
+			continue
+		}
+
+		if o.Repeats > 0 && len(retVal) >= o.Repeats {
+			break
+		}
+
+		if !until.IsZero() && dateOf(d).After(until) {
+			break
+		}
+
+		retVal = append(retVal, d)
+	}
+
+	return retVal, nil
+}
+
+// NextOccurrence returns the first instance after the specified time of a
+// task first due on start.  A zero time is returned if the task has stopped
+// repeating.
+func (o *TaskRepeatOptions) NextOccurrence(start time.Time, after time.Time) (time.Time, error) {
+
+	interval := 1
+	if o != nil && o.Interval > 1 {
+		interval = o.Interval
+	}
+
+	// eight years covers yearly repeats of a leap day
+	horizon := after
+	if start.After(horizon) {
+		horizon = start
+	}
+	horizon = horizon.AddDate(8*interval, 1, 0)
+
+	occurrences, err := o.Occurrences(start, horizon)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	for _, d := range occurrences {
+		if d.After(after) {
+			return d, nil
+		}
+	}
+
+	return time.Time{}, nil
+}
+
+// monthlyCandidates returns the start of each monthly instance, in order, from
+// the month of start until the first instance after to.
+func (o *TaskRepeatOptions) monthlyCandidates(start time.Time, to time.Time, interval int) []time.Time {
+
+	var retVal []time.Time
+
+	first := time.Date(start.Year(), start.Month(), 1, start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+	week := (start.Day()-1)/7 + 1
+
+	for i := 0; i < maxRecurrenceIterations; i++ {
+
+		month := first.AddDate(0, i*interval, 0)
+		last := month.AddDate(0, 1, -1).Day()
+
+		var day int
+
+		switch o.MonthlyRepeatType {
+		case TaskRepeatLastDay:
+			day = last
+		case TaskRepeatWeekDay:
+			// months without a fifth weekday use the last one
+			day = 1 + (int(start.Weekday())-int(month.Weekday())+7)%7 + (week-1)*7
+			if day > last {
+				day -= 7
+			}
+		default:
+			day = start.Day()
+			if o.DayOfMonth > 0 {
+				day = o.DayOfMonth
+			}
+			if day > last {
+				day = last
+			}
+		}
+
+		d := month.AddDate(0, 0, day-1)
+		if d.After(to) {
+			break
+		}
+
+		retVal = append(retVal, d)
+	}
+
+	return retVal
+}
+
+// SetTaskRepeat sets the repeat options of a task.
+func (conn *Connection) SetTaskRepeat(taskID string, options *TaskRepeatOptions) error {
+
+	err := validateID("taskID", taskID)
+	if err != nil {
+		return err
+	}
+
+	err = validateTaskRepeatOptions(options)
+	if err != nil {
+		return err
+	}
+
+	patch := TaskPatchV3JSON{}
+	patch.Task.RepeatOptions = options
+
+	_, err = conn.PatchTask(taskID, patch)
+
+	return err
+}
+
+// ClearTaskRepeat stops a task from repeating.
+func (conn *Connection) ClearTaskRepeat(taskID string) error {
+	return conn.SetTaskRepeat(taskID, &TaskRepeatOptions{Frequency: TaskRepeatNone})
+}
+
+// GetRecurringTasks retrieves the tasks matching the query parameters that
+// repeat, along with their next occurrence after the specified time.  Every
+// page of tasks is examined.  Tasks are sorted by next occurrence; those
+// without one are last.
+func (conn *Connection) GetRecurringTasks(queryParams TaskQueryParamsV3, after time.Time) ([]*RecurringTask, error) {
+
+	res, err := conn.GetAllTasksV3(queryParams)
+	if err != nil {
+		return nil, err
+	}
+
+	var retVal []*RecurringTask
+
+	for i := range res.Tasks {
+
+		t := &res.Tasks[i]
+
+		if t.RepeatOptions == nil || t.RepeatOptions.Frequency == "" || t.RepeatOptions.Frequency == TaskRepeatNone {
+			continue
+		}
+
+		rt := &RecurringTask{Task: t}
+
+		start := t.StartDate
+		if start == "" {
+			start = t.DueDate
+		}
+
+		if start != "" {
+			s, err := ParseTeamworkDate(start)
+			if err != nil {
+				return nil, fmt.Errorf("invalid start date (%s) for task (%d)", start, t.Id)
+			}
+
+			rt.Next, err = t.RepeatOptions.NextOccurrence(s, after)
+			if err != nil {
+				return nil, fmt.Errorf("invalid repeat options for task (%d): %s", t.Id, err)
+			}
+		}
+
+		retVal = append(retVal, rt)
+	}
+
+	sort.SliceStable(retVal, func(i, j int) bool {
+		if retVal[i].Next.IsZero() || retVal[j].Next.IsZero() {
+			return !retVal[i].Next.IsZero() && retVal[j].Next.IsZero()
+		}
+		return retVal[i].Next.Before(retVal[j].Next)
+	})
+
+	return retVal, nil
+}
+
+func validateTaskRepeatOptions(o *TaskRepeatOptions) error {
+
+	if o == nil {
+		return fmt.Errorf("missing required parameter(s): options")
+	}
+
+	switch o.Frequency {
+	case "":
+		return fmt.Errorf("repeat options are missing required field(s): Frequency")
+	case TaskRepeatNone, TaskRepeatDaily, TaskRepeatWeekly, TaskRepeatMonthly, TaskRepeatYearly:
+	default:
+		return fmt.Errorf("invalid repeat frequency (%s)", o.Frequency)
+	}
+
+	if o.Interval < 0 {
+		return fmt.Errorf("invalid value (%d) for Interval", o.Interval)
+	}
+
+	if o.Repeats < 0 {
+		return fmt.Errorf("invalid value (%d) for Repeats", o.Repeats)
+	}
+
+	if len(o.SelectedDays) > 0 && o.Frequency != TaskRepeatWeekly {
+		return fmt.Errorf("SelectedDays only applies to weekly repeats")
+	}
+
+	for _, d := range o.SelectedDays {
+		if _, ok := weekdayNames[weekdayKey(d)]; !ok {
+			return fmt.Errorf("invalid repeat day (%s)", d)
+		}
+	}
+
+	if (o.MonthlyRepeatType != "" || o.DayOfMonth != 0) && o.Frequency != TaskRepeatMonthly {
+		return fmt.Errorf("MonthlyRepeatType and DayOfMonth only apply to monthly repeats")
+	}
+
+	switch o.MonthlyRepeatType {
+	case "", TaskRepeatMonthDay:
+	case TaskRepeatWeekDay, TaskRepeatLastDay:
+		if o.DayOfMonth != 0 {
+			return fmt.Errorf("DayOfMonth only applies to %s repeats", TaskRepeatMonthDay)
+		}
+	default:
+		return fmt.Errorf("invalid value (%s) for MonthlyRepeatType", o.MonthlyRepeatType)
+	}
+
+	if o.DayOfMonth < 0 || o.DayOfMonth > 31 {
+		return fmt.Errorf("invalid value (%d) for DayOfMonth", o.DayOfMonth)
+	}
+
+	return validateDateParam("EndsAt", o.EndsAt, TeamworkDateFormatV3)
+}
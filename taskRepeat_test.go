@@ -0,0 +1,181 @@
+package teamworkapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTaskRepeatOccurrences(t *testing.T) {
+
+	d := func(s string) time.Time {
+		v, err := ParseTeamworkDate(s)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		return v
+	}
+
+	var tests = []struct {
+		options *TaskRepeatOptions
+		start   string
+		to      string
+		want    string
+	}{
+		// months without the 31st use the last day of the month
+		{&TaskRepeatOptions{Frequency: TaskRepeatMonthly},
+			"20210131", "20210430", "20210131,20210228,20210331,20210430"},
+		{&TaskRepeatOptions{Frequency: TaskRepeatMonthly, DayOfMonth: 15},
+			"20210120", "20210430", "20210215,20210315,20210415"},
+		// second Tuesday of every other month
+		{&TaskRepeatOptions{Frequency: TaskRepeatMonthly, Interval: 2, MonthlyRepeatType: TaskRepeatWeekDay},
+			"20210112", "20210731", "20210112,20210309,20210511,20210713"},
+		// fifth Monday falls back to the last Monday
+		{&TaskRepeatOptions{Frequency: TaskRepeatMonthly, MonthlyRepeatType: TaskRepeatWeekDay},
+			"20210329", "20210531", "20210329,20210426,20210531"},
+		{&TaskRepeatOptions{Frequency: TaskRepeatMonthly, MonthlyRepeatType: TaskRepeatLastDay, Repeats: 3},
+			"20210110", "20211231", "20210131,20210228,20210331"},
+		{&TaskRepeatOptions{Frequency: TaskRepeatWeekly, SelectedDays: []string{"Mon", "Thursday"}, EndsAt: "2021-03-11"},
+			"20210301", "20210331", "20210301,20210304,20210308,20210311"},
+		{&TaskRepeatOptions{Frequency: TaskRepeatDaily, Interval: 2, Repeats: 3},
+			"20210301", "20210331", "20210301,20210303,20210305"},
+		{&TaskRepeatOptions{Frequency: TaskRepeatNone},
+			"20210301", "20210331", "20210301"},
+	}
+
+	for _, v := range tests {
+
+		occurrences, err := v.options.Occurrences(d(v.start), d(v.to))
+		if err != nil {
+			t.Errorf(err.Error())
+			continue
+		}
+
+		var got []string
+		for _, o := range occurrences {
+			got = append(got, o.Format(TeamworkDateFormatShort))
+		}
+
+		if strings.Join(got, ",") != v.want {
+			t.Errorf("expected occurrences (%s) but got (%s)", v.want, strings.Join(got, ","))
+		}
+	}
+
+	options := &TaskRepeatOptions{Frequency: TaskRepeatYearly}
+
+	next, err := options.NextOccurrence(d("20200229"), d("20200301"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if next.Format(TeamworkDateFormatShort) != "20240229" {
+		t.Errorf("expected next occurrence 20240229 but got (%s)", next)
+	}
+
+	options = &TaskRepeatOptions{Frequency: TaskRepeatDaily, EndsAt: "2021-03-02"}
+
+	next, err = options.NextOccurrence(d("20210301"), d("20210305"))
+	if err != nil || !next.IsZero() {
+		t.Errorf("expected no next occurrence but got (%s, %v)", next, err)
+	}
+}
+
+func TestValidateTaskRepeatOptions(t *testing.T) {
+
+	var tests = []struct {
+		options *TaskRepeatOptions
+		want    string
+	}{
+		{&TaskRepeatOptions{}, "repeat options are missing required field(s): Frequency"},
+		{&TaskRepeatOptions{Frequency: "hourly"}, "invalid repeat frequency (hourly)"},
+		{&TaskRepeatOptions{Frequency: TaskRepeatDaily, Repeats: -1}, "invalid value (-1) for Repeats"},
+		{&TaskRepeatOptions{Frequency: TaskRepeatDaily, SelectedDays: []string{"Mon"}}, "SelectedDays only applies to weekly repeats"},
+		{&TaskRepeatOptions{Frequency: TaskRepeatWeekly, SelectedDays: []string{"Xyz"}}, "invalid repeat day (Xyz)"},
+		{&TaskRepeatOptions{Frequency: TaskRepeatWeekly, DayOfMonth: 1}, "MonthlyRepeatType and DayOfMonth only apply to monthly repeats"},
+		{&TaskRepeatOptions{Frequency: TaskRepeatMonthly, MonthlyRepeatType: "firstDay"}, "invalid value (firstDay) for MonthlyRepeatType"},
+		{&TaskRepeatOptions{Frequency: TaskRepeatMonthly, MonthlyRepeatType: TaskRepeatLastDay, DayOfMonth: 3}, "DayOfMonth only applies to monthDay repeats"},
+		{&TaskRepeatOptions{Frequency: TaskRepeatMonthly, DayOfMonth: 32}, "invalid value (32) for DayOfMonth"},
+		{&TaskRepeatOptions{Frequency: TaskRepeatMonthly, EndsAt: "20211231"}, "invalid format for EndsAt parameter.  Should be YYYY-MM-DD, but found 20211231"},
+	}
+
+	for _, v := range tests {
+		err := validateTaskRepeatOptions(v.options)
+		if err == nil || err.Error() != v.want {
+			t.Errorf("expected error (%s) but got (%v)", v.want, err)
+		}
+	}
+}
+
+func TestRecurringTasks(t *testing.T) {
+
+	var body string
+
+	conn := initMockConnection(t, func(w http.ResponseWriter, r *http.Request) {
+
+		switch r.Method {
+		case http.MethodGet:
+			if r.URL.Path != "/tasks.json" {
+				t.Errorf("unexpected request for %s", r.URL.Path)
+			}
+			if r.URL.Query().Get("page") == "1" {
+				fmt.Fprint(w, `{"tasks": [
+					{"id": 1, "startDate": "2021-01-05T00:00:00Z", "repeatOptions": {"frequency": "monthly", "dayOfMonth": 5}},
+					{"id": 2, "dueDate": "2021-03-01T00:00:00Z", "repeatOptions": {"frequency": "weekly"}}
+				], "meta": {"page": {"hasMore": true}}}`)
+			} else {
+				fmt.Fprint(w, `{"tasks": [
+					{"id": 3, "dueDate": "2021-03-01T00:00:00Z", "repeatOptions": null},
+					{"id": 4, "dueDate": "2021-01-01T00:00:00Z", "repeatOptions": {"frequency": "daily", "repeats": 2}}
+				], "meta": {"page": {"hasMore": false}}}`)
+			}
+		case http.MethodPatch:
+			raw, _ := ioutil.ReadAll(r.Body)
+			body = string(raw)
+			fmt.Fprint(w, `{"task": {"id": 5}}`)
+		}
+	})
+
+	tasks, err := conn.GetRecurringTasks(TaskQueryParamsV3{}, time.Date(2021, 3, 10, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	var got []string
+	for _, rt := range tasks {
+		next := "-"
+		if !rt.Next.IsZero() {
+			next = rt.Next.Format(TeamworkDateFormatShort)
+		}
+		got = append(got, fmt.Sprintf("%d@%s", rt.Task.Id, next))
+	}
+
+	want := "2@20210315,1@20210405,4@-"
+	if strings.Join(got, ",") != want {
+		t.Errorf("expected recurring tasks (%s) but got (%s)", want, strings.Join(got, ","))
+	}
+
+	err = conn.SetTaskRepeat("5", &TaskRepeatOptions{Frequency: TaskRepeatMonthly, MonthlyRepeatType: TaskRepeatLastDay, EndsAt: "2021-12-31"})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	patch := new(TaskPatchV3JSON)
+
+	err = json.Unmarshal([]byte(body), patch)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if patch.Task.RepeatOptions == nil || patch.Task.RepeatOptions.MonthlyRepeatType != TaskRepeatLastDay {
+		t.Errorf("unexpected repeat options patched: %s", body)
+	}
+
+	_, err = conn.PostTask("3", TaskV3JSON{Task: TaskV3{Name: "Backup audit", RepeatOptions: &TaskRepeatOptions{Frequency: "hourly"}}})
+	if err == nil || err.Error() != "invalid repeat frequency (hourly)" {
+		t.Errorf("expected repeat validation error but got (%v)", err)
+	}
+}
@@ -0,0 +1,527 @@
+package teamworkapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// TaskTemplate models a reusable set of task lists and tasks, e.g. an
+// onboarding or release checklist, defined in YAML or JSON.
+//
+// Strings may reference variables as ${name}.  Variables holds their default
+// values and Roles the default email address of each assignee role.
+type TaskTemplate struct {
+	Name      string              `json:"name" yaml:"name"`
+	Variables map[string]string   `json:"variables" yaml:"variables"`
+	Roles     map[string]string   `json:"roles" yaml:"roles"`
+	TaskLists []*TaskListTemplate `json:"tasklists" yaml:"tasklists"`
+}
+
+// TaskListTemplate models a task list of a TaskTemplate.  The task list is
+// created if the project has none with the same name.
+type TaskListTemplate struct {
+	Name  string              `json:"name" yaml:"name"`
+	Tasks []*TaskTemplateItem `json:"tasks" yaml:"tasks"`
+}
+
+// TaskTemplateItem models a task, and its subtasks, of a TaskTemplate.
+//
+// Estimate is a duration such as "90m" or "1h30m".  Start and Due are relative
+// to the base date of the instantiation: a signed number of days ("+3d"),
+// weeks ("1w") or business days ("-2bd").  Assignees are roles or email
+// addresses, and Tags the names of existing tags.
+type TaskTemplateItem struct {
+	Name        string              `json:"name" yaml:"name"`
+	Description string              `json:"description" yaml:"description"`
+	Estimate    string              `json:"estimate" yaml:"estimate"`
+	Start       string              `json:"start" yaml:"start"`
+	Due         string              `json:"due" yaml:"due"`
+	Assignees   []string            `json:"assignees" yaml:"assignees"`
+	Tags        []string            `json:"tags" yaml:"tags"`
+	Subtasks    []*TaskTemplateItem `json:"subtasks" yaml:"subtasks"`
+}
+
+// TaskTemplateOptions configures InstantiateTaskTemplate.
+//
+// Variables and Roles override the defaults of the template.  Relative dates
+// are computed from BaseDate (today if zero), skipping the non-business days
+// of Calendar.  If DryRun is true, the result is reported without making
+// changes.
+type TaskTemplateOptions struct {
+	Variables map[string]string
+	Roles     map[string]string
+	BaseDate  time.Time
+	Calendar  *BusinessCalendar
+	DryRun    bool
+}
+
+// TaskTemplateTask records a task created from a template.  ParentID is 0 for
+// top-level tasks, and IDs are 0 on a dry run.
+type TaskTemplateTask struct {
+	TaskList string
+	Name     string
+	ID       int
+	ParentID int
+}
+
+// TaskTemplateResult reports the task lists used, by name, and the tasks
+// created by InstantiateTaskTemplate.
+type TaskTemplateResult struct {
+	TaskListIDs map[string]string
+	Tasks       []*TaskTemplateTask
+}
+
+// preparedTask holds a template task resolved into a request body.
+type preparedTask struct {
+	name     string
+	data     TaskV3JSON
+	subtasks []*preparedTask
+}
+
+var templateVariable = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+var relativeDate = regexp.MustCompile(`^([+-]?\d+)(d|w|bd)$`)
+
+// ReadTaskTemplate parses a YAML or JSON task template.
+func ReadTaskTemplate(r io.Reader) (*TaskTemplate, error) {
+
+	tmpl := new(TaskTemplate)
+
+	err := decodeDefinition(r, tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse task template: %s", err)
+	}
+
+	return tmpl, nil
+}
+
+// ReadTaskTemplateFile parses the YAML or JSON task template at path.
+func ReadTaskTemplateFile(path string) (*TaskTemplate, error) {
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ReadTaskTemplate(f)
+}
+
+// decodeDefinition decodes a YAML or JSON definition into v.  Unknown YAML
+// fields are rejected.
+func decodeDefinition(r io.Reader, v interface{}) error {
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return json.Unmarshal(data, v)
+	}
+
+	return yaml.UnmarshalStrict(data, v)
+}
+
+// InstantiateTaskTemplate creates the task lists and tasks of a template in a
+// project.  The whole template is resolved before any changes are made, so an
+// invalid template, unknown variable, person or tag creates nothing.  If a
+// request fails, the tasks created so far are returned with the error.
+//
+// Task lists, people and tags are managed with API v1, while tasks are created
+// with API v3, so conn may target either version.
+func (conn *Connection) InstantiateTaskTemplate(projectID string, tmpl *TaskTemplate, opts TaskTemplateOptions) (*TaskTemplateResult, error) {
+
+	err := validateID("projectID", projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if tmpl == nil {
+		return nil, fmt.Errorf("missing required parameter(s): tmpl")
+	}
+
+	vars := make(map[string]string)
+	for k, v := range tmpl.Variables {
+		vars[k] = v
+	}
+	for k, v := range opts.Variables {
+		vars[k] = v
+	}
+
+	roles := make(map[string]string)
+	for k, v := range tmpl.Roles {
+		roles[k] = v
+	}
+	for k, v := range opts.Roles {
+		roles[k] = v
+	}
+
+	base := opts.BaseDate
+	if base.IsZero() {
+		base = time.Now()
+	}
+	base = dateOf(base)
+
+	v1 := conn.v1()
+	v3 := conn.v3()
+
+	r := &templateResolver{conn: v1, vars: vars, roles: roles, base: base, calendar: opts.Calendar}
+
+	lists := make([]string, len(tmpl.TaskLists))
+	prepared := make([][]*preparedTask, len(tmpl.TaskLists))
+
+	for i, l := range tmpl.TaskLists {
+
+		lists[i], err = r.expand(l.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		if lists[i] == "" {
+			return nil, fmt.Errorf("task list %d of template is missing required field(s): Name", i+1)
+		}
+
+		prepared[i], err = r.prepareTasks(l.Tasks)
+		if err != nil {
+			return nil, fmt.Errorf("invalid task in task list (%s): %s", lists[i], err)
+		}
+	}
+
+	res := &TaskTemplateResult{TaskListIDs: make(map[string]string)}
+
+	for i, name := range lists {
+
+		listID := ""
+
+		if !opts.DryRun {
+			listID, err = v1.EnsureTaskList(projectID, name)
+			if err != nil {
+				return res, err
+			}
+		}
+
+		res.TaskListIDs[name] = listID
+
+		err = v3.postTemplateTasks(res, name, listID, 0, prepared[i], opts.DryRun)
+		if err != nil {
+			return res, err
+		}
+	}
+
+	return res, nil
+}
+
+// postTemplateTasks creates tasks in a task list, or as subtasks of parentID
+// if not 0, along with their subtasks.  conn must target API v3.
+func (conn *Connection) postTemplateTasks(res *TaskTemplateResult, listName string, listID string, parentID int, tasks []*preparedTask, dryRun bool) error {
+
+	for _, t := range tasks {
+
+		id := 0
+
+		if !dryRun {
+
+			var err error
+
+			if parentID == 0 {
+				id, err = conn.PostTask(listID, t.data)
+			} else {
+				id, err = conn.PostSubTask(strconv.Itoa(parentID), t.data)
+			}
+
+			if err != nil {
+				return fmt.Errorf("failed to create task (%s): %s", t.name, err)
+			}
+		}
+
+		res.Tasks = append(res.Tasks, &TaskTemplateTask{TaskList: listName, Name: t.name, ID: id, ParentID: parentID})
+
+		err := conn.postTemplateTasks(res, listName, listID, id, t.subtasks, dryRun)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// templateResolver resolves the variables, dates, people and tags of a
+// template.  People and tags are retrieved on first use, with conn targeting
+// API v1.
+type templateResolver struct {
+	conn     *Connection
+	vars     map[string]string
+	roles    map[string]string
+	base     time.Time
+	calendar *BusinessCalendar
+	people   map[string]int64
+	tags     map[string]int
+	tagNames map[int]string
+}
+
+func (r *templateResolver) prepareTasks(items []*TaskTemplateItem) ([]*preparedTask, error) {
+
+	var retVal []*preparedTask
+
+	for _, item := range items {
+
+		t, err := r.prepareTask(item)
+		if err != nil {
+			return nil, err
+		}
+
+		retVal = append(retVal, t)
+	}
+
+	return retVal, nil
+}
+
+func (r *templateResolver) prepareTask(item *TaskTemplateItem) (*preparedTask, error) {
+
+	name, err := r.expand(item.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		return nil, fmt.Errorf("task is missing required field(s): Name")
+	}
+
+	t := &preparedTask{name: name}
+
+	t.data.Task.Name = name
+
+	t.data.Task.Description, err = r.expand(item.Description)
+	if err != nil {
+		return nil, err
+	}
+
+	if item.Estimate != "" {
+		estimate, err := r.expand(item.Estimate)
+		if err != nil {
+			return nil, err
+		}
+
+		d, err := time.ParseDuration(estimate)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid estimate (%s) for task (%s)", estimate, name)
+		}
+
+		t.data.Task.EstimatedMinutes = int(d.Minutes())
+	}
+
+	t.data.Task.StartAt, err = r.date(item.Start)
+	if err != nil {
+		return nil, fmt.Errorf("%s for task (%s)", err, name)
+	}
+
+	t.data.Task.DueAt, err = r.date(item.Due)
+	if err != nil {
+		return nil, fmt.Errorf("%s for task (%s)", err, name)
+	}
+
+	for _, a := range item.Assignees {
+
+		id, err := r.person(a)
+		if err != nil {
+			return nil, fmt.Errorf("%s for task (%s)", err, name)
+		}
+
+		if t.data.Task.Assignees == nil {
+			t.data.Task.Assignees = map[string][]int64{"userIds": nil}
+		}
+		t.data.Task.Assignees["userIds"] = append(t.data.Task.Assignees["userIds"], id)
+	}
+
+	for _, tag := range item.Tags {
+
+		id, err := r.tag(tag)
+		if err != nil {
+			return nil, fmt.Errorf("%s for task (%s)", err, name)
+		}
+
+		t.data.Task.TagIDs = append(t.data.Task.TagIDs, id)
+	}
+
+	t.subtasks, err = r.prepareTasks(item.Subtasks)
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// expand substitutes the variables referenced in s.
+func (r *templateResolver) expand(s string) (string, error) {
+
+	var missing []string
+
+	retVal := templateVariable.ReplaceAllStringFunc(s, func(ref string) string {
+
+		name := strings.TrimSpace(ref[2 : len(ref)-1])
+
+		v, ok := r.vars[name]
+		if !ok {
+			missing = append(missing, name)
+		}
+
+		return v
+	})
+
+	if len(missing) > 0 {
+		return "", fmt.Errorf("undefined template variable(s): %s", strings.Join(missing, ", "))
+	}
+
+	return retVal, nil
+}
+
+// date resolves a relative date, returning "" if s is empty.
+func (r *templateResolver) date(s string) (string, error) {
+
+	s, err := r.expand(s)
+	if err != nil || s == "" {
+		return "", err
+	}
+
+	m := relativeDate.FindStringSubmatch(strings.ReplaceAll(s, " ", ""))
+	if m == nil {
+		return "", fmt.Errorf("invalid relative date (%s)", s)
+	}
+
+	n, _ := strconv.Atoi(m[1])
+
+	d := r.base
+
+	switch m[2] {
+	case "d":
+		d = d.AddDate(0, 0, n)
+	case "w":
+		d = d.AddDate(0, 0, 7*n)
+	case "bd":
+		d = r.calendar.AddBusinessDays(d, n)
+	}
+
+	return d.Format(TeamworkDateFormatV3), nil
+}
+
+// person resolves a role or email address to the ID of a person.
+func (r *templateResolver) person(assignee string) (int64, error) {
+
+	email, err := r.expand(assignee)
+	if err != nil {
+		return 0, err
+	}
+
+	if !strings.Contains(email, "@") {
+		v, ok := r.roles[email]
+		if !ok {
+			return 0, fmt.Errorf("undefined assignee role (%s)", email)
+		}
+		email = v
+	}
+
+	if r.people == nil {
+
+		people, err := r.conn.GetPeople(PeopleQueryParams{})
+		if err != nil {
+			return 0, err
+		}
+
+		r.people = make(map[string]int64)
+
+		for _, p := range people {
+			id, err := strconv.ParseInt(p.ID, 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid ID (%s) for person (%s)", p.ID, p.Email)
+			}
+			r.people[strings.ToLower(p.Email)] = id
+		}
+	}
+
+	id, ok := r.people[strings.ToLower(email)]
+	if !ok {
+		return 0, fmt.Errorf("no person found with email (%s)", email)
+	}
+
+	return id, nil
+}
+
+// tag resolves the name of a tag to its ID.
+func (r *templateResolver) tag(name string) (int, error) {
+
+	name, err := r.expand(name)
+	if err != nil {
+		return 0, err
+	}
+
+	err = r.loadTags()
+	if err != nil {
+		return 0, err
+	}
+
+	id, ok := r.tags[strings.ToLower(name)]
+	if !ok {
+		return 0, fmt.Errorf("no tag found with name (%s)", name)
+	}
+
+	return id, nil
+}
+
+// tagName resolves the ID of a tag to its name.
+func (r *templateResolver) tagName(id int) (string, error) {
+
+	err := r.loadTags()
+	if err != nil {
+		return "", err
+	}
+
+	name, ok := r.tagNames[id]
+	if !ok {
+		return "", fmt.Errorf("no tag found with ID (%d)", id)
+	}
+
+	return name, nil
+}
+
+func (r *templateResolver) loadTags() error {
+
+	if r.tags != nil {
+		return nil
+	}
+
+	tags, err := r.conn.GetTags()
+	if err != nil {
+		return err
+	}
+
+	r.tags = make(map[string]int)
+	r.tagNames = make(map[int]string)
+
+	for _, t := range tags {
+		s, err := t.ID()
+		if err != nil {
+			return err
+		}
+
+		id, err := strconv.Atoi(s)
+		if err != nil {
+			return err
+		}
+
+		r.tags[strings.ToLower(t.Name)] = id
+		r.tagNames[id] = t.Name
+	}
+
+	return nil
+}
@@ -0,0 +1,186 @@
+package teamworkapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testTaskTemplateYAML = `
+name: Onboarding
+variables:
+  employee: Jane
+roles:
+  manager: boss@example.com
+tasklists:
+  - name: Onboarding ${employee}
+    tasks:
+      - name: Prepare laptop for ${employee}
+        estimate: 1h30m
+        start: -2bd
+        due: 3bd
+        assignees: [it]
+        tags: [Onboarding]
+        subtasks:
+          - name: Install VPN
+            due: +1w
+            assignees: [manager, IT@example.com]
+      - name: Welcome lunch
+`
+
+func TestReadTaskTemplate(t *testing.T) {
+
+	tmpl, err := ReadTaskTemplate(strings.NewReader(testTaskTemplateYAML))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if tmpl.Name != "Onboarding" || len(tmpl.TaskLists) != 1 || len(tmpl.TaskLists[0].Tasks) != 2 {
+		t.Fatalf("unexpected template parsed: %+v", tmpl)
+	}
+
+	task := tmpl.TaskLists[0].Tasks[0]
+	if task.Estimate != "1h30m" || len(task.Subtasks) != 1 || task.Subtasks[0].Assignees[1] != "IT@example.com" {
+		t.Errorf("unexpected task parsed: %+v", task)
+	}
+
+	tmpl, err = ReadTaskTemplate(strings.NewReader(`{"name": "Release", "tasklists": [{"name": "Release", "tasks": [{"name": "Tag"}]}]}`))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if tmpl.Name != "Release" || tmpl.TaskLists[0].Tasks[0].Name != "Tag" {
+		t.Errorf("unexpected template parsed: %+v", tmpl)
+	}
+
+	_, err = ReadTaskTemplate(strings.NewReader("name: Release\nowner: me\n"))
+	if err == nil || !strings.HasPrefix(err.Error(), "failed to parse task template") {
+		t.Errorf("expected parse error but got (%v)", err)
+	}
+}
+
+func TestInstantiateTaskTemplate(t *testing.T) {
+
+	var calls []string
+	var bodies []*TaskV3JSON
+
+	conn := initMockConnection(t, func(w http.ResponseWriter, r *http.Request) {
+
+		raw, _ := ioutil.ReadAll(r.Body)
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/people.json":
+			fmt.Fprint(w, `{"STATUS": "OK", "people": [{"id": "7", "user-name": "boss@example.com"}, {"id": "8", "user-name": "it@example.com"}]}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/tags.json":
+			fmt.Fprint(w, `{"STATUS": "OK", "tags": [{"id": 1234567, "name": "onboarding"}]}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/projects/1/tasklists.json":
+			fmt.Fprint(w, `{"STATUS": "OK", "tasklists": []}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/projects/1/tasklists.json":
+			calls = append(calls, r.Method+" "+r.URL.Path)
+			fmt.Fprint(w, `{"STATUS": "OK", "TASKLISTID": "30"}`)
+		case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/projects/api/v3/"):
+			calls = append(calls, r.Method+" "+r.URL.Path)
+			b := new(TaskV3JSON)
+			json.Unmarshal(raw, b)
+			bodies = append(bodies, b)
+			fmt.Fprintf(w, `{"task": {"id": %d}}`, 40+len(bodies))
+		default:
+			fmt.Fprint(w, `{"STATUS": "Error", "MESSAGE": "not found"}`)
+		}
+	})
+
+	tmpl, err := ReadTaskTemplate(strings.NewReader(testTaskTemplateYAML))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	opts := TaskTemplateOptions{
+		Variables: map[string]string{"employee": "Sam"},
+		Roles:     map[string]string{"it": "it@example.com"},
+		BaseDate:  time.Date(2021, 3, 1, 15, 0, 0, 0, time.UTC),
+	}
+
+	// task lists, people and tags use v1 and tasks use v3, whichever version
+	// the connection targets
+	for _, url := range []string{conn.URL, conn.URL + "projects/api/v3/"} {
+
+		conn.URL = url
+		calls = nil
+		bodies = nil
+
+		res, err := conn.InstantiateTaskTemplate("1", tmpl, opts)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		wantCalls := []string{
+			"POST /projects/1/tasklists.json",
+			"POST /projects/api/v3/tasklists/30/tasks.json",
+			"POST /projects/api/v3/tasks/41/subtasks.json",
+			"POST /projects/api/v3/tasklists/30/tasks.json",
+		}
+
+		if !reflect.DeepEqual(calls, wantCalls) {
+			t.Fatalf("expected calls %v but got %v", wantCalls, calls)
+		}
+
+		task := bodies[0].Task
+		if task.Name != "Prepare laptop for Sam" || task.EstimatedMinutes != 90 || task.StartAt != "2021-02-25" ||
+			task.DueAt != "2021-03-04" || !reflect.DeepEqual(task.Assignees["userIds"], []int64{8}) || !reflect.DeepEqual(task.TagIDs, []int{1234567}) {
+			t.Errorf("unexpected task posted: %+v", task)
+		}
+
+		subtask := bodies[1].Task
+		if subtask.DueAt != "2021-03-08" || !reflect.DeepEqual(subtask.Assignees["userIds"], []int64{7, 8}) {
+			t.Errorf("unexpected subtask posted: %+v", subtask)
+		}
+
+		if res.TaskListIDs["Onboarding Sam"] != "30" || len(res.Tasks) != 3 || res.Tasks[1].ParentID != 41 || res.Tasks[2].ID != 43 {
+			t.Errorf("unexpected result: %+v", res)
+		}
+	}
+
+	var tests = []struct {
+		opts TaskTemplateOptions
+		tmpl string
+		want string
+	}{
+		{TaskTemplateOptions{}, "tasklists: [{name: '${team}'}]", "undefined template variable(s): team"},
+		{TaskTemplateOptions{}, "tasklists: [{tasks: [{name: A}]}]", "task list 1 of template is missing required field(s): Name"},
+		{TaskTemplateOptions{}, "tasklists: [{name: L, tasks: [{name: A, due: tomorrow}]}]", "invalid task in task list (L): invalid relative date (tomorrow) for task (A)"},
+		{TaskTemplateOptions{}, "tasklists: [{name: L, tasks: [{name: A, estimate: 2 days}]}]", "invalid task in task list (L): invalid estimate (2 days) for task (A)"},
+		{TaskTemplateOptions{}, "tasklists: [{name: L, tasks: [{name: A, assignees: [qa]}]}]", "invalid task in task list (L): undefined assignee role (qa) for task (A)"},
+		{TaskTemplateOptions{Roles: map[string]string{"qa": "qa@example.com"}}, "tasklists: [{name: L, tasks: [{name: A, assignees: [qa]}]}]", "invalid task in task list (L): no person found with email (qa@example.com) for task (A)"},
+		{TaskTemplateOptions{}, "tasklists: [{name: L, tasks: [{name: A, tags: [urgent]}]}]", "invalid task in task list (L): no tag found with name (urgent) for task (A)"},
+	}
+
+	calls = nil
+
+	for _, v := range tests {
+
+		tmpl, err := ReadTaskTemplate(strings.NewReader(v.tmpl))
+		if err != nil {
+			t.Errorf(err.Error())
+			continue
+		}
+
+		_, err = conn.InstantiateTaskTemplate("1", tmpl, v.opts)
+		if err == nil || err.Error() != v.want {
+			t.Errorf("expected error (%s) but got (%v)", v.want, err)
+		}
+	}
+
+	if len(calls) != 0 {
+		t.Errorf("expected no changes for invalid templates but got %v", calls)
+	}
+
+	res, err := conn.InstantiateTaskTemplate("1", tmpl, TaskTemplateOptions{DryRun: true, Roles: opts.Roles})
+	if err != nil || len(res.Tasks) != 3 || len(calls) != 0 {
+		t.Errorf("expected dry run to report 3 tasks without changes but got %v (%v)", calls, err)
+	}
+}
@@ -0,0 +1,169 @@
+package teamworkapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// TaskList models a Teamwork task list.
+type TaskList struct {
+	ID          string `json:"id,omitempty"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	ProjectID   string `json:"projectId,omitempty"`
+	Complete    bool   `json:"complete,omitempty"`
+}
+
+// TaskListJSON models the parent JSON structure of a single TaskList and
+// facilitates marshalling.
+type TaskListJSON struct {
+	TaskList *TaskList `json:"todo-list"`
+}
+
+// TaskListsJSON models the parent JSON structure of an array of TaskLists and
+// facilitates unmarshalling.
+type TaskListsJSON struct {
+	TaskLists []*TaskList `json:"tasklists"`
+}
+
+// TaskListResponseHandler models a http response for a TaskList operation.
+type TaskListResponseHandler struct {
+	Status  string `json:"STATUS"`
+	Message string `json:"MESSAGE"`
+	ID      string `json:"TASKLISTID"`
+}
+
+// ParseResponse interprets the http response for a TaskList operation.
+func (resMsg *TaskListResponseHandler) ParseResponse(httpMethod string, rawRes []byte) error {
+
+	err := json.Unmarshal(rawRes, &resMsg)
+	if err != nil {
+		return err
+	}
+
+	if resMsg.Status == "Error" {
+		return fmt.Errorf("received ERROR response: %s", resMsg.Message)
+	}
+
+	switch httpMethod {
+	case http.MethodPost:
+		if resMsg.ID == "" {
+			return fmt.Errorf("no ID returned for TaskList POST request")
+		}
+	}
+
+	return nil
+}
+
+// GetTaskLists retrieves the task lists of a project.
+func (conn *Connection) GetTaskLists(projectID string) ([]*TaskList, error) {
+
+	err := validateID("projectID", projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := conn.GetRequest("projects/"+projectID+"/tasklists", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	lists := new(TaskListsJSON)
+
+	err = json.Unmarshal(data, &lists)
+	if err != nil {
+		return nil, err
+	}
+
+	return lists.TaskLists, nil
+}
+
+// GetTaskListByName retrieves a task list of a project by name
+// (case-insensitive).  A nil TaskList is returned if none is found.
+func (conn *Connection) GetTaskListByName(projectID string, name string) (*TaskList, error) {
+
+	lists, err := conn.GetTaskLists(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, l := range lists {
+		if strings.EqualFold(strings.TrimSpace(l.Name), strings.TrimSpace(name)) {
+			return l, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// PostTaskList creates a task list in a project.  The ID of the new task list
+// is returned and stored in taskList.
+func (conn *Connection) PostTaskList(projectID string, taskList *TaskList) (string, error) {
+
+	err := validateID("projectID", projectID)
+	if err != nil {
+		return "", err
+	}
+
+	if taskList == nil || strings.TrimSpace(taskList.Name) == "" {
+		return "", fmt.Errorf("task list is missing required field(s): Name")
+	}
+
+	data, err := json.Marshal(TaskListJSON{TaskList: taskList})
+	if err != nil {
+		return "", err
+	}
+
+	handler := new(TaskListResponseHandler)
+
+	err = conn.PostRequest("projects/"+projectID+"/tasklists", data, handler)
+	if err != nil {
+		return "", err
+	}
+
+	taskList.ID = handler.ID
+
+	return handler.ID, nil
+}
+
+// EnsureTaskList returns the ID of the task list of a project with the
+// specified name, creating it if missing.
+func (conn *Connection) EnsureTaskList(projectID string, name string) (string, error) {
+
+	l, err := conn.GetTaskListByName(projectID, name)
+	if err != nil {
+		return "", err
+	}
+
+	if l != nil {
+		return l.ID, nil
+	}
+
+	return conn.PostTaskList(projectID, &TaskList{Name: name})
+}
+
+// GetTasksByTaskList retrieves the tasks of a task list, including completed
+// tasks.
+func (conn *Connection) GetTasksByTaskList(taskListID string) ([]*Task, error) {
+
+	err := validateID("taskListID", taskListID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := conn.GetRequest("tasklists/"+taskListID+"/tasks", TaskQueryParams{IncludeCompleted: true})
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := new(TasksJSON)
+
+	err = json.Unmarshal(data, &tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	return tasks.Tasks, nil
+}
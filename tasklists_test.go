@@ -0,0 +1,120 @@
+package teamworkapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestTaskLists(t *testing.T) {
+
+	var posted []*TaskList
+	var query string
+
+	conn := initMockConnection(t, func(w http.ResponseWriter, r *http.Request) {
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/projects/1/tasklists.json":
+			fmt.Fprint(w, `{"STATUS": "OK", "tasklists": [{"id": "30", "name": "Backlog"}]}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/projects/1/tasklists.json":
+			raw, _ := ioutil.ReadAll(r.Body)
+			l := new(TaskListJSON)
+			json.Unmarshal(raw, &l)
+			posted = append(posted, l.TaskList)
+			fmt.Fprint(w, `{"STATUS": "OK", "TASKLISTID": "31"}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/tasklists/30/tasks.json":
+			query = r.URL.RawQuery
+			fmt.Fprint(w, `{"STATUS": "OK", "todo-items": [{"id": 40, "content": "Write docs"}]}`)
+		default:
+			fmt.Fprint(w, `{"STATUS": "Error", "MESSAGE": "not found"}`)
+		}
+	})
+
+	var tests = []struct {
+		name   string
+		id     string
+		posted int
+	}{
+		{" backlog", "30", 0},
+		{"Release", "31", 1},
+	}
+
+	for _, v := range tests {
+
+		id, err := conn.EnsureTaskList("1", v.name)
+		if err != nil || id != v.id || len(posted) != v.posted {
+			t.Errorf("expected task list (%s) with %d posted but got (%s) with %d (%v)", v.id, v.posted, id, len(posted), err)
+		}
+	}
+
+	tasks, err := conn.GetTasksByTaskList("30")
+	if err != nil || len(tasks) != 1 || tasks[0].Title != "Write docs" {
+		t.Errorf("expected 1 task but got %d (%v)", len(tasks), err)
+	}
+
+	if query != "includeCompletedTasks=true" {
+		t.Errorf("expected completed tasks to be included but got query (%s)", query)
+	}
+
+	_, err = conn.PostTaskList("1", &TaskList{})
+	if err == nil || err.Error() != "task list is missing required field(s): Name" {
+		t.Errorf("expected missing name error but got (%v)", err)
+	}
+}
+
+func TestTaskItems(t *testing.T) {
+
+	var calls []string
+
+	conn := initMockConnection(t, func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		fmt.Fprint(w, `{"STATUS": "OK", "id": "42"}`)
+	})
+
+	item := &TaskItem{Content: "Rotate keys", Priority: "high", DueDate: "20210301"}
+
+	id, err := conn.PostTaskItem("30", item)
+	if err != nil || id != "42" {
+		t.Errorf("expected task (42) but got (%s, %v)", id, err)
+	}
+
+	for _, f := range []func() error{
+		func() error { return conn.PutTaskItem("42", item) },
+		func() error { return conn.CompleteTask("42") },
+		func() error { return conn.UncompleteTask("42") },
+	} {
+		err = f()
+		if err != nil {
+			t.Errorf(err.Error())
+		}
+	}
+
+	want := "POST /tasklists/30/tasks.json,PUT /tasks/42.json,PUT /tasks/42/complete.json,PUT /tasks/42/uncomplete.json"
+
+	if strings.Join(calls, ",") != want {
+		t.Errorf("expected calls (%s) but got (%s)", want, strings.Join(calls, ","))
+	}
+
+	var tests = []struct {
+		item *TaskItem
+		want string
+	}{
+		{nil, "task is missing required field(s): Content"},
+		{&TaskItem{Content: "a", Priority: "urgent"}, "invalid value (urgent) for Priority"},
+		{&TaskItem{Content: "a", DueDate: "2021-03-01"}, "invalid format for DueDate parameter.  Should be YYYYMMDD, but found 2021-03-01"},
+	}
+
+	for _, v := range tests {
+		err := validateTaskItem(v.item)
+		if err == nil || err.Error() != v.want {
+			t.Errorf("expected error (%s) but got (%v)", v.want, err)
+		}
+	}
+	err = conn.DeleteTask("")
+	if err == nil || err.Error() != "missing required parameter(s): taskID" {
+		t.Errorf("expected missing taskID error but got (%v)", err)
+	}
+}
@@ -6,7 +6,7 @@ import (
 	"math"
 	"net/http"
 	"strconv"
-	"time"
+	"strings"
 
 	"github.com/google/go-querystring/query"
 )
@@ -29,6 +29,7 @@ type TaskVersion3 struct {
 			ID   int    `json:"id"`
 			Type string `json:"type"`
 		} `json:"attachments"`
+		RepeatOptions *TaskRepeatOptions `json:"repeatOptions"`
 	} `json:"task"`
 }
 
@@ -39,16 +40,31 @@ type Task struct {
 	Description    string `json:"description"`
 	ProjectID      int    `json:"project-id"`
 	TaskListID     int    `json:"todo-list-id"`
+	ParentTaskID   string `json:"parentTaskId"`
 	Status         string `json:"status"`
 	CompanyID      int    `json:"company-id"`
 	DueDate        string `json:"due-date"`
+	StartDate      string `json:"start-date"`
 	CreatedOn      string `json:"created-on"`
 	CompletedOn    string `json:"completed_on"`
 	EstimatedMin   int    `json:"estimated-minutes"`
 	Priority       string `json:"priority"`
 	AssignedUserID string `json:"responsible-party-id"`
 	TimeTotals     *TimeTotals
-	Tags           []Tag `json:"tags"`
+	Tags           []Tag            `json:"tags"`
+	Order          int              `json:"order"`
+	BoardColumn    *TaskBoardColumn `json:"boardColumn,omitempty"`
+	// ChangeFollowerIDs and CommentFollowerIDs are comma separated lists of
+	// people notified of changes to, and comments on, the task.
+	ChangeFollowerIDs  string `json:"changeFollowerIds"`
+	CommentFollowerIDs string `json:"commentFollowerIds"`
+}
+
+// TaskBoardColumn models the board column a task is in.
+type TaskBoardColumn struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
 }
 
 // TaskJSON models the parent JSON structure of an individual task and
@@ -73,25 +89,51 @@ type TaskV3 struct {
 	Assignees        map[string][]int64 `json:"assignees"`
 	DueAt            string             `json:"dueAt"`
 	StartAt          string             `json:"startAt"`
+	RepeatOptions    *TaskRepeatOptions `json:"repeatOptions,omitempty"`
+	TagIDs           []int              `json:"tagIds,omitempty"`
 }
 
 type TasksV3Res struct {
-	Tasks []TaskRes `json:"tasks"`
+	Tasks    []TaskRes `json:"tasks"`
+	Included struct {
+		CustomFieldTasks map[string]*CustomFieldValue `json:"customfieldTasks"`
+	} `json:"included"`
 }
 
+// TaskV3JSON models the body of a request to create a task.  If Notify is
+// nil, Teamwork decides whether assignees are notified.
 type TaskV3JSON struct {
-	Task TaskV3 `json:"task"`
+	Task   TaskV3 `json:"task"`
+	Notify *bool  `json:"notify,omitempty"`
 }
 
+// TaskRes models a task returned by version 3 of the Teamwork API.  Dates are
+// in TeamworkDateFormatLong, and RepeatOptions is nil for tasks that do not
+// repeat.
 type TaskRes struct {
-	Id              int   `json:"id"`
-	AssigneeUserIds []int `json:"assigneeUserIds"`
+	Id              int    `json:"id"`
+	AssigneeUserIds []int  `json:"assigneeUserIds"`
+	Name            string `json:"name"`
+	Description     string `json:"description"`
+	Status          string `json:"status"`
+	TaskListID      int    `json:"tasklistId"`
+	ParentTaskID    int    `json:"parentTaskId"`
+	TagIDs          []int  `json:"tagIds"`
+	EstimateMinutes int    `json:"estimateMinutes"`
+	StartDate       string `json:"startDate"`
+	DueDate         string `json:"dueDate"`
+	Assignees       []struct {
+		ID   int    `json:"id"`
+		Type string `json:"type"`
+	} `json:"assignees"`
+	RepeatOptions *TaskRepeatOptions `json:"repeatOptions"`
 }
 
 type TaskPatchV3JSON struct {
 	Attachments TaskPatchAttachments `json:"attachments,omitempty"`
 	Task        struct {
-		Description string `json:"description,omitempty"`
+		Description   string             `json:"description,omitempty"`
+		RepeatOptions *TaskRepeatOptions `json:"repeatOptions,omitempty"`
 	} `json:"task"`
 }
 
@@ -158,8 +200,23 @@ type TaskQueryParams struct {
 	Include          string `url:"include,omitempty"`
 	ProjectIDs       string `url:"projectIds,omitempty"`
 	PageSize         string `url:"pageSize,omitempty"`
-	CompletedBefore  string `url:"completedBefore"`
-	CompletedAfter   string `url:"completedAfter"`
+	Page             string `url:"page,omitempty"`
+	CompletedBefore  string `url:"completedBefore,omitempty"`
+	CompletedAfter   string `url:"completedAfter,omitempty"`
+}
+
+// TaskQueryParamsV3 defines valid query parameters for retrieving tasks using
+// version 3 of the Teamwork API.  StartDate and EndDate are YYYY-MM-DD.
+type TaskQueryParamsV3 struct {
+	AssigneeUserIDs       []string `url:"assigneeUserIds,omitempty,comma"`
+	ProjectIDs            []string `url:"projectIds,omitempty,comma"`
+	TaskListIDs           []string `url:"tasklistIds,omitempty,comma"`
+	StartDate             string   `url:"startDate,omitempty"`
+	EndDate               string   `url:"endDate,omitempty"`
+	IncludeCompletedTasks bool     `url:"includeCompletedTasks,omitempty"`
+	Include               string   `url:"include,omitempty"`
+	PageSize              string   `url:"pageSize,omitempty"`
+	Page                  string   `url:"page,omitempty"`
 }
 
 func (resMsg *TaskResponseHandlerV3) ParseResponse(httpMethod string, rawRes []byte) error {
@@ -192,18 +249,35 @@ func (resMsg *TaskResponseHandlerV3) ParseResponse(httpMethod string, rawRes []b
 // FormatQueryParams formats query parameters for this resource.
 func (qp TaskQueryParams) FormatQueryParams() (string, error) {
 
-	if qp.FromDate != "" {
-		_, err := time.Parse("20060102", qp.FromDate)
-		if err != nil {
-			return "", fmt.Errorf("invalid format for FromDate parameter.  Should be YYYYMMDD, but found %s", qp.FromDate)
-		}
+	err := validateDateParam("FromDate", qp.FromDate, TeamworkDateFormatShort)
+	if err != nil {
+		return "", err
 	}
 
-	if qp.ToDate != "" {
-		_, err := time.Parse("20060102", qp.ToDate)
-		if err != nil {
-			return "", fmt.Errorf("invalid format for ToDate parameter.  Should be YYYYMMDD, but found %s", qp.ToDate)
-		}
+	err = validateDateParam("ToDate", qp.ToDate, TeamworkDateFormatShort)
+	if err != nil {
+		return "", err
+	}
+
+	params, err := query.Values(qp)
+	if err != nil {
+		return "", err
+	}
+
+	return params.Encode(), nil
+}
+
+// FormatQueryParamsV3 formats query parameters for this resource.
+func (qp TaskQueryParamsV3) FormatQueryParamsV3() (string, error) {
+
+	err := validateDateParam("StartDate", qp.StartDate, TeamworkDateFormatV3)
+	if err != nil {
+		return "", err
+	}
+
+	err = validateDateParam("EndDate", qp.EndDate, TeamworkDateFormatV3)
+	if err != nil {
+		return "", err
 	}
 
 	params, err := query.Values(qp)
@@ -299,6 +373,120 @@ func (conn *Connection) GetTasks(queryParams TaskQueryParams) ([]*Task, error) {
 	return tasks.Tasks, nil
 }
 
+// GetAllTasks returns every page of tasks matching queryParams.  Pages of
+// PageSize tasks (250 if not set) are requested until one is not full.  The
+// Page parameter is ignored.
+func (conn *Connection) GetAllTasks(queryParams TaskQueryParams) ([]*Task, error) {
+	return getTaskPages(queryParams, conn.GetTasks)
+}
+
+// getTaskPages pages through the tasks matching queryParams as GetAllTasks
+// does, fetching each page with get.
+func getTaskPages(queryParams TaskQueryParams, get func(TaskQueryParams) ([]*Task, error)) ([]*Task, error) {
+
+	if queryParams.PageSize == "" {
+		queryParams.PageSize = "250"
+	}
+
+	size, err := strconv.Atoi(queryParams.PageSize)
+	if err != nil || size < 1 {
+		return nil, fmt.Errorf("invalid value (%s) for PageSize", queryParams.PageSize)
+	}
+
+	var retVal []*Task
+
+	for page := 1; ; page++ {
+
+		queryParams.Page = strconv.Itoa(page)
+
+		tasks, err := get(queryParams)
+		if err != nil {
+			return nil, err
+		}
+
+		retVal = append(retVal, tasks...)
+
+		if len(tasks) < size {
+			return retVal, nil
+		}
+	}
+}
+
+// GetTasksV3 returns the tasks matching queryParams using version 3 of the
+// Teamwork API, along with any included data requested.
+func (conn *Connection) GetTasksV3(queryParams TaskQueryParamsV3) (*TasksV3Res, error) {
+
+	data, err := conn.GetRequestV3("tasks", queryParams)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := new(TasksV3Res)
+
+	err = json.Unmarshal(data, &tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+// GetAllTasksV3 retrieves every page of tasks specified by queryParams.  The
+// Page parameter is ignored.
+func (conn *Connection) GetAllTasksV3(queryParams TaskQueryParamsV3) (*TasksV3Res, error) {
+
+	retVal := new(TasksV3Res)
+
+	err := conn.getPagesV3("tasks", func(page int) QueryParamsV3 {
+		queryParams.Page = strconv.Itoa(page)
+		return queryParams
+	}, func(data []byte) error {
+
+		tasks := new(TasksV3Res)
+
+		err := json.Unmarshal(data, &tasks)
+		if err != nil {
+			return err
+		}
+
+		retVal.Tasks = append(retVal.Tasks, tasks.Tasks...)
+
+		for k, v := range tasks.Included.CustomFieldTasks {
+			if retVal.Included.CustomFieldTasks == nil {
+				retVal.Included.CustomFieldTasks = make(map[string]*CustomFieldValue)
+			}
+			retVal.Included.CustomFieldTasks[k] = v
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return retVal, nil
+}
+
+// AssigneeIDs returns the IDs of the people assigned to the task.
+func (t *TaskRes) AssigneeIDs() []string {
+
+	var retVal []string
+
+	for _, a := range t.Assignees {
+		if a.Type == "users" {
+			retVal = append(retVal, strconv.Itoa(a.ID))
+		}
+	}
+
+	if len(t.Assignees) == 0 {
+		for _, id := range t.AssigneeUserIds {
+			retVal = append(retVal, strconv.Itoa(id))
+		}
+	}
+
+	return retVal
+}
+
 func (conn *Connection) PatchTask(taskID string, putData TaskPatchV3JSON) (int, error) {
 	handler := new(TaskResponseHandlerV3)
 
@@ -323,6 +511,14 @@ func (conn *Connection) PatchTask(taskID string, putData TaskPatchV3JSON) (int,
 func (conn *Connection) PostTask(taskListID string, postData TaskV3JSON) (int, error) {
 
 	handler := new(TaskResponseHandlerV3)
+
+	if postData.Task.RepeatOptions != nil {
+		err := validateTaskRepeatOptions(postData.Task.RepeatOptions)
+		if err != nil {
+			return 0, err
+		}
+	}
+
 	//b :=byteData.Bytes()
 	//fmt.Printf(handler.Message)
 	b, err := json.Marshal(postData)
@@ -359,10 +555,52 @@ func (conn *Connection) GetSubtaskV3(parentTaskID string) (*TasksV3Res, error) {
 	return tasks, nil
 }
 
+// GetAllSubtasksV3 retrieves every page of the subtasks of a task matching
+// queryParams.  The Page parameter is ignored.
+func (conn *Connection) GetAllSubtasksV3(parentTaskID string, queryParams TaskQueryParamsV3) (*TasksV3Res, error) {
+
+	err := validateID("parentTaskID", parentTaskID)
+	if err != nil {
+		return nil, err
+	}
+
+	retVal := new(TasksV3Res)
+
+	err = conn.getPagesV3("tasks/"+parentTaskID+"/subtasks", func(page int) QueryParamsV3 {
+		queryParams.Page = strconv.Itoa(page)
+		return queryParams
+	}, func(data []byte) error {
+
+		tasks := new(TasksV3Res)
+
+		err := json.Unmarshal(data, &tasks)
+		if err != nil {
+			return err
+		}
+
+		retVal.Tasks = append(retVal.Tasks, tasks.Tasks...)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return retVal, nil
+}
+
 //Creates a subtask given the parent's task ID
 func (conn *Connection) PostSubTask(parentTaskID string, postData TaskV3JSON) (int, error) {
 
 	handler := new(TaskResponseHandlerV3)
+
+	if postData.Task.RepeatOptions != nil {
+		err := validateTaskRepeatOptions(postData.Task.RepeatOptions)
+		if err != nil {
+			return 0, err
+		}
+	}
+
 	b, err := json.Marshal(postData)
 	if err != nil {
 		return 0, err
@@ -419,10 +657,275 @@ func (conn *Connection) GetTaskHours(taskID string) (*TimeTotals, error) {
 	}, nil
 }
 
-// CalculateEstimateError determines the percent error of a time estimate.
+// CalculateEstimateError determines the percent error of a time estimate.  A
+// task without an estimate has no meaningful error, so 0 is returned.
 func CalculateEstimateError(estimate float64, actual float64) float64 {
 
+	if estimate == 0 {
+		return 0
+	}
+
 	accuracy := (estimate - actual) / estimate * 100
 
 	return math.Round(accuracy*100) / 100
 }
+
+// TaskItem models the writable fields of a task using version 1 of the
+// Teamwork API.  Tags is a comma separated list of tag names.
+type TaskItem struct {
+	Content            string `json:"content"`
+	Description        string `json:"description,omitempty"`
+	Priority           string `json:"priority,omitempty"`
+	ResponsiblePartyID string `json:"responsible-party-id,omitempty"`
+	StartDate          string `json:"start-date,omitempty"` // expected format is YYYYMMDD
+	DueDate            string `json:"due-date,omitempty"`   // expected format is YYYYMMDD
+	EstimatedMinutes   int    `json:"estimated-minutes,omitempty"`
+	Tags               string `json:"tags,omitempty"`
+	Notify             *bool  `json:"notify,omitempty"` // if nil, Teamwork decides whether assignees are notified
+}
+
+// TaskItemJSON models the parent JSON structure of a TaskItem and facilitates
+// marshalling.
+type TaskItemJSON struct {
+	Item *TaskItem `json:"todo-item"`
+}
+
+// TaskItemResponseHandler models a http response for a Task operation using
+// version 1 of the Teamwork API.
+type TaskItemResponseHandler struct {
+	Status  string `json:"STATUS"`
+	Message string `json:"MESSAGE"`
+	ID      string `json:"id"`
+}
+
+// ParseResponse interprets the http response for a Task operation.
+func (resMsg *TaskItemResponseHandler) ParseResponse(httpMethod string, rawRes []byte) error {
+
+	err := json.Unmarshal(rawRes, &resMsg)
+	if err != nil {
+		return err
+	}
+
+	if resMsg.Status == "Error" {
+		return fmt.Errorf("received ERROR response: %s", resMsg.Message)
+	}
+
+	switch httpMethod {
+	case http.MethodPost:
+		if resMsg.ID == "" {
+			return fmt.Errorf("no task id returned for Task Post request")
+		}
+	}
+
+	return nil
+}
+
+// PostTaskItem creates a task in a task list using version 1 of the Teamwork
+// API.  The ID of the new task is returned.
+func (conn *Connection) PostTaskItem(taskListID string, item *TaskItem) (string, error) {
+
+	err := validateTaskItem(item)
+	if err != nil {
+		return "", err
+	}
+
+	err = validateID("taskListID", taskListID)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(TaskItemJSON{Item: item})
+	if err != nil {
+		return "", err
+	}
+
+	handler := new(TaskItemResponseHandler)
+
+	err = conn.PostRequest("tasklists/"+taskListID+"/tasks", data, handler)
+	if err != nil {
+		return "", err
+	}
+
+	return handler.ID, nil
+}
+
+// PutTaskItem updates a task using version 1 of the Teamwork API.
+func (conn *Connection) PutTaskItem(taskID string, item *TaskItem) error {
+
+	err := validateTaskItem(item)
+	if err != nil {
+		return err
+	}
+
+	err = validateID("taskID", taskID)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(TaskItemJSON{Item: item})
+	if err != nil {
+		return err
+	}
+
+	return conn.PutRequest("tasks/"+taskID, data, new(TaskItemResponseHandler))
+}
+
+// CompleteTask marks a task as complete.
+func (conn *Connection) CompleteTask(taskID string) error {
+
+	err := validateID("taskID", taskID)
+	if err != nil {
+		return err
+	}
+
+	return conn.PutRequest("tasks/"+taskID+"/complete", nil, new(TaskItemResponseHandler))
+}
+
+// DeleteTask deletes a task, along with its subtasks.
+func (conn *Connection) DeleteTask(taskID string) error {
+
+	err := validateID("taskID", taskID)
+	if err != nil {
+		return err
+	}
+
+	return conn.DeleteRequest("tasks/"+taskID, new(TaskItemResponseHandler))
+}
+
+// UncompleteTask marks a completed task as incomplete.
+func (conn *Connection) UncompleteTask(taskID string) error {
+
+	err := validateID("taskID", taskID)
+	if err != nil {
+		return err
+	}
+
+	return conn.PutRequest("tasks/"+taskID+"/uncomplete", nil, new(TaskItemResponseHandler))
+}
+
+func validateTaskItem(item *TaskItem) error {
+
+	if item == nil || strings.TrimSpace(item.Content) == "" {
+		return fmt.Errorf("task is missing required field(s): Content")
+	}
+
+	switch item.Priority {
+	case "", "low", "medium", "high":
+	default:
+		return fmt.Errorf("invalid value (%s) for Priority", item.Priority)
+	}
+
+	err := validateDateParam("StartDate", item.StartDate, TeamworkDateFormatShort)
+	if err != nil {
+		return err
+	}
+
+	return validateDateParam("DueDate", item.DueDate, TeamworkDateFormatShort)
+}
+
+// Task follower types.
+const (
+	TaskFollowerChanges  = "change"
+	TaskFollowerComments = "comment"
+)
+
+// AddTaskFollowers adds people to the followers of a task.  followerType is
+// TaskFollowerChanges or TaskFollowerComments.  People already following are
+// ignored.
+func (conn *Connection) AddTaskFollowers(taskID string, followerType string, personIDs ...string) error {
+
+	err := validateTaskFollowers(taskID, followerType, personIDs)
+	if err != nil {
+		return err
+	}
+
+	task, err := conn.GetTaskByID(taskID)
+	if err != nil {
+		return err
+	}
+
+	followers := taskFollowers(task, followerType)
+
+	for _, id := range personIDs {
+		if !containsString(followers, id) {
+			followers = append(followers, id)
+		}
+	}
+
+	return conn.putTaskFollowers(taskID, followerType, followers)
+}
+
+// RemoveTaskFollowers removes people from the followers of a task.
+// followerType is TaskFollowerChanges or TaskFollowerComments.
+func (conn *Connection) RemoveTaskFollowers(taskID string, followerType string, personIDs ...string) error {
+
+	err := validateTaskFollowers(taskID, followerType, personIDs)
+	if err != nil {
+		return err
+	}
+
+	task, err := conn.GetTaskByID(taskID)
+	if err != nil {
+		return err
+	}
+
+	current := taskFollowers(task, followerType)
+
+	var followers []string
+
+	for _, id := range current {
+		if !containsString(personIDs, id) {
+			followers = append(followers, id)
+		}
+	}
+
+	return conn.putTaskFollowers(taskID, followerType, followers)
+}
+
+func (conn *Connection) putTaskFollowers(taskID string, followerType string, followers []string) error {
+
+	data, err := json.Marshal(map[string]map[string]string{
+		"todo-item": {followerType + "FollowerIds": strings.Join(followers, ",")},
+	})
+	if err != nil {
+		return err
+	}
+
+	return conn.PutRequest("tasks/"+taskID, data, new(TaskItemResponseHandler))
+}
+
+// validateTaskFollowers checks the parameters of AddTaskFollowers and
+// RemoveTaskFollowers, so that nothing is requested with invalid input.
+func validateTaskFollowers(taskID string, followerType string, personIDs []string) error {
+
+	err := validateID("taskID", taskID)
+	if err != nil {
+		return err
+	}
+
+	if followerType != TaskFollowerChanges && followerType != TaskFollowerComments {
+		return fmt.Errorf("invalid follower type (%s)", followerType)
+	}
+
+	if len(personIDs) == 0 {
+		return fmt.Errorf("missing required parameter(s): personIDs")
+	}
+
+	for _, id := range personIDs {
+		err = validateID("personIDs", id)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func taskFollowers(task *Task, followerType string) []string {
+
+	if followerType == TaskFollowerComments {
+		return splitIDs(task.CommentFollowerIDs)
+	}
+
+	return splitIDs(task.ChangeFollowerIDs)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net/http"
 	"net/url"
 	"os"
 	"reflect"
@@ -457,6 +458,7 @@ func TestCalculateEstimateError(t *testing.T) {
 		{30, 5, -500},
 		{5, 10, 50},
 		{2, 2, 0},
+		{4, 0, 0},
 	}
 
 	for _, v := range tests {
@@ -468,3 +470,117 @@ func TestCalculateEstimateError(t *testing.T) {
 		}
 	}
 }
+
+func TestTaskFollowers(t *testing.T) {
+
+	var bodies []string
+	requests := 0
+
+	conn := initMockConnection(t, func(w http.ResponseWriter, r *http.Request) {
+
+		requests++
+
+		switch r.Method {
+		case http.MethodGet:
+			fmt.Fprint(w, `{"STATUS": "OK", "todo-item": {"id": 5, "changeFollowerIds": "1,2", "commentFollowerIds": ""}}`)
+		case http.MethodPut:
+			if r.URL.Path != "/tasks/5.json" {
+				t.Errorf("unexpected path (%s)", r.URL.Path)
+			}
+			raw, _ := ioutil.ReadAll(r.Body)
+			bodies = append(bodies, string(raw))
+			fmt.Fprint(w, `{"STATUS": "OK"}`)
+		}
+	})
+
+	err := conn.AddTaskFollowers("5", TaskFollowerChanges, "2", "3")
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+
+	err = conn.AddTaskFollowers("5", TaskFollowerComments, "4")
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+
+	err = conn.RemoveTaskFollowers("5", TaskFollowerChanges, "1")
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+
+	want := []string{
+		`{"todo-item":{"changeFollowerIds":"1,2,3"}}`,
+		`{"todo-item":{"commentFollowerIds":"4"}}`,
+		`{"todo-item":{"changeFollowerIds":"2"}}`,
+	}
+
+	if !reflect.DeepEqual(bodies, want) {
+		t.Errorf("expected bodies %v but got %v", want, bodies)
+	}
+
+	// invalid input is rejected before any request is made
+	requests = 0
+
+	var tests = []struct {
+		taskID       string
+		followerType string
+		personIDs    []string
+		want         string
+	}{
+		{"5", "everyone", []string{"1"}, "invalid follower type (everyone)"},
+		{"", TaskFollowerChanges, []string{"1"}, "missing required parameter(s): taskID"},
+		{"abc", TaskFollowerChanges, []string{"1"}, "invalid value (abc) for taskID"},
+		{"5", TaskFollowerChanges, nil, "missing required parameter(s): personIDs"},
+		{"5", TaskFollowerComments, []string{"1", "bob"}, "invalid value (bob) for personIDs"},
+	}
+
+	for _, v := range tests {
+
+		err = conn.AddTaskFollowers(v.taskID, v.followerType, v.personIDs...)
+		if err == nil || err.Error() != v.want {
+			t.Errorf("expected error (%s) but got (%v)", v.want, err)
+		}
+
+		err = conn.RemoveTaskFollowers(v.taskID, v.followerType, v.personIDs...)
+		if err == nil || err.Error() != v.want {
+			t.Errorf("expected error (%s) but got (%v)", v.want, err)
+		}
+	}
+
+	if requests != 0 {
+		t.Errorf("expected no requests for invalid input but got %d", requests)
+	}
+}
+
+func TestPostTaskNotify(t *testing.T) {
+
+	var body string
+
+	conn := initMockConnection(t, func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := ioutil.ReadAll(r.Body)
+		body = string(raw)
+		fmt.Fprint(w, `{"task": {"id": 7}}`)
+	})
+
+	notify := false
+
+	id, err := conn.PostTask("3", TaskV3JSON{Task: TaskV3{Name: "Quiet task"}, Notify: &notify})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if id != 7 {
+		t.Errorf("expected task ID 7 but got %d", id)
+	}
+
+	posted := make(map[string]interface{})
+
+	err = json.Unmarshal([]byte(body), &posted)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if posted["notify"] != false {
+		t.Errorf("expected notify to be false but got (%v)", posted["notify"])
+	}
+}
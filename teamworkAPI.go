@@ -10,6 +10,8 @@ import (
 	"io/ioutil"
 	"net/http"
 	"os"
+	"strconv"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -185,6 +187,11 @@ func (conn *Connection) GetRequest(endpoint string, params QueryParams) ([]byte,
 		return nil, err
 	}
 
+	err = checkResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
 	data, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
@@ -225,6 +232,11 @@ func (conn *Connection) GetRequestV3(endpoint string, params QueryParamsV3) ([]b
 		return nil, err
 	}
 
+	err = checkResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
 	data, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
@@ -252,6 +264,11 @@ func (conn *Connection) PatchRequest(endpoint string, data []byte, resHandler Re
 		return err
 	}
 
+	err = checkResponse(resp)
+	if err != nil {
+		return err
+	}
+
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return err
@@ -282,6 +299,11 @@ func (conn *Connection) PostRequest(endpoint string, data []byte, resHandler Res
 		return err
 	}
 
+	err = checkResponse(resp)
+	if err != nil {
+		return err
+	}
+
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return err
@@ -296,6 +318,49 @@ func (conn *Connection) PostRequest(endpoint string, data []byte, resHandler Res
 	return err
 }
 
+// PutRequest submits a PUT request to Teamwork API.  The ResponseHandler is
+// used to properly interpret the http response and store the response content ([]byte)
+// for further processing.  If ResponseHandler is nil, the
+// GeneralResponse will be used.
+func (conn *Connection) PutRequest(endpoint string, data []byte, resHandler ResponseHandler) error {
+
+	if endpoint == "" {
+		return fmt.Errorf("missing required parameter(s): endpoint")
+	}
+
+	client := &http.Client{}
+	req, err := http.NewRequest("PUT", conn.URL+endpoint+".json", bytes.NewBuffer(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Authorization", "Basic "+basicAuth(conn.APIKey))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	err = checkResponse(resp)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resHandler == nil {
+		resHandler = new(GeneralResponse)
+	}
+
+	err = resHandler.ParseResponse(http.MethodPut, body)
+
+	return err
+}
+
 // DeleteRequest submits a DELETE request to Teamwork API.  The ResponseHandler is
 // used to properly interpret the http response and store the response content ([]byte)
 // for further processing.  If ResponseHandler is nil, the
@@ -317,6 +382,11 @@ func (conn *Connection) DeleteRequest(endpoint string, resHandler ResponseHandle
 		return err
 	}
 
+	err = checkResponse(resp)
+	if err != nil {
+		return err
+	}
+
 	data, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return err
@@ -330,6 +400,122 @@ func (conn *Connection) DeleteRequest(endpoint string, resHandler ResponseHandle
 	return err
 }
 
+// HTTPError reports a response from Teamwork with a status code showing that
+// the request may succeed if repeated: 429 Too Many Requests or a server
+// error.  RetryAfter is the delay requested by the Retry-After header of the
+// response, if any.
+type HTTPError struct {
+	StatusCode int
+	RetryAfter time.Duration
+}
+
+// Error describes the status of the response.
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("received HTTP %d (%s) response", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// checkResponse returns an HTTPError, closing the body, for a response that
+// was rate limited or failed on the server.  Other responses are interpreted
+// by the caller.
+func checkResponse(resp *http.Response) error {
+
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < http.StatusInternalServerError {
+		return nil
+	}
+
+	resp.Body.Close()
+
+	err := &HTTPError{StatusCode: resp.StatusCode}
+
+	if v := resp.Header.Get("Retry-After"); v != "" {
+		if seconds, e := strconv.Atoi(v); e == nil {
+			err.RetryAfter = time.Duration(seconds) * time.Second
+		} else if t, e := http.ParseTime(v); e == nil {
+			err.RetryAfter = time.Until(t)
+		}
+	}
+
+	return err
+}
+
+// PageMetaV3 models the paging information included in list responses from
+// version 3 of the Teamwork API.
+type PageMetaV3 struct {
+	Meta struct {
+		Page struct {
+			PageOffset int  `json:"pageOffset"`
+			PageSize   int  `json:"pageSize"`
+			Count      int  `json:"count"`
+			HasMore    bool `json:"hasMore"`
+		} `json:"page"`
+	} `json:"meta"`
+}
+
+// getPagesV3 retrieves successive pages of a v3 endpoint until the response
+// reports there are no more.  params returns the query parameters for the
+// specified page (starting at 1), and add is called with each response.
+func (conn *Connection) getPagesV3(endpoint string, params func(page int) QueryParamsV3, add func(data []byte) error) error {
+
+	for page := 1; ; page++ {
+
+		data, err := conn.GetRequestV3(endpoint, params(page))
+		if err != nil {
+			return err
+		}
+
+		err = add(data)
+		if err != nil {
+			return err
+		}
+
+		meta := new(PageMetaV3)
+
+		err = json.Unmarshal(data, &meta)
+		if err != nil {
+			return err
+		}
+
+		if !meta.Meta.Page.HasMore {
+			return nil
+		}
+	}
+}
+
+// v1 returns a copy of the connection that targets version 1 of the Teamwork
+// API, regardless of the version conn was created for.
+func (conn *Connection) v1() *Connection {
+
+	c := *conn
+	c.URL = conn.siteURL()
+
+	return &c
+}
+
+// v3 returns a copy of the connection that targets version 3 of the Teamwork
+// API, regardless of the version conn was created for.
+func (conn *Connection) v3() *Connection {
+
+	c := *conn
+	c.URL = conn.siteURL() + "projects/api/v3/"
+
+	return &c
+}
+
+// validateID checks that the ID parameter with the specified name is present
+// and numeric.
+func validateID(name string, ID string) error {
+
+	_, err := strconv.Atoi(ID)
+	if err != nil {
+		if ID == "" {
+			return fmt.Errorf("missing required parameter(s): %s", name)
+		}
+		return fmt.Errorf("invalid value (%s) for %s", ID, name)
+	}
+
+	return nil
+}
+
 func basicAuth(apiKey string) string {
 	return base64.StdEncoding.EncodeToString([]byte(apiKey))
 }
@@ -3,6 +3,8 @@ package teamworkapi
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/google/go-querystring/query"
@@ -197,3 +199,51 @@ func TestGetRequest(t *testing.T) {
 		}
 	}
 }
+
+// initMockConnection returns a connection to a local test server that serves
+// requests with handler.
+func initMockConnection(t *testing.T, handler http.HandlerFunc) *Connection {
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	conn, err := NewConnection("someKey", "someSite", "", "")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	conn.URL = srv.URL + "/"
+
+	return conn
+}
+
+func TestPutRequest(t *testing.T) {
+
+	conn := initMockConnection(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			t.Errorf("expected PUT request but got %s", r.Method)
+		}
+
+		if r.URL.Path == "/bad.json" {
+			fmt.Fprint(w, `{"STATUS": "Error", "MESSAGE": "bad request"}`)
+			return
+		}
+
+		fmt.Fprint(w, `{"STATUS": "OK"}`)
+	})
+
+	err := conn.PutRequest("good", []byte(`{}`), nil)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+
+	err = conn.PutRequest("bad", []byte(`{}`), nil)
+	if err == nil || err.Error() != "bad request" {
+		t.Errorf("expected error (bad request) but got (%v)", err)
+	}
+
+	err = conn.PutRequest("", []byte(`{}`), nil)
+	if err == nil {
+		t.Errorf("expected error for missing endpoint")
+	}
+}
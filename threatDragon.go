@@ -0,0 +1,498 @@
+package teamworkapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Threat Dragon threat statuses.
+const (
+	ThreatStatusOpen          = "Open"
+	ThreatStatusMitigated     = "Mitigated"
+	ThreatStatusNotApplicable = "NotApplicable"
+)
+
+// ThreatModel models an OWASP Threat Dragon model.  Both the version 1 and
+// version 2 file formats are supported.
+type ThreatModel struct {
+	Summary struct {
+		Title       string `json:"title"`
+		Owner       string `json:"owner"`
+		Description string `json:"description"`
+	} `json:"summary"`
+	Detail struct {
+		Contributors []struct {
+			Name string `json:"name"`
+		} `json:"contributors"`
+		Diagrams []*ThreatDiagram `json:"diagrams"`
+		Reviewer string           `json:"reviewer"`
+	} `json:"detail"`
+}
+
+// ThreatDiagram models a diagram of a Threat Dragon model.  Version 1 models
+// hold their cells in DiagramJSON.
+type ThreatDiagram struct {
+	ID          int           `json:"id"`
+	Title       string        `json:"title"`
+	DiagramType string        `json:"diagramType"`
+	Cells       []*ThreatCell `json:"cells"`
+	DiagramJSON struct {
+		Cells []*ThreatCell `json:"cells"`
+	} `json:"diagramJson"`
+}
+
+// ThreatCell models an element (process, store, actor, flow or boundary) of a
+// Threat Dragon diagram.  Version 1 models hold the name in Attrs or Labels
+// and the threats on the cell; version 2 models hold both in Data.
+type ThreatCell struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	Shape      string    `json:"shape"`
+	Threats    []*Threat `json:"threats"`
+	OutOfScope bool      `json:"outOfScope"`
+	Attrs      struct {
+		Text struct {
+			Text string `json:"text"`
+		} `json:"text"`
+	} `json:"attrs"`
+	Labels []struct {
+		Attrs struct {
+			Text struct {
+				Text string `json:"text"`
+			} `json:"text"`
+		} `json:"attrs"`
+	} `json:"labels"`
+	Data *struct {
+		Name       string    `json:"name"`
+		Threats    []*Threat `json:"threats"`
+		OutOfScope bool      `json:"outOfScope"`
+	} `json:"data"`
+}
+
+// Threat models a threat identified against a Threat Dragon cell.  Status is
+// one of ThreatStatusOpen, ThreatStatusMitigated or ThreatStatusNotApplicable.
+type Threat struct {
+	ID          string `json:"id"`
+	RuleID      string `json:"ruleId"`
+	Title       string `json:"title"`
+	Type        string `json:"type"`
+	Status      string `json:"status"`
+	Severity    string `json:"severity"`
+	Description string `json:"description"`
+	Mitigation  string `json:"mitigation"`
+	ModelType   string `json:"modelType"`
+}
+
+// ModelThreat is a threat together with where it was found in a model.  Key
+// identifies the threat across imports.
+type ModelThreat struct {
+	*Threat
+	Key     string
+	Diagram string
+	Cell    string
+}
+
+// ThreatImportOptions configures ImportThreatModel.  TaskListName defaults to
+// "Threat mitigations: " followed by the model title.  If CreateRisks is
+// true, a risk is kept for each threat.  If DryRun is true, the report is
+// produced without making changes.
+type ThreatImportOptions struct {
+	TaskListName string
+	CreateRisks  bool
+	DryRun       bool
+}
+
+// ThreatImportChange records what an import did for a threat.  Resource is
+// "task" or "risk"; Action is one of "created", "updated", "completed",
+// "reopened", "closed" or "unchanged".
+type ThreatImportChange struct {
+	Key      string
+	Title    string
+	Resource string
+	Action   string
+}
+
+// ThreatImportReport reports the changes made by ImportThreatModel.
+type ThreatImportReport struct {
+	TaskListID string
+	Changes    []*ThreatImportChange
+}
+
+// Count returns the number of changes of the specified resource and action.
+func (rep *ThreatImportReport) Count(resource string, action string) int {
+
+	n := 0
+
+	for _, c := range rep.Changes {
+		if c.Resource == resource && c.Action == action {
+			n++
+		}
+	}
+
+	return n
+}
+
+// ReadThreatModel parses an OWASP Threat Dragon model.
+func ReadThreatModel(r io.Reader) (*ThreatModel, error) {
+
+	model := new(ThreatModel)
+
+	err := json.NewDecoder(r).Decode(model)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse threat model: %s", err)
+	}
+
+	return model, nil
+}
+
+// ReadThreatModelFile parses the OWASP Threat Dragon model at path.
+func ReadThreatModelFile(path string) (*ThreatModel, error) {
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ReadThreatModel(f)
+}
+
+// Name returns the name of the cell.
+func (c *ThreatCell) Name() string {
+
+	if c.Data != nil && c.Data.Name != "" {
+		return c.Data.Name
+	}
+
+	if c.Attrs.Text.Text != "" {
+		return c.Attrs.Text.Text
+	}
+
+	for _, l := range c.Labels {
+		if l.Attrs.Text.Text != "" {
+			return l.Attrs.Text.Text
+		}
+	}
+
+	return c.ID
+}
+
+// Threats returns the threats of the model, skipping cells out of scope.
+func (m *ThreatModel) Threats() []*ModelThreat {
+
+	var retVal []*ModelThreat
+
+	for _, d := range m.Detail.Diagrams {
+
+		cells := d.Cells
+		if len(cells) == 0 {
+			cells = d.DiagramJSON.Cells
+		}
+
+		for _, c := range cells {
+
+			threats := c.Threats
+			outOfScope := c.OutOfScope
+
+			if c.Data != nil {
+				threats = append(threats, c.Data.Threats...)
+				outOfScope = outOfScope || c.Data.OutOfScope
+			}
+
+			if outOfScope {
+				continue
+			}
+
+			for _, t := range threats {
+
+				key := t.ID
+				if key == "" {
+					// version 1 threats have no ID
+					key = c.ID + "/" + strings.ToLower(strings.TrimSpace(t.Title))
+				}
+
+				retVal = append(retVal, &ModelThreat{
+					Threat:  t,
+					Key:     key,
+					Diagram: d.Title,
+					Cell:    c.Name(),
+				})
+			}
+		}
+	}
+
+	return retVal
+}
+
+// IsOpen reports whether the threat still needs mitigation.
+func (t *Threat) IsOpen() bool {
+	return !strings.EqualFold(t.Status, ThreatStatusMitigated) && !strings.EqualFold(t.Status, ThreatStatusNotApplicable)
+}
+
+// threatMarker returns the marker identifying the threat in the description
+// of a task or the source of a risk.
+func threatMarker(key string) string {
+	return "[threat:" + key + "]"
+}
+
+// TaskItemFromThreat converts a threat to a mitigation task.
+func TaskItemFromThreat(mt *ModelThreat) *TaskItem {
+
+	var desc []string
+
+	if mt.Description != "" {
+		desc = append(desc, mt.Description)
+	}
+
+	if mt.Mitigation != "" {
+		desc = append(desc, "Mitigation: "+mt.Mitigation)
+	}
+
+	desc = append(desc, fmt.Sprintf("Diagram: %s\nElement: %s\nType: %s\nSeverity: %s", mt.Diagram, mt.Cell, mt.Type, mt.Severity))
+	desc = append(desc, threatMarker(mt.Key))
+
+	return &TaskItem{
+		Content:     mt.Cell + ": " + mt.Title,
+		Description: strings.Join(desc, "\n\n"),
+		Priority:    threatPriority(mt.Severity),
+	}
+}
+
+// RiskFromThreat converts a threat to a risk.  Probability and impact are
+// derived from the severity of the threat.
+func RiskFromThreat(mt *ModelThreat) *Risk {
+
+	level := threatLevel(mt.Severity)
+
+	status := RiskStatusOpen
+	if !mt.IsOpen() {
+		status = RiskStatusClosed
+	}
+
+	return &Risk{
+		Source:         mt.Cell + ": " + mt.Title + " " + threatMarker(mt.Key),
+		Status:         status,
+		Probability:    level,
+		Impact:         level,
+		MitigationPlan: mt.Mitigation,
+	}
+}
+
+// ImportThreatModel creates or updates a mitigation task, and optionally a
+// risk, in a project for each threat of a model.  Tasks and risks are matched
+// to threats by a marker holding the threat key, so imports can be repeated.
+// Tasks are completed when their threat is mitigated or not applicable, and
+// reopened otherwise.
+func (conn *Connection) ImportThreatModel(projectID string, model *ThreatModel, opts ThreatImportOptions) (*ThreatImportReport, error) {
+
+	if model == nil {
+		return nil, fmt.Errorf("missing required parameter(s): model")
+	}
+
+	listName := opts.TaskListName
+	if listName == "" {
+		listName = "Threat mitigations: " + model.Summary.Title
+	}
+
+	report := new(ThreatImportReport)
+
+	list, err := conn.GetTaskListByName(projectID, listName)
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []*Task
+
+	if list != nil {
+		report.TaskListID = list.ID
+
+		tasks, err = conn.GetTasksByTaskList(list.ID)
+		if err != nil {
+			return nil, err
+		}
+	} else if !opts.DryRun {
+		report.TaskListID, err = conn.PostTaskList(projectID, &TaskList{Name: listName, Description: model.Summary.Description})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var risks []*Risk
+
+	if opts.CreateRisks {
+		risks, err = conn.GetRisks(projectID, "")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, mt := range model.Threats() {
+
+		action, err := conn.importThreatTask(report.TaskListID, mt, tasks, opts.DryRun)
+		if err != nil {
+			return report, fmt.Errorf("failed to import threat (%s): %s", mt.Key, err)
+		}
+		report.Changes = append(report.Changes, &ThreatImportChange{Key: mt.Key, Title: mt.Title, Resource: "task", Action: action})
+
+		if !opts.CreateRisks {
+			continue
+		}
+
+		action, err = conn.importThreatRisk(projectID, mt, risks, opts.DryRun)
+		if err != nil {
+			return report, fmt.Errorf("failed to import threat (%s): %s", mt.Key, err)
+		}
+		report.Changes = append(report.Changes, &ThreatImportChange{Key: mt.Key, Title: mt.Title, Resource: "risk", Action: action})
+	}
+
+	return report, nil
+}
+
+func (conn *Connection) importThreatTask(taskListID string, mt *ModelThreat, tasks []*Task, dryRun bool) (string, error) {
+
+	item := TaskItemFromThreat(mt)
+	marker := threatMarker(mt.Key)
+
+	var existing *Task
+
+	for _, t := range tasks {
+		if strings.Contains(t.Description, marker) {
+			existing = t
+			break
+		}
+	}
+
+	if existing == nil {
+
+		if !dryRun {
+			taskID, err := conn.PostTaskItem(taskListID, item)
+			if err != nil {
+				return "", err
+			}
+
+			if !mt.IsOpen() {
+				err = conn.CompleteTask(taskID)
+				if err != nil {
+					return "", err
+				}
+			}
+		}
+
+		return "created", nil
+	}
+
+	taskID := fmt.Sprint(existing.ID)
+	completed := existing.Status == "completed"
+
+	action := "unchanged"
+
+	if existing.Title != item.Content || existing.Description != item.Description || existing.Priority != item.Priority {
+		action = "updated"
+
+		if !dryRun {
+			err := conn.PutTaskItem(taskID, item)
+			if err != nil {
+				return "", err
+			}
+		}
+	}
+
+	switch {
+	case completed && mt.IsOpen():
+		action = "reopened"
+		if !dryRun {
+			return action, conn.UncompleteTask(taskID)
+		}
+	case !completed && !mt.IsOpen():
+		action = "completed"
+		if !dryRun {
+			return action, conn.CompleteTask(taskID)
+		}
+	}
+
+	return action, nil
+}
+
+func (conn *Connection) importThreatRisk(projectID string, mt *ModelThreat, risks []*Risk, dryRun bool) (string, error) {
+
+	risk := RiskFromThreat(mt)
+	marker := threatMarker(mt.Key)
+
+	var existing *Risk
+
+	for _, r := range risks {
+		if strings.Contains(r.Source, marker) {
+			existing = r
+			break
+		}
+	}
+
+	if existing == nil {
+
+		if !dryRun {
+			_, err := conn.PostRisk(projectID, risk)
+			if err != nil {
+				return "", err
+			}
+		}
+
+		return "created", nil
+	}
+
+	if existing.Source == risk.Source && existing.Status == risk.Status && existing.Probability == risk.Probability &&
+		existing.Impact == risk.Impact && existing.MitigationPlan == risk.MitigationPlan {
+		return "unchanged", nil
+	}
+
+	action := "updated"
+	if existing.Status != RiskStatusClosed && risk.Status == RiskStatusClosed {
+		action = "closed"
+	}
+
+	if !dryRun {
+		update := *existing
+		update.Source = risk.Source
+		update.Status = risk.Status
+		update.Probability = risk.Probability
+		update.Impact = risk.Impact
+		update.MitigationPlan = risk.MitigationPlan
+
+		err := conn.PutRisk(&update)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return action, nil
+}
+
+// threatLevel maps a Threat Dragon severity to a risk level.
+func threatLevel(severity string) int {
+
+	switch strings.ToLower(severity) {
+	case "critical":
+		return RiskLevelVeryHigh
+	case "high":
+		return RiskLevelHigh
+	case "low":
+		return RiskLevelLow
+	}
+
+	return RiskLevelMedium
+}
+
+// threatPriority maps a Threat Dragon severity to a task priority.
+func threatPriority(severity string) string {
+
+	switch strings.ToLower(severity) {
+	case "critical", "high":
+		return "high"
+	case "low":
+		return "low"
+	}
+
+	return "medium"
+}
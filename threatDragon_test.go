@@ -0,0 +1,210 @@
+package teamworkapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const threatModelV1 = `{
+  "summary": {"title": "Shop", "owner": "Hunter"},
+  "detail": {
+    "contributors": [],
+    "reviewer": "Hunter",
+    "diagrams": [{
+      "id": 0,
+      "title": "Checkout",
+      "diagramType": "STRIDE",
+      "diagramJson": {"cells": [
+        {"type": "tm.Process", "id": "p1", "attrs": {"text": {"text": "Web App"}},
+          "threats": [
+            {"title": "Session hijack", "status": "Open", "severity": "High", "type": "Spoofing", "description": "Cookies stolen", "mitigation": "Secure cookies"},
+            {"title": "Log tampering", "status": "Mitigated", "severity": "Low", "type": "Repudiation"}
+          ]},
+        {"type": "tm.Flow", "id": "f1", "labels": [{"attrs": {"text": {"text": "Card data"}}}],
+          "threats": [{"title": "Sniffing", "status": "Open", "severity": "Medium"}]},
+        {"type": "tm.Store", "id": "s1", "outOfScope": true, "attrs": {"text": {"text": "Legacy DB"}},
+          "threats": [{"title": "Ignored", "status": "Open"}]}
+      ]}
+    }]
+  }
+}`
+
+const threatModelV2 = `{
+  "summary": {"title": "Shop"},
+  "detail": {
+    "diagrams": [{
+      "id": 1,
+      "title": "Payments",
+      "cells": [
+        {"shape": "store", "id": "s2", "data": {"name": "Orders DB",
+          "threats": [{"id": "6f2e", "title": "SQL injection", "status": "NotApplicable", "severity": "Critical"}]}}
+      ]
+    }]
+  }
+}`
+
+func TestReadThreatModel(t *testing.T) {
+
+	model, err := ReadThreatModelFile("ThreatDragonModels/Test/Test.json")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if model.Summary.Title != "Test" || len(model.Threats()) != 0 {
+		t.Errorf("expected empty model titled (Test) but got (%s)", model.Summary.Title)
+	}
+
+	var tests = []struct {
+		model string
+		want  string
+	}{
+		{threatModelV1, "p1/session hijack|Web App|Checkout,p1/log tampering|Web App|Checkout,f1/sniffing|Card data|Checkout"},
+		{threatModelV2, "6f2e|Orders DB|Payments"},
+	}
+
+	for _, v := range tests {
+
+		model, err := ReadThreatModel(strings.NewReader(v.model))
+		if err != nil {
+			t.Errorf(err.Error())
+			continue
+		}
+
+		var got []string
+		for _, mt := range model.Threats() {
+			got = append(got, mt.Key+"|"+mt.Cell+"|"+mt.Diagram)
+		}
+
+		if strings.Join(got, ",") != v.want {
+			t.Errorf("expected threats (%s) but got (%s)", v.want, strings.Join(got, ","))
+		}
+	}
+
+	_, err = ReadThreatModel(strings.NewReader("{"))
+	if err == nil || !strings.HasPrefix(err.Error(), "failed to parse threat model") {
+		t.Errorf("expected parse error but got (%v)", err)
+	}
+}
+
+func TestThreatConversions(t *testing.T) {
+
+	model, _ := ReadThreatModel(strings.NewReader(threatModelV1))
+	threats := model.Threats()
+
+	item := TaskItemFromThreat(threats[0])
+
+	if item.Content != "Web App: Session hijack" || item.Priority != "high" ||
+		!strings.Contains(item.Description, "Mitigation: Secure cookies") || !strings.HasSuffix(item.Description, "[threat:p1/session hijack]") {
+		t.Errorf("unexpected task: %+v", item)
+	}
+
+	var tests = []struct {
+		threat      *ModelThreat
+		status      string
+		probability int
+	}{
+		{threats[0], RiskStatusOpen, RiskLevelHigh},
+		{threats[1], RiskStatusClosed, RiskLevelLow},
+		{threats[2], RiskStatusOpen, RiskLevelMedium},
+	}
+
+	for _, v := range tests {
+
+		r := RiskFromThreat(v.threat)
+
+		if r.Status != v.status || r.Probability != v.probability || r.Impact != v.probability || validateRisk(r) != nil {
+			t.Errorf("expected %s risk at level %d but got %+v", v.status, v.probability, r)
+		}
+	}
+}
+
+func TestImportThreatModel(t *testing.T) {
+
+	var calls []string
+	var postedTasks []*TaskItem
+	var postedRisks, putRisks []*Risk
+
+	conn := initMockConnection(t, func(w http.ResponseWriter, r *http.Request) {
+
+		raw, _ := ioutil.ReadAll(r.Body)
+
+		if r.Method != http.MethodGet {
+			calls = append(calls, r.Method+" "+r.URL.Path)
+		}
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/projects/1/tasklists.json":
+			fmt.Fprint(w, `{"STATUS": "OK", "tasklists": [{"id": "30", "name": "threat mitigations: shop"}]}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/tasklists/30/tasks.json":
+			// session hijack is up to date but completed, sniffing has an old title
+			item := TaskItemFromThreat(&ModelThreat{Threat: &Threat{Title: "Session hijack", Status: "Open", Severity: "High", Type: "Spoofing",
+				Description: "Cookies stolen", Mitigation: "Secure cookies"}, Key: "p1/session hijack", Diagram: "Checkout", Cell: "Web App"})
+			tasks := TasksJSON{Tasks: []*Task{
+				{ID: 40, Title: item.Content, Description: item.Description, Priority: item.Priority, Status: "completed"},
+				{ID: 41, Title: "Old title", Description: "[threat:f1/sniffing]", Priority: "medium"},
+			}}
+			json.NewEncoder(w).Encode(tasks)
+		case r.Method == http.MethodGet && r.URL.Path == "/projects/1/risks.json":
+			fmt.Fprint(w, `{"STATUS": "OK", "risks": [{"id": "50", "source": "Web App: Log tampering [threat:p1/log tampering]", "status": "open", "probability": 2, "impact": 2}]}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/tasklists/30/tasks.json":
+			item := new(TaskItemJSON)
+			json.Unmarshal(raw, &item)
+			postedTasks = append(postedTasks, item.Item)
+			fmt.Fprint(w, `{"STATUS": "OK", "id": "42"}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/projects/1/risks.json":
+			risk := new(RiskJSON)
+			json.Unmarshal(raw, &risk)
+			postedRisks = append(postedRisks, risk.Risk)
+			fmt.Fprintf(w, `{"STATUS": "OK", "riskId": "%d"}`, 60+len(postedRisks))
+		case r.Method == http.MethodPut && r.URL.Path == "/risks/50.json":
+			risk := new(RiskJSON)
+			json.Unmarshal(raw, &risk)
+			putRisks = append(putRisks, risk.Risk)
+			fmt.Fprint(w, `{"STATUS": "OK"}`)
+		default:
+			fmt.Fprint(w, `{"STATUS": "OK"}`)
+		}
+	})
+
+	model, _ := ReadThreatModel(strings.NewReader(threatModelV1))
+
+	report, err := conn.ImportThreatModel("1", model, ThreatImportOptions{CreateRisks: true, DryRun: true})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if len(calls) != 0 {
+		t.Errorf("expected no changes in a dry run but got (%s)", strings.Join(calls, ","))
+	}
+
+	report, err = conn.ImportThreatModel("1", model, ThreatImportOptions{CreateRisks: true})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	var got []string
+	for _, c := range report.Changes {
+		got = append(got, c.Resource+":"+c.Action)
+	}
+
+	want := "task:reopened,risk:created,task:created,risk:closed,task:updated,risk:created"
+
+	if strings.Join(got, ",") != want || report.TaskListID != "30" || report.Count("risk", "created") != 2 {
+		t.Errorf("expected changes (%s) but got (%s)", want, strings.Join(got, ","))
+	}
+
+	wantCalls := "PUT /tasks/40/uncomplete.json,POST /projects/1/risks.json,POST /tasklists/30/tasks.json,PUT /tasks/42/complete.json," +
+		"PUT /risks/50.json,PUT /tasks/41.json,POST /projects/1/risks.json"
+
+	if strings.Join(calls, ",") != wantCalls {
+		t.Errorf("expected calls (%s) but got (%s)", wantCalls, strings.Join(calls, ","))
+	}
+
+	if len(postedTasks) != 1 || postedTasks[0].Content != "Web App: Log tampering" || len(putRisks) != 1 || putRisks[0].Status != RiskStatusClosed {
+		t.Errorf("unexpected tasks (%+v) or risks (%+v)", postedTasks, putRisks)
+	}
+}
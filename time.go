@@ -6,20 +6,10 @@ import (
 	"math"
 	"net/http"
 	"strconv"
-	"time"
 
 	"github.com/google/go-querystring/query"
 )
 
-// TeamworkDateFormatShort is the short-form of a date used by Teamwork.
-const TeamworkDateFormatShort = "20060102"
-
-// TeamworkDateFormatMed is the medium-form of a date/time used by Teamwork.
-const TeamworkDateFormatMed = "2006-01-02T15:04"
-
-// TeamworkDateFormatLong is the long-form of a date/time used by Teamwork.
-const TeamworkDateFormatLong = "2006-01-02T15:04:05Z"
-
 // TimeEntry models an individual time entry.
 type TimeEntry struct {
 	ID          string `json:"id"`
@@ -33,6 +23,7 @@ type TimeEntry struct {
 	IsBillable  string `json:"isbillable"`
 	ProjectID   string `json:"project-id"`
 	TaskID      string `json:"todo-item-id"`
+	Tags        []Tag  `json:"tags,omitempty"`
 }
 
 // TimeEntryJSON provides a wrapper around TimeEntry to properly marshal json
@@ -74,14 +65,18 @@ type TimeQueryParamsV3 struct {
 	AssignedToUserIds  []string `url:"assignedToUserIds,omitempty"`
 	ProjectID          string `url:"projectId,omitempty"`
 	PageSize 		   string `url:"pageSize,omitempty"`
+	Page               string `url:"page,omitempty"`
 }
 
 type TimeLogV3 struct {
+	ID         int     `json:"id"`
 	UserId     int     `json:"userId"`
 	Minutes    int     `json:"minutes"`
 	TaskID     int     `json:"taskId"`
 	ProjectID  int     `json:"projectId"`
 	TimeLogged string  `json:"timeLogged"` //Date?
+	IsBillable bool    `json:"isBillable"`
+	TagIDs     []int   `json:"tagIds"`
 }
 
 type TimeLogJSON struct {
@@ -141,18 +136,14 @@ func (resMsg *TimeResponseHandler) ParseResponse(httpMethod string, rawRes []byt
 // FormatQueryParams formats query parameters for this resource.
 func (qp *TimeQueryParams) FormatQueryParams() (string, error) {
 
-	if qp.FromDate != "" {
-		_, err := time.Parse("20060102", qp.FromDate)
-		if err != nil {
-			return "", fmt.Errorf("invalid format for FromDate parameter.  Should be YYYYMMDD, but found %s", qp.FromDate)
-		}
+	err := validateDateParam("FromDate", qp.FromDate, TeamworkDateFormatShort)
+	if err != nil {
+		return "", err
 	}
 
-	if qp.ToDate != "" {
-		_, err := time.Parse("20060102", qp.ToDate)
-		if err != nil {
-			return "", fmt.Errorf("invalid format for ToDate parameter.  Should be YYYYMMDD, but found %s", qp.ToDate)
-		}
+	err = validateDateParam("ToDate", qp.ToDate, TeamworkDateFormatShort)
+	if err != nil {
+		return "", err
 	}
 
 	s, err := query.Values(qp)
@@ -164,12 +155,16 @@ func (qp *TimeQueryParams) FormatQueryParams() (string, error) {
 }
 func (qp *TimeQueryParamsV3) FormatQueryParamsV3() (string, error) {
 
-	if qp.EndDate != "" {
-		_, err := time.Parse("2006-01-02", qp.EndDate)
-		if err != nil {
-			return "", fmt.Errorf("invalid format for FromDate parameter.  Should be YYYY-MM-DD, but found %s", qp.EndDate)
-		}
+	err := validateDateParam("StartDate", qp.StartDate, TeamworkDateFormatV3)
+	if err != nil {
+		return "", err
 	}
+
+	err = validateDateParam("EndDate", qp.EndDate, TeamworkDateFormatV3)
+	if err != nil {
+		return "", err
+	}
+
 	s, err := query.Values(qp)
 	if err != nil {
 		return "", err
@@ -208,6 +203,40 @@ func (conn *Connection) GetTimeEntriesV3(queryParams *TimeQueryParamsV3) ([]*Tim
 	return entries.TimeLog, nil
 }
 
+// GetAllTimeEntriesV3 retrieves every page of time logs specified by
+// queryParams.  The Page parameter is ignored.
+func (conn *Connection) GetAllTimeEntriesV3(queryParams *TimeQueryParamsV3) ([]*TimeLogV3, error) {
+
+	qp := new(TimeQueryParamsV3)
+	if queryParams != nil {
+		*qp = *queryParams
+	}
+
+	var retVal []*TimeLogV3
+
+	err := conn.getPagesV3("time", func(page int) QueryParamsV3 {
+		qp.Page = strconv.Itoa(page)
+		return qp
+	}, func(data []byte) error {
+
+		entries := new(TimeLogJSON)
+
+		err := json.Unmarshal(data, &entries)
+		if err != nil {
+			return err
+		}
+
+		retVal = append(retVal, entries.TimeLog...)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return retVal, nil
+}
+
 // GetTimeEntriesByTask retrieves all time entries for the specified Task.
 func (conn *Connection) GetTimeEntriesByTask(ID string) ([]*TimeEntry, error) {
 
@@ -379,19 +408,3 @@ func TotalAndAvgHours(e []*TimeEntry) (map[string]float64, error) {
 
 	return retVal, nil
 }
-
-// DurationInDays gets the number of days between the specified dates.
-func DurationInDays(from string, to string) (int, error) {
-
-	start, err := time.Parse(TeamworkDateFormatShort, from)
-	if err != nil {
-		return 0, err
-	}
-
-	end, err := time.Parse(TeamworkDateFormatShort, to)
-	if err != nil {
-		return 0, err
-	}
-
-	return int(end.Sub(start).Hours() / 24), nil
-}
@@ -0,0 +1,323 @@
+package teamworkapi
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimesheetDimension identifies an attribute used to group time entries.
+type TimesheetDimension string
+
+// Supported timesheet dimensions.
+const (
+	DimensionPerson   TimesheetDimension = "person"
+	DimensionProject  TimesheetDimension = "project"
+	DimensionTask     TimesheetDimension = "task"
+	DimensionTag      TimesheetDimension = "tag"
+	DimensionBillable TimesheetDimension = "billable"
+	DimensionDay      TimesheetDimension = "day"
+	DimensionWeek     TimesheetDimension = "week"
+	DimensionMonth    TimesheetDimension = "month"
+)
+
+// TimesheetNone is the key used when an entry has no value for a dimension
+// (e.g. an entry without tags or task).
+const TimesheetNone = "(none)"
+
+// TimesheetEntry is a source-independent representation of logged time, built
+// from either v1 TimeEntry or v3 TimeLogV3 data.
+type TimesheetEntry struct {
+	PersonID  string
+	ProjectID string
+	TaskID    string
+	Tags      []string
+	Billable  bool
+	Date      time.Time
+	Hours     float64
+}
+
+// TimesheetTable is a pivot table of hours, with rows and columns keyed by the
+// values of two TimesheetDimensions.
+type TimesheetTable struct {
+	RowDimension    TimesheetDimension
+	ColumnDimension TimesheetDimension
+	Rows            []string
+	Columns         []string
+	Cells           map[string]map[string]float64
+	RowTotals       map[string]float64
+	ColumnTotals    map[string]float64
+	Total           float64
+}
+
+// Value returns the hours found at the specified row and column.
+func (tt *TimesheetTable) Value(row string, column string) float64 {
+
+	if r, ok := tt.Cells[row]; ok {
+		return r[column]
+	}
+
+	return 0
+}
+
+// TimesheetEntriesFromV1 converts v1 time entries to TimesheetEntries.
+func TimesheetEntriesFromV1(e []*TimeEntry) ([]*TimesheetEntry, error) {
+
+	retVal := make([]*TimesheetEntry, 0, len(e))
+
+	for _, v := range e {
+
+		hours, err := strconv.ParseFloat(v.Hours, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert %s to float64", v.Hours)
+		}
+
+		if v.Minutes != "" && v.Minutes != "0" {
+			m, err := strconv.ParseFloat(v.Minutes, 64)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert %s to float64", v.Minutes)
+			}
+			hours += m / 60
+		}
+
+		date, err := ParseTeamworkDate(v.Date)
+		if err != nil {
+			return nil, err
+		}
+
+		entry := &TimesheetEntry{
+			PersonID:  v.PersonID,
+			ProjectID: v.ProjectID,
+			TaskID:    v.TaskID,
+			Billable:  v.IsBillable == "1" || strings.EqualFold(v.IsBillable, "true"),
+			Date:      date,
+			Hours:     hours,
+		}
+
+		for _, tag := range v.Tags {
+			entry.Tags = append(entry.Tags, tag.Name)
+		}
+
+		retVal = append(retVal, entry)
+	}
+
+	return retVal, nil
+}
+
+// TimesheetEntriesFromV3 converts v3 time logs to TimesheetEntries.  The v3
+// time log only references tags by ID, so they are resolved to names using
+// tags, matching the entries built by TimesheetEntriesFromV1.  A tag that is
+// not found in tags is identified by its ID.
+func TimesheetEntriesFromV3(e []*TimeLogV3, tags []*Tag) ([]*TimesheetEntry, error) {
+
+	names := make(map[string]string, len(tags))
+
+	for _, t := range tags {
+		id, err := t.ID()
+		if err != nil {
+			return nil, err
+		}
+		names[id] = t.Name
+	}
+
+	retVal := make([]*TimesheetEntry, 0, len(e))
+
+	for _, v := range e {
+
+		date, err := ParseTeamworkDate(v.TimeLogged)
+		if err != nil {
+			return nil, err
+		}
+
+		entry := &TimesheetEntry{
+			PersonID:  strconv.Itoa(v.UserId),
+			ProjectID: strconv.Itoa(v.ProjectID),
+			Billable:  v.IsBillable,
+			Date:      date,
+			Hours:     float64(v.Minutes) / 60,
+		}
+
+		if v.TaskID != 0 {
+			entry.TaskID = strconv.Itoa(v.TaskID)
+		}
+
+		for _, id := range v.TagIDs {
+			tag := strconv.Itoa(id)
+			if name, ok := names[tag]; ok {
+				tag = name
+			}
+			entry.Tags = append(entry.Tags, tag)
+		}
+
+		retVal = append(retVal, entry)
+	}
+
+	return retVal, nil
+}
+
+// AggregateTimesheet builds a pivot table of hours from entries, grouped by
+// the row and column dimensions.  An entry with several tags contributes its
+// hours to each tag, so tag totals may exceed the overall total, which is
+// always the sum of the entries' hours.
+func AggregateTimesheet(entries []*TimesheetEntry, rowDim TimesheetDimension, colDim TimesheetDimension) (*TimesheetTable, error) {
+
+	for _, d := range []TimesheetDimension{rowDim, colDim} {
+		if !validTimesheetDimension(d) {
+			return nil, fmt.Errorf("invalid timesheet dimension (%s)", d)
+		}
+	}
+
+	tt := &TimesheetTable{
+		RowDimension:    rowDim,
+		ColumnDimension: colDim,
+		Cells:           make(map[string]map[string]float64),
+		RowTotals:       make(map[string]float64),
+		ColumnTotals:    make(map[string]float64),
+	}
+
+	for _, e := range entries {
+
+		rows := e.keys(rowDim)
+		cols := e.keys(colDim)
+
+		for _, r := range rows {
+			if _, ok := tt.Cells[r]; !ok {
+				tt.Cells[r] = make(map[string]float64)
+			}
+
+			for _, c := range cols {
+				tt.Cells[r][c] += e.Hours
+			}
+
+			tt.RowTotals[r] += e.Hours
+		}
+
+		for _, c := range cols {
+			tt.ColumnTotals[c] += e.Hours
+		}
+
+		tt.Total += e.Hours
+	}
+
+	for r, cols := range tt.Cells {
+		tt.Rows = append(tt.Rows, r)
+		for c := range cols {
+			cols[c] = roundHours(cols[c])
+		}
+	}
+
+	for c := range tt.ColumnTotals {
+		tt.Columns = append(tt.Columns, c)
+		tt.ColumnTotals[c] = roundHours(tt.ColumnTotals[c])
+	}
+
+	for r := range tt.RowTotals {
+		tt.RowTotals[r] = roundHours(tt.RowTotals[r])
+	}
+
+	tt.Total = roundHours(tt.Total)
+
+	sort.Strings(tt.Rows)
+	sort.Strings(tt.Columns)
+
+	return tt, nil
+}
+
+// keys returns the value(s) of the entry for the specified dimension.
+func (e *TimesheetEntry) keys(d TimesheetDimension) []string {
+
+	switch d {
+	case DimensionPerson:
+		return []string{noneIfEmpty(e.PersonID)}
+	case DimensionProject:
+		return []string{noneIfEmpty(e.ProjectID)}
+	case DimensionTask:
+		return []string{noneIfEmpty(e.TaskID)}
+	case DimensionTag:
+		if len(e.Tags) == 0 {
+			return []string{TimesheetNone}
+		}
+		return e.Tags
+	case DimensionBillable:
+		if e.Billable {
+			return []string{"billable"}
+		}
+		return []string{"non-billable"}
+	case DimensionDay:
+		return []string{e.Date.Format("2006-01-02")}
+	case DimensionWeek:
+		y, w := e.Date.ISOWeek()
+		return []string{fmt.Sprintf("%04d-W%02d", y, w)}
+	case DimensionMonth:
+		return []string{e.Date.Format("2006-01")}
+	}
+
+	return []string{TimesheetNone}
+}
+
+func validTimesheetDimension(d TimesheetDimension) bool {
+
+	switch d {
+	case DimensionPerson, DimensionProject, DimensionTask, DimensionTag,
+		DimensionBillable, DimensionDay, DimensionWeek, DimensionMonth:
+		return true
+	}
+
+	return false
+}
+
+func noneIfEmpty(s string) string {
+
+	if s == "" || s == "0" {
+		return TimesheetNone
+	}
+
+	return s
+}
+
+func roundHours(h float64) float64 {
+	return math.Round(h*100) / 100
+}
+
+// GetTimesheet retrieves v1 time entries specified by queryParams and
+// aggregates them by the row and column dimensions.
+func (conn *Connection) GetTimesheet(queryParams *TimeQueryParams, rowDim TimesheetDimension, colDim TimesheetDimension) (*TimesheetTable, error) {
+
+	raw, err := conn.GetTimeEntries(queryParams)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := TimesheetEntriesFromV1(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return AggregateTimesheet(entries, rowDim, colDim)
+}
+
+// GetTimesheetV3 retrieves v3 time logs specified by queryParams and
+// aggregates them by the row and column dimensions.  Tags are reported by name,
+// as in GetTimesheet.
+func (conn *Connection) GetTimesheetV3(queryParams *TimeQueryParamsV3, rowDim TimesheetDimension, colDim TimesheetDimension) (*TimesheetTable, error) {
+
+	raw, err := conn.v3().GetTimeEntriesV3(queryParams)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := conn.v1().GetTags()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := TimesheetEntriesFromV3(raw, tags)
+	if err != nil {
+		return nil, err
+	}
+
+	return AggregateTimesheet(entries, rowDim, colDim)
+}
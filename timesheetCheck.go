@@ -0,0 +1,301 @@
+package teamworkapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TimesheetAnomalyType identifies the kind of issue found in a timesheet.
+type TimesheetAnomalyType string
+
+// Supported timesheet anomaly types.
+const (
+	AnomalyMissingTime       TimesheetAnomalyType = "missing-time"
+	AnomalyExcessiveTime     TimesheetAnomalyType = "excessive-time"
+	AnomalyWeekendTime       TimesheetAnomalyType = "weekend-time"
+	AnomalyCompletedTaskTime TimesheetAnomalyType = "completed-task-time"
+	AnomalyTaskLookupFailed  TimesheetAnomalyType = "task-lookup-failed"
+)
+
+// DefaultMaxDailyHours is the daily threshold above which logged time is
+// reported as excessive.
+const DefaultMaxDailyHours = 10.0
+
+// DefaultLeaveEventTypes lists the calendar event type names treated as
+// excused absences when no other types are configured.
+var DefaultLeaveEventTypes = []string{"Leave", "Holiday", "Vacation", "Sick", "Out of Office"}
+
+// TimesheetCheckConfig configures the timesheet checker.  Zero values are
+// replaced with defaults.  Calendar determines the weekend and holidays; a nil
+// Calendar treats Saturday and Sunday as the weekend.
+type TimesheetCheckConfig struct {
+	MaxDailyHours   float64
+	LeaveEventTypes []string
+	Calendar        *BusinessCalendar
+}
+
+// TimesheetAnomaly describes an individual issue found in a person's
+// timesheet.
+type TimesheetAnomaly struct {
+	PersonID string
+	Name     string
+	Date     string // expected format is YYYYMMDD
+	Type     TimesheetAnomalyType
+	Hours    float64
+	TaskID   string
+	Message  string
+}
+
+// CheckTimesheet inspects the time entries of a single person between from and
+// to (inclusive) and reports business days without logged time, days over the
+// configured threshold, weekend logging and time logged against completed
+// tasks.  Holidays of the configured calendar, and days covered by a leave
+// event the person attends, are excused.  completedTasks maps task IDs to
+// tasks known to be completed.
+func CheckTimesheet(person *Person, entries []*TimeEntry, events []*CalendarEvent, completedTasks map[string]*Task, from time.Time, to time.Time, conf *TimesheetCheckConfig) ([]*TimesheetAnomaly, error) {
+
+	if person == nil {
+		return nil, fmt.Errorf("missing required parameter(s): person")
+	}
+
+	conf = conf.withDefaults()
+	name := strings.TrimSpace(person.FirstName + " " + person.LastName)
+
+	sheet, err := TimesheetEntriesFromV1(entries)
+	if err != nil {
+		return nil, err
+	}
+
+	daily := make(map[string]float64)
+	for _, e := range sheet {
+		daily[e.Date.Format(TeamworkDateFormatShort)] += e.Hours
+	}
+
+	excused := conf.excusedDays(person.ID, events)
+
+	var anomalies []*TimesheetAnomaly
+
+	for d := dateOf(from); !d.After(dateOf(to)); d = d.AddDate(0, 0, 1) {
+
+		key := d.Format(TeamworkDateFormatShort)
+		hours := roundHours(daily[key])
+		weekend := conf.Calendar.IsWeekend(d)
+		_, holiday := conf.Calendar.Holiday(d)
+
+		switch {
+		case weekend && hours > 0:
+			anomalies = append(anomalies, &TimesheetAnomaly{
+				PersonID: person.ID, Name: name, Date: key, Type: AnomalyWeekendTime, Hours: hours,
+				Message: fmt.Sprintf("%.2f hour(s) logged on a weekend", hours),
+			})
+		case !weekend && !holiday && hours == 0 && !excused[key]:
+			anomalies = append(anomalies, &TimesheetAnomaly{
+				PersonID: person.ID, Name: name, Date: key, Type: AnomalyMissingTime,
+				Message: "no time logged on a workday",
+			})
+		}
+
+		if hours > conf.MaxDailyHours {
+			anomalies = append(anomalies, &TimesheetAnomaly{
+				PersonID: person.ID, Name: name, Date: key, Type: AnomalyExcessiveTime, Hours: hours,
+				Message: fmt.Sprintf("%.2f hour(s) logged, exceeding threshold of %.2f", hours, conf.MaxDailyHours),
+			})
+		}
+	}
+
+	for _, e := range sheet {
+
+		task, ok := completedTasks[e.TaskID]
+		if !ok || e.TaskID == "" {
+			continue
+		}
+
+		completed, err := ParseTeamworkDate(task.CompletedOn)
+		if err == nil && !dateOf(e.Date).After(dateOf(completed)) {
+			continue
+		}
+
+		anomalies = append(anomalies, &TimesheetAnomaly{
+			PersonID: person.ID, Name: name, Date: e.Date.Format(TeamworkDateFormatShort),
+			Type: AnomalyCompletedTaskTime, Hours: roundHours(e.Hours), TaskID: e.TaskID,
+			Message: fmt.Sprintf("time logged against completed task (%s)", e.TaskID),
+		})
+	}
+
+	sort.SliceStable(anomalies, func(i, j int) bool {
+		return anomalies[i].Date < anomalies[j].Date
+	})
+
+	return anomalies, nil
+}
+
+// CheckTimesheetsByCompany runs CheckTimesheet for every person in the
+// specified company between fromDate and toDate (YYYYMMDD).  Tasks that cannot
+// be retrieved are reported as AnomalyTaskLookupFailed against the entry.
+func (conn *Connection) CheckTimesheetsByCompany(companyID string, fromDate string, toDate string, conf *TimesheetCheckConfig) ([]*TimesheetAnomaly, error) {
+
+	from, to, err := parseReportPeriod(fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+
+	people, err := conn.GetPeopleByCompany(companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := conn.GetCalendarEvents(CalendarEventQueryParams{
+		From: fromDate,
+		To:   toDate,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	events, err = ExpandCalendarEvents(events, from, endOfDay(to))
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make(map[string]*Task)
+	failed := make(map[string]error)
+
+	var anomalies []*TimesheetAnomaly
+
+	for _, p := range people {
+
+		entries, err := conn.GetTimeEntriesByPerson(p.ID, fromDate, toDate)
+		if err != nil {
+			return nil, err
+		}
+
+		completed := make(map[string]*Task)
+
+		for _, e := range entries {
+			if e.TaskID == "" || e.TaskID == "0" {
+				continue
+			}
+
+			t, ok := tasks[e.TaskID]
+			if !ok {
+				lookupErr, seen := failed[e.TaskID]
+				if !seen {
+					t, lookupErr = conn.GetTaskByID(e.TaskID)
+				}
+
+				if lookupErr != nil {
+					failed[e.TaskID] = lookupErr
+
+					date := e.Date
+					if d, err := ParseTeamworkDate(e.Date); err == nil {
+						date = d.Format(TeamworkDateFormatShort)
+					}
+
+					anomalies = append(anomalies, &TimesheetAnomaly{
+						PersonID: p.ID, Name: strings.TrimSpace(p.FirstName + " " + p.LastName), Date: date,
+						Type: AnomalyTaskLookupFailed, TaskID: e.TaskID,
+						Message: fmt.Sprintf("failed to retrieve task (%s): %s", e.TaskID, lookupErr),
+					})
+					continue
+				}
+				tasks[e.TaskID] = t
+			}
+
+			if t.Status == "completed" {
+				completed[e.TaskID] = t
+			}
+		}
+
+		a, err := CheckTimesheet(p, entries, events, completed, from, to, conf)
+		if err != nil {
+			return nil, err
+		}
+
+		anomalies = append(anomalies, a...)
+	}
+
+	return anomalies, nil
+}
+
+func (conf *TimesheetCheckConfig) withDefaults() *TimesheetCheckConfig {
+
+	c := TimesheetCheckConfig{}
+	if conf != nil {
+		c = *conf
+	}
+
+	if c.MaxDailyHours <= 0 {
+		c.MaxDailyHours = DefaultMaxDailyHours
+	}
+
+	if len(c.LeaveEventTypes) == 0 {
+		c.LeaveEventTypes = DefaultLeaveEventTypes
+	}
+
+	return &c
+}
+
+// isLeave determines if the event type is one of the configured leave types.
+// Types may be configured by name or ID.
+func (conf *TimesheetCheckConfig) isLeave(t *EventType) bool {
+
+	if t == nil {
+		return false
+	}
+
+	for _, l := range conf.LeaveEventTypes {
+		if strings.EqualFold(l, t.Name) || l == t.ID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// excusedDays returns the days (YYYYMMDD) covered by leave events attended by
+// personID.  Leave events without attendees (e.g. company holidays) apply to
+// everyone.
+func (conf *TimesheetCheckConfig) excusedDays(personID string, events []*CalendarEvent) map[string]bool {
+
+	days := make(map[string]bool)
+
+	for _, e := range events {
+
+		if !conf.isLeave(e.Type) || !attendsEvent(personID, e.AttendeeIDs) {
+			continue
+		}
+
+		start, err := ParseTeamworkDate(e.Start)
+		if err != nil {
+			continue
+		}
+
+		end, err := ParseTeamworkDate(e.End)
+		if err != nil {
+			end = start
+		}
+
+		for d := dateOf(start); !d.After(dateOf(end)); d = d.AddDate(0, 0, 1) {
+			days[d.Format(TeamworkDateFormatShort)] = true
+		}
+	}
+
+	return days
+}
+
+func attendsEvent(personID string, attendeeIDs string) bool {
+
+	if strings.TrimSpace(attendeeIDs) == "" {
+		return true
+	}
+
+	for _, id := range strings.Split(attendeeIDs, ",") {
+		if strings.TrimSpace(id) == personID {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,156 @@
+package teamworkapi
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCheckTimesheet(t *testing.T) {
+
+	person := &Person{ID: "1", FirstName: "Luke", LastName: "Skywalker"}
+
+	// 2021-03-01 is a Monday
+	entries := []*TimeEntry{
+		{PersonID: "1", TaskID: "100", Hours: "8", Minutes: "0", Date: "20210301"},
+		{PersonID: "1", TaskID: "200", Hours: "11", Minutes: "30", Date: "20210302"},
+		{PersonID: "1", TaskID: "100", Hours: "2", Minutes: "0", Date: "20210306"},
+	}
+
+	events := []*CalendarEvent{
+		{Start: "2021-03-03T00:00", End: "2021-03-03T23:59", Type: &EventType{ID: "1", Name: "Vacation"}, AttendeeIDs: "1,2"},
+		{Start: "2021-03-04T00:00", End: "2021-03-04T23:59", Type: &EventType{ID: "2", Name: "Meeting"}, AttendeeIDs: "1"},
+		{Start: "2021-03-05T00:00", End: "2021-03-05T23:59", Type: &EventType{ID: "1", Name: "Vacation"}, AttendeeIDs: "2"},
+	}
+
+	completed := map[string]*Task{
+		"100": {ID: 100, Status: "completed", CompletedOn: "2021-03-02T12:00:00Z"},
+	}
+
+	from, _ := time.Parse(TeamworkDateFormatShort, "20210301")
+	to, _ := time.Parse(TeamworkDateFormatShort, "20210307")
+
+	anomalies, err := CheckTimesheet(person, entries, events, completed, from, to, nil)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	var tests = []struct {
+		date     string
+		anomaly  TimesheetAnomalyType
+		wantSeen bool
+	}{
+		{"20210301", AnomalyMissingTime, false},
+		{"20210302", AnomalyExcessiveTime, true},
+		{"20210303", AnomalyMissingTime, false},
+		{"20210304", AnomalyMissingTime, true},
+		{"20210305", AnomalyMissingTime, true},
+		{"20210306", AnomalyWeekendTime, true},
+		{"20210306", AnomalyCompletedTaskTime, true},
+		{"20210301", AnomalyCompletedTaskTime, false},
+		{"20210307", AnomalyMissingTime, false},
+	}
+
+	for _, v := range tests {
+
+		seen := false
+		for _, a := range anomalies {
+			if a.Date == v.date && a.Type == v.anomaly {
+				seen = true
+			}
+		}
+
+		if seen != v.wantSeen {
+			t.Errorf("expected %s on %s to be reported (%t) but got (%t)", v.anomaly, v.date, v.wantSeen, seen)
+		}
+	}
+
+	if len(anomalies) != 5 {
+		t.Errorf("expected 5 anomalies but got %d", len(anomalies))
+	}
+
+	anomalies, err = CheckTimesheet(person, entries, nil, nil, from, from, &TimesheetCheckConfig{MaxDailyHours: 6})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if len(anomalies) != 1 || anomalies[0].Type != AnomalyExcessiveTime {
+		t.Errorf("expected a single excessive time anomaly with threshold of 6 hours")
+	}
+}
+
+func TestCheckTimesheetCalendar(t *testing.T) {
+
+	person := &Person{ID: "1"}
+
+	// Friday 2021-03-05 is a weekend day and Monday 2021-03-01 a holiday
+	cal := NewBusinessCalendar()
+	cal.Weekend = []time.Weekday{time.Friday, time.Saturday}
+	cal.AddHoliday(time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC), "Founders Day")
+
+	entries := []*TimeEntry{
+		{PersonID: "1", Hours: "8", Minutes: "0", Date: "20210302"},
+		{PersonID: "1", Hours: "8", Minutes: "0", Date: "20210303"},
+		{PersonID: "1", Hours: "8", Minutes: "0", Date: "20210304"},
+		{PersonID: "1", Hours: "2", Minutes: "0", Date: "20210305"},
+	}
+
+	from, _ := time.Parse(TeamworkDateFormatShort, "20210301")
+	to, _ := time.Parse(TeamworkDateFormatShort, "20210307")
+
+	anomalies, err := CheckTimesheet(person, entries, nil, nil, from, to, &TimesheetCheckConfig{Calendar: cal})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	var got []string
+	for _, a := range anomalies {
+		got = append(got, a.Date+" "+string(a.Type))
+	}
+
+	want := fmt.Sprint([]string{"20210305 weekend-time", "20210307 missing-time"})
+	if fmt.Sprint(got) != want {
+		t.Errorf("expected anomalies %s but got %v", want, got)
+	}
+}
+
+func TestCheckTimesheetsByCompany(t *testing.T) {
+
+	conn := initMockConnection(t, func(w http.ResponseWriter, r *http.Request) {
+
+		switch r.URL.Path {
+		case "/people.json":
+			fmt.Fprint(w, `{"STATUS": "OK", "people": [{"id": "1", "first-name": "Leia", "last-name": "Organa"}]}`)
+		case "/calendarevents.json":
+			fmt.Fprint(w, `{"STATUS": "OK", "events": []}`)
+		case "/time_entries.json":
+			fmt.Fprint(w, `{"STATUS": "OK", "time-entries": [
+				{"person-id": "1", "todo-item-id": "100", "hours": "8", "minutes": "0", "date": "2021-03-01T09:00:00Z"},
+				{"person-id": "1", "todo-item-id": "200", "hours": "8", "minutes": "0", "date": "2021-03-02T09:00:00Z"},
+				{"person-id": "1", "todo-item-id": "100", "hours": "1", "minutes": "0", "date": "2021-03-02T17:00:00Z"}
+			]}`)
+		case "/tasks/200.json":
+			fmt.Fprint(w, `{"STATUS": "OK", "todo-item": {"id": 200, "status": "completed", "completed_on": "2021-03-01T12:00:00Z"}}`)
+		default:
+			fmt.Fprint(w, `{"STATUS": "Error", "MESSAGE": "Not found"}`)
+		}
+	})
+
+	anomalies, err := conn.CheckTimesheetsByCompany("5", "20210301", "20210302", nil)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	var got []string
+	for _, a := range anomalies {
+		got = append(got, a.Date+" "+string(a.Type)+" "+a.TaskID)
+	}
+
+	// the failed lookup of task 100 is reported against each of its entries,
+	// and the rest of the report is still produced
+	want := fmt.Sprint([]string{"20210301 task-lookup-failed 100", "20210302 task-lookup-failed 100", "20210302 completed-task-time 200"})
+	if fmt.Sprint(got) != want {
+		t.Errorf("expected anomalies %s but got %v", want, got)
+	}
+}
@@ -0,0 +1,123 @@
+package teamworkapi
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimesheetEntriesFromV1AndV3(t *testing.T) {
+
+	v1 := []*TimeEntry{
+		{PersonID: "1", ProjectID: "10", TaskID: "100", Hours: "1", Minutes: "30", Date: "2021-03-01T14:00:00Z", IsBillable: "1", Tags: []Tag{{Name: "api"}}},
+		{PersonID: "2", ProjectID: "10", Hours: "2", Minutes: "0", Date: "20210302", IsBillable: "0"},
+	}
+
+	v3 := []*TimeLogV3{
+		{UserId: 1, ProjectID: 10, TaskID: 100, Minutes: 90, TimeLogged: "2021-03-01T14:00:00Z", IsBillable: true, TagIDs: []int{5}},
+		{UserId: 2, ProjectID: 10, Minutes: 120, TimeLogged: "2021-03-02T09:00:00Z"},
+	}
+
+	e1, err := TimesheetEntriesFromV1(v1)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	e3, err := TimesheetEntriesFromV3(v3, []*Tag{{IDBuff: float64(5), Name: "api"}})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	for _, entries := range [][]*TimesheetEntry{e1, e3} {
+
+		tt, err := AggregateTimesheet(entries, DimensionPerson, DimensionBillable)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		if tt.Value("1", "billable") != 1.5 {
+			t.Errorf("expected 1.5 billable hours for person 1 but got %f", tt.Value("1", "billable"))
+		}
+
+		if tt.Value("2", "non-billable") != 2 {
+			t.Errorf("expected 2 non-billable hours for person 2 but got %f", tt.Value("2", "non-billable"))
+		}
+
+		if tt.Total != 3.5 {
+			t.Errorf("expected total of 3.5 hours but got %f", tt.Total)
+		}
+
+		tags, err := AggregateTimesheet(entries, DimensionTag, DimensionPerson)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		if tags.Value("api", "1") != 1.5 {
+			t.Errorf("expected 1.5 hours tagged api for person 1 but got %f", tags.Value("api", "1"))
+		}
+	}
+
+	if _, err := TimesheetEntriesFromV1([]*TimeEntry{{Hours: "1", Date: "03/01/2021"}}); err == nil {
+		t.Errorf("expected error for invalid date")
+	}
+}
+
+func TestAggregateTimesheet(t *testing.T) {
+
+	entries := []*TimesheetEntry{
+		{PersonID: "1", ProjectID: "10", Tags: []string{"api", "ui"}, Hours: 2, Date: mustParseTimesheetDate(t, "2021-01-04")},
+		{PersonID: "1", ProjectID: "20", Hours: 1, Date: mustParseTimesheetDate(t, "2021-01-11")},
+		{PersonID: "2", ProjectID: "10", Tags: []string{"api"}, Hours: 3, Date: mustParseTimesheetDate(t, "2021-02-01")},
+	}
+
+	var tests = []struct {
+		row       TimesheetDimension
+		col       TimesheetDimension
+		wantRows  int
+		wantCols  int
+		cellRow   string
+		cellCol   string
+		wantCell  float64
+		wantTotal float64
+	}{
+		{DimensionPerson, DimensionProject, 2, 2, "1", "10", 2, 6},
+		{DimensionTag, DimensionPerson, 3, 2, "api", "1", 2, 6},
+		{DimensionProject, DimensionWeek, 2, 3, "10", "2021-W01", 2, 6},
+		{DimensionPerson, DimensionMonth, 2, 2, "1", "2021-01", 3, 6},
+		{DimensionTask, DimensionDay, 1, 3, TimesheetNone, "2021-02-01", 3, 6},
+	}
+
+	for _, v := range tests {
+
+		tt, err := AggregateTimesheet(entries, v.row, v.col)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		if len(tt.Rows) != v.wantRows || len(tt.Columns) != v.wantCols {
+			t.Errorf("expected %dx%d table for %s/%s but got %dx%d", v.wantRows, v.wantCols, v.row, v.col, len(tt.Rows), len(tt.Columns))
+		}
+
+		if tt.Value(v.cellRow, v.cellCol) != v.wantCell {
+			t.Errorf("expected %f hours at (%s, %s) but got %f", v.wantCell, v.cellRow, v.cellCol, tt.Value(v.cellRow, v.cellCol))
+		}
+
+		if tt.Total != v.wantTotal {
+			t.Errorf("expected total of %f hours but got %f", v.wantTotal, tt.Total)
+		}
+	}
+
+	_, err := AggregateTimesheet(entries, "bad", DimensionPerson)
+	if err == nil || err.Error() != "invalid timesheet dimension (bad)" {
+		t.Errorf("expected invalid dimension error but got %v", err)
+	}
+}
+
+func mustParseTimesheetDate(t *testing.T, s string) time.Time {
+
+	d, err := ParseTeamworkDate(s)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	return d
+}
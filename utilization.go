@@ -0,0 +1,597 @@
+package teamworkapi
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WorkingHours models the number of hours a person is expected to work on
+// each day of the week.
+type WorkingHours struct {
+	Sunday    float64 `json:"sunday"`
+	Monday    float64 `json:"monday"`
+	Tuesday   float64 `json:"tuesday"`
+	Wednesday float64 `json:"wednesday"`
+	Thursday  float64 `json:"thursday"`
+	Friday    float64 `json:"friday"`
+	Saturday  float64 `json:"saturday"`
+}
+
+// DefaultWorkingHours is an 8 hour day, Monday through Friday.
+var DefaultWorkingHours = WorkingHours{
+	Monday:    8,
+	Tuesday:   8,
+	Wednesday: 8,
+	Thursday:  8,
+	Friday:    8,
+}
+
+// HoursOn returns the working hours for the specified weekday.
+func (wh WorkingHours) HoursOn(d time.Weekday) float64 {
+
+	switch d {
+	case time.Sunday:
+		return wh.Sunday
+	case time.Monday:
+		return wh.Monday
+	case time.Tuesday:
+		return wh.Tuesday
+	case time.Wednesday:
+		return wh.Wednesday
+	case time.Thursday:
+		return wh.Thursday
+	case time.Friday:
+		return wh.Friday
+	case time.Saturday:
+		return wh.Saturday
+	}
+
+	return 0
+}
+
+// LeavePeriod models time a person (or everyone, if PersonIDs is empty) is
+// unavailable, such as leave or a holiday.
+type LeavePeriod struct {
+	PersonIDs []string
+	Start     time.Time
+	End       time.Time
+	AllDay    bool
+}
+
+// UtilizationConfig configures utilization and capacity reports.
+// WorkingHours overrides DefaultWorkingHours for specific people, keyed by
+// person ID.  LeaveEventTypes lists the calendar event type names or IDs
+// treated as leave; DefaultLeaveEventTypes is used if empty.
+type UtilizationConfig struct {
+	DefaultWorkingHours *WorkingHours
+	WorkingHours        map[string]WorkingHours
+	LeaveEventTypes     []string
+}
+
+// UtilizationEntry summarizes availability and logged time for a person, or
+// for a team when PersonID is empty.  Utilization is the percentage of
+// available hours logged as billable, while TotalUtilization includes
+// non-billable time.
+type UtilizationEntry struct {
+	PersonID         string
+	WorkingHours     float64
+	LeaveHours       float64
+	AvailableHours   float64
+	BillableHours    float64
+	NonBillableHours float64
+	Utilization      float64
+	TotalUtilization float64
+}
+
+// UtilizationReport summarizes utilization per person and for the team over a
+// period.
+type UtilizationReport struct {
+	From   time.Time
+	To     time.Time
+	People map[string]*UtilizationEntry
+	Team   *UtilizationEntry
+}
+
+// CapacityEntry summarizes the forward-looking capacity of a person, or of a
+// team when PersonID is empty.  ScheduledHours is the estimated time of open
+// tasks due within the period, including OverdueHours of open tasks due before
+// it, while UnscheduledHours is the estimated time of open tasks without a due
+// date.
+type CapacityEntry struct {
+	PersonID         string
+	AvailableHours   float64
+	ScheduledHours   float64
+	OverdueHours     float64
+	UnscheduledHours float64
+	RemainingHours   float64
+	Load             float64
+}
+
+// CapacityReport summarizes capacity per person and for the team over a
+// period.
+type CapacityReport struct {
+	From   time.Time
+	To     time.Time
+	People map[string]*CapacityEntry
+	Team   *CapacityEntry
+}
+
+// LeaveFromCalendarEvents converts v1 calendar events of a leave type to
+// LeavePeriods.
+func LeaveFromCalendarEvents(events []*CalendarEvent, leaveTypes []string) []*LeavePeriod {
+
+	conf := &TimesheetCheckConfig{LeaveEventTypes: leaveTypes}
+	conf = conf.withDefaults()
+
+	var retVal []*LeavePeriod
+
+	for _, e := range events {
+
+		if !conf.isLeave(e.Type) {
+			continue
+		}
+
+		start, err := ParseTeamworkDate(e.Start)
+		if err != nil {
+			continue
+		}
+
+		end, err := ParseTeamworkDate(e.End)
+		if err != nil {
+			end = start
+		}
+
+		lp := &LeavePeriod{Start: start, End: end, AllDay: e.AllDay}
+
+		for _, id := range strings.Split(e.AttendeeIDs, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				lp.PersonIDs = append(lp.PersonIDs, id)
+			}
+		}
+
+		retVal = append(retVal, lp)
+	}
+
+	return retVal
+}
+
+// LeaveFromCalendarEventsV3 converts v3 calendar events of a leave type to
+// LeavePeriods.  Since v3 events only reference their type by ID, leaveTypes
+// must contain event type IDs.
+func LeaveFromCalendarEventsV3(events []*CalendarEventsV3JSON, leaveTypes []string) []*LeavePeriod {
+
+	var retVal []*LeavePeriod
+
+	for _, e := range events {
+
+		leave := false
+		for _, t := range leaveTypes {
+			if t == strconv.Itoa(e.TypeId) {
+				leave = true
+			}
+		}
+
+		if !leave {
+			continue
+		}
+
+		start, err := ParseTeamworkDate(e.StartDate)
+		if err != nil {
+			continue
+		}
+
+		end, err := ParseTeamworkDate(e.EndDate)
+		if err != nil {
+			end = start
+		}
+
+		lp := &LeavePeriod{Start: start, End: end, AllDay: e.AllDay}
+
+		for _, id := range e.AttendingUserIds {
+			lp.PersonIDs = append(lp.PersonIDs, strconv.Itoa(id))
+		}
+
+		retVal = append(retVal, lp)
+	}
+
+	return retVal
+}
+
+// BuildUtilizationReport computes utilization for each person between from and
+// to (inclusive), based on their working hours, leave and logged time.
+func BuildUtilizationReport(personIDs []string, entries []*TimesheetEntry, leave []*LeavePeriod, from time.Time, to time.Time, conf *UtilizationConfig) *UtilizationReport {
+
+	r := &UtilizationReport{
+		From:   from,
+		To:     to,
+		People: make(map[string]*UtilizationEntry, len(personIDs)),
+		Team:   new(UtilizationEntry),
+	}
+
+	for _, id := range personIDs {
+		u := &UtilizationEntry{PersonID: id}
+		u.WorkingHours, u.LeaveHours = conf.availability(id, leave, from, to)
+		r.People[id] = u
+	}
+
+	start := dateOf(from)
+	end := dateOf(to)
+
+	for _, e := range entries {
+
+		u, ok := r.People[e.PersonID]
+		if !ok {
+			continue
+		}
+
+		d := dateOf(e.Date)
+		if d.Before(start) || d.After(end) {
+			continue
+		}
+
+		if e.Billable {
+			u.BillableHours += e.Hours
+		} else {
+			u.NonBillableHours += e.Hours
+		}
+	}
+
+	for _, u := range r.People {
+		u.AvailableHours = math.Max(u.WorkingHours-u.LeaveHours, 0)
+
+		r.Team.WorkingHours += u.WorkingHours
+		r.Team.LeaveHours += u.LeaveHours
+		r.Team.AvailableHours += u.AvailableHours
+		r.Team.BillableHours += u.BillableHours
+		r.Team.NonBillableHours += u.NonBillableHours
+
+		u.round()
+	}
+
+	r.Team.round()
+
+	return r
+}
+
+// BuildCapacityReport computes the forward-looking capacity of each person
+// between from and to (inclusive), based on their availability and the
+// estimates of their open tasks.  The estimate of a task assigned to several
+// people is split evenly between them.  Open tasks that are overdue at the
+// start of the period still need to be done, so they count as scheduled.
+func BuildCapacityReport(personIDs []string, tasks []*TaskRes, leave []*LeavePeriod, from time.Time, to time.Time, conf *UtilizationConfig) *CapacityReport {
+
+	r := &CapacityReport{
+		From:   from,
+		To:     to,
+		People: make(map[string]*CapacityEntry, len(personIDs)),
+		Team:   new(CapacityEntry),
+	}
+
+	for _, id := range personIDs {
+		working, onLeave := conf.availability(id, leave, from, to)
+		r.People[id] = &CapacityEntry{
+			PersonID:       id,
+			AvailableHours: math.Max(working-onLeave, 0),
+		}
+	}
+
+	start := dateOf(from)
+	end := dateOf(to)
+
+	for _, t := range tasks {
+
+		if t.Status == "completed" || t.EstimateMinutes == 0 {
+			continue
+		}
+
+		all := t.AssigneeIDs()
+
+		var assignees []string
+		for _, id := range all {
+			if _, ok := r.People[id]; ok {
+				assignees = append(assignees, id)
+			}
+		}
+
+		if len(assignees) == 0 {
+			continue
+		}
+
+		hours := float64(t.EstimateMinutes) / 60 / float64(len(all))
+
+		due, err := ParseTeamworkDate(t.DueDate)
+
+		for _, id := range assignees {
+			c := r.People[id]
+
+			switch {
+			case t.DueDate == "" || err != nil:
+				c.UnscheduledHours += hours
+			case dateOf(due).Before(start):
+				c.ScheduledHours += hours
+				c.OverdueHours += hours
+			case !dateOf(due).After(end):
+				c.ScheduledHours += hours
+			}
+		}
+	}
+
+	for _, c := range r.People {
+		c.RemainingHours = c.AvailableHours - c.ScheduledHours
+
+		r.Team.AvailableHours += c.AvailableHours
+		r.Team.ScheduledHours += c.ScheduledHours
+		r.Team.OverdueHours += c.OverdueHours
+		r.Team.UnscheduledHours += c.UnscheduledHours
+		r.Team.RemainingHours += c.RemainingHours
+
+		c.round()
+	}
+
+	r.Team.round()
+
+	return r
+}
+
+// GetUtilizationReport retrieves time logs and calendar events for each person
+// between fromDate and toDate (YYYYMMDD) and builds a UtilizationReport.
+func (conn *Connection) GetUtilizationReport(personIDs []string, fromDate string, toDate string, conf *UtilizationConfig) (*UtilizationReport, error) {
+
+	from, to, err := parseReportPeriod(fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+
+	leave, err := conn.getLeavePeriods(from, to, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := conn.v3().GetAllTimeEntriesV3(&TimeQueryParamsV3{
+		StartDate:         from.Format(TeamworkDateFormatV3),
+		EndDate:           to.Format(TeamworkDateFormatV3),
+		AssignedToUserIds: personIDs,
+		PageSize:          "500",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := TimesheetEntriesFromV3(raw, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return BuildUtilizationReport(personIDs, entries, leave, from, to, conf), nil
+}
+
+// GetCapacityReport retrieves open tasks and calendar events for each person
+// between fromDate and toDate (YYYYMMDD) and builds a CapacityReport.
+func (conn *Connection) GetCapacityReport(personIDs []string, fromDate string, toDate string, conf *UtilizationConfig) (*CapacityReport, error) {
+
+	from, to, err := parseReportPeriod(fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+
+	leave, err := conn.getLeavePeriods(from, to, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := conn.v3().GetAllTasksV3(TaskQueryParamsV3{
+		AssigneeUserIDs: personIDs,
+		PageSize:        "500",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]*TaskRes, 0, len(res.Tasks))
+	for i := range res.Tasks {
+		tasks = append(tasks, &res.Tasks[i])
+	}
+
+	return BuildCapacityReport(personIDs, tasks, leave, from, to, conf), nil
+}
+
+func (conn *Connection) getLeavePeriods(from time.Time, to time.Time, conf *UtilizationConfig) ([]*LeavePeriod, error) {
+
+	types, err := conn.leaveEventTypeIDs(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := conn.v3().GetAllCalendarEventsV3(CalendarEventQueryParamsV3{
+		StartDate: from.Format(TeamworkDateFormatV3),
+		EndDate:   to.Format(TeamworkDateFormatV3),
+		PageSize:  "500",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	events, err = ExpandCalendarEventsV3(events, from, endOfDay(to))
+	if err != nil {
+		return nil, err
+	}
+
+	return LeaveFromCalendarEventsV3(events, types), nil
+}
+
+// leaveEventTypeIDs returns the IDs of the configured leave event types, or of
+// DefaultLeaveEventTypes.  A configured name that does not match an event type
+// is an error.  Sites rarely define every default type, so missing defaults
+// are only an error when none of them match.
+func (conn *Connection) leaveEventTypeIDs(conf *UtilizationConfig) ([]string, error) {
+
+	names := DefaultLeaveEventTypes
+	configured := conf != nil && len(conf.LeaveEventTypes) > 0
+	if configured {
+		names = conf.LeaveEventTypes
+	}
+
+	var retVal []string
+	var unresolved []string
+
+	for _, n := range names {
+		if _, err := strconv.Atoi(n); err == nil {
+			retVal = append(retVal, n)
+		} else {
+			unresolved = append(unresolved, n)
+		}
+	}
+
+	if len(unresolved) == 0 {
+		return retVal, nil
+	}
+
+	types, err := conn.v1().GetCalendarEventTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []string
+
+	for _, n := range unresolved {
+
+		found := false
+
+		for _, t := range types {
+			if strings.EqualFold(strings.TrimSpace(n), strings.TrimSpace(t.Name)) {
+				retVal = append(retVal, t.ID)
+				found = true
+			}
+		}
+
+		if !found {
+			missing = append(missing, n)
+		}
+	}
+
+	if len(retVal) == 0 || (configured && len(missing) > 0) {
+		return nil, fmt.Errorf("no calendar event type found for leave type(s): %s", strings.Join(missing, ", "))
+	}
+
+	return retVal, nil
+}
+
+// availability returns the working hours and hours on leave of a person
+// between from and to (inclusive).
+func (conf *UtilizationConfig) availability(personID string, leave []*LeavePeriod, from time.Time, to time.Time) (float64, float64) {
+
+	wh := DefaultWorkingHours
+	if conf != nil {
+		if conf.DefaultWorkingHours != nil {
+			wh = *conf.DefaultWorkingHours
+		}
+		if v, ok := conf.WorkingHours[personID]; ok {
+			wh = v
+		}
+	}
+
+	working := 0.0
+	onLeave := 0.0
+
+	for d := dateOf(from); !d.After(dateOf(to)); d = d.AddDate(0, 0, 1) {
+
+		h := wh.HoursOn(d.Weekday())
+		working += h
+
+		if h == 0 {
+			continue
+		}
+
+		l := 0.0
+		for _, lp := range leave {
+			l = math.Max(l, lp.hoursOn(personID, d, h))
+		}
+
+		onLeave += l
+	}
+
+	return working, onLeave
+}
+
+// hoursOn returns the hours of leave taken by personID on day d, capped at the
+// working hours of that day.
+func (lp *LeavePeriod) hoursOn(personID string, d time.Time, working float64) float64 {
+
+	if len(lp.PersonIDs) > 0 {
+		found := false
+		for _, id := range lp.PersonIDs {
+			if id == personID {
+				found = true
+			}
+		}
+		if !found {
+			return 0
+		}
+	}
+
+	if d.Before(dateOf(lp.Start)) || d.After(dateOf(lp.End)) {
+		return 0
+	}
+
+	if lp.AllDay || !dateOf(lp.Start).Equal(dateOf(lp.End)) {
+		return working
+	}
+
+	return math.Min(lp.End.Sub(lp.Start).Hours(), working)
+}
+
+func (u *UtilizationEntry) round() {
+
+	if u.AvailableHours > 0 {
+		u.Utilization = math.Round(u.BillableHours/u.AvailableHours*10000) / 100
+		u.TotalUtilization = math.Round((u.BillableHours+u.NonBillableHours)/u.AvailableHours*10000) / 100
+	}
+
+	u.WorkingHours = roundHours(u.WorkingHours)
+	u.LeaveHours = roundHours(u.LeaveHours)
+	u.AvailableHours = roundHours(u.AvailableHours)
+	u.BillableHours = roundHours(u.BillableHours)
+	u.NonBillableHours = roundHours(u.NonBillableHours)
+}
+
+func (c *CapacityEntry) round() {
+
+	if c.AvailableHours > 0 {
+		c.Load = math.Round(c.ScheduledHours/c.AvailableHours*10000) / 100
+	}
+
+	c.AvailableHours = roundHours(c.AvailableHours)
+	c.ScheduledHours = roundHours(c.ScheduledHours)
+	c.OverdueHours = roundHours(c.OverdueHours)
+	c.UnscheduledHours = roundHours(c.UnscheduledHours)
+	c.RemainingHours = roundHours(c.RemainingHours)
+}
+
+func parseReportPeriod(fromDate string, toDate string) (time.Time, time.Time, error) {
+
+	if fromDate == "" || toDate == "" {
+		return time.Time{}, time.Time{}, fmt.Errorf("missing required parameter(s): fromDate, toDate")
+	}
+
+	err := validateDateParam("fromDate", fromDate, TeamworkDateFormatShort)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	err = validateDateParam("toDate", toDate, TeamworkDateFormatShort)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	from, _ := time.Parse(TeamworkDateFormatShort, fromDate)
+	to, _ := time.Parse(TeamworkDateFormatShort, toDate)
+
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("toDate (%s) is before fromDate (%s)", toDate, fromDate)
+	}
+
+	return from, to, nil
+}
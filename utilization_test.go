@@ -0,0 +1,201 @@
+package teamworkapi
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildUtilizationReport(t *testing.T) {
+
+	// 2021-03-01 is a Monday
+	from, _ := time.Parse(TeamworkDateFormatShort, "20210301")
+	to, _ := time.Parse(TeamworkDateFormatShort, "20210307")
+
+	entries := []*TimesheetEntry{
+		{PersonID: "1", Hours: 20, Billable: true, Date: from},
+		{PersonID: "1", Hours: 4, Date: from.AddDate(0, 0, 1)},
+		{PersonID: "2", Hours: 16, Billable: true, Date: from},
+		{PersonID: "2", Hours: 8, Billable: true, Date: from.AddDate(0, 0, 14)},
+		{PersonID: "3", Hours: 8, Billable: true, Date: from},
+	}
+
+	leave := []*LeavePeriod{
+		{Start: from.AddDate(0, 0, 2), End: from.AddDate(0, 0, 2), AllDay: true},
+		{PersonIDs: []string{"2"}, Start: from.AddDate(0, 0, 3), End: from.AddDate(0, 0, 4), AllDay: true},
+		{PersonIDs: []string{"1"}, Start: from.Add(9 * time.Hour), End: from.Add(13 * time.Hour)},
+	}
+
+	conf := &UtilizationConfig{
+		WorkingHours: map[string]WorkingHours{"2": {Monday: 10, Tuesday: 10, Wednesday: 10, Thursday: 10}},
+	}
+
+	r := BuildUtilizationReport([]string{"1", "2"}, entries, leave, from, to, conf)
+
+	var tests = []struct {
+		personID        string
+		wantWorking     float64
+		wantLeave       float64
+		wantUtilization float64
+		wantTotal       float64
+	}{
+		{"1", 40, 12, 71.43, 85.71},
+		{"2", 40, 20, 80, 80},
+	}
+
+	for _, v := range tests {
+
+		u := r.People[v.personID]
+
+		if u.WorkingHours != v.wantWorking {
+			t.Errorf("expected %f working hours for person %s but got %f", v.wantWorking, v.personID, u.WorkingHours)
+		}
+
+		if u.LeaveHours != v.wantLeave {
+			t.Errorf("expected %f leave hours for person %s but got %f", v.wantLeave, v.personID, u.LeaveHours)
+		}
+
+		if u.Utilization != v.wantUtilization {
+			t.Errorf("expected utilization of %f for person %s but got %f", v.wantUtilization, v.personID, u.Utilization)
+		}
+
+		if u.TotalUtilization != v.wantTotal {
+			t.Errorf("expected total utilization of %f for person %s but got %f", v.wantTotal, v.personID, u.TotalUtilization)
+		}
+	}
+
+	if r.Team.AvailableHours != 48 || r.Team.Utilization != 75 {
+		t.Errorf("expected team availability of 48 hours and utilization of 75 but got %f and %f", r.Team.AvailableHours, r.Team.Utilization)
+	}
+}
+
+func TestBuildCapacityReport(t *testing.T) {
+
+	from, _ := time.Parse(TeamworkDateFormatShort, "20210301")
+	to, _ := time.Parse(TeamworkDateFormatShort, "20210305")
+
+	tasks := []*TaskRes{
+		{Id: 1, AssigneeUserIds: []int{1}, EstimateMinutes: 600, DueDate: "2021-03-03T00:00:00Z"},
+		{Id: 2, AssigneeUserIds: []int{1, 2}, EstimateMinutes: 1200, DueDate: "2021-03-05T00:00:00Z"},
+		{Id: 3, AssigneeUserIds: []int{2}, EstimateMinutes: 300},
+		{Id: 4, AssigneeUserIds: []int{2}, EstimateMinutes: 300, DueDate: "2021-03-10T00:00:00Z"},
+		{Id: 5, AssigneeUserIds: []int{1}, EstimateMinutes: 300, DueDate: "2021-03-02T00:00:00Z", Status: "completed"},
+		{Id: 6, AssigneeUserIds: []int{2}, EstimateMinutes: 300, DueDate: "2021-02-25T00:00:00Z"},
+	}
+
+	r := BuildCapacityReport([]string{"1", "2"}, tasks, nil, from, to, nil)
+
+	var tests = []struct {
+		personID        string
+		wantScheduled   float64
+		wantOverdue     float64
+		wantUnscheduled float64
+		wantRemaining   float64
+	}{
+		{"1", 20, 0, 0, 20},
+		{"2", 15, 5, 5, 25},
+	}
+
+	for _, v := range tests {
+
+		c := r.People[v.personID]
+
+		if c.ScheduledHours != v.wantScheduled {
+			t.Errorf("expected %f scheduled hours for person %s but got %f", v.wantScheduled, v.personID, c.ScheduledHours)
+		}
+
+		if c.OverdueHours != v.wantOverdue {
+			t.Errorf("expected %f overdue hours for person %s but got %f", v.wantOverdue, v.personID, c.OverdueHours)
+		}
+
+		if c.UnscheduledHours != v.wantUnscheduled {
+			t.Errorf("expected %f unscheduled hours for person %s but got %f", v.wantUnscheduled, v.personID, c.UnscheduledHours)
+		}
+
+		if c.RemainingHours != v.wantRemaining {
+			t.Errorf("expected %f remaining hours for person %s but got %f", v.wantRemaining, v.personID, c.RemainingHours)
+		}
+	}
+
+	if r.Team.Load != 43.75 {
+		t.Errorf("expected team load of 43.75 but got %f", r.Team.Load)
+	}
+}
+
+func TestLeaveFromCalendarEvents(t *testing.T) {
+
+	v1 := []*CalendarEvent{
+		{Start: "2021-03-01T00:00", End: "2021-03-02T00:00", AllDay: true, Type: &EventType{ID: "7", Name: "Holiday"}},
+		{Start: "2021-03-01T09:00", End: "2021-03-01T10:00", Type: &EventType{ID: "8", Name: "Meeting"}, AttendeeIDs: "1"},
+	}
+
+	if l := LeaveFromCalendarEvents(v1, nil); len(l) != 1 || len(l[0].PersonIDs) != 0 {
+		t.Errorf("expected a single leave period for everyone but got %d", len(l))
+	}
+
+	v3 := []*CalendarEventsV3JSON{
+		{TypeId: 7, StartDate: "2021-03-01T00:00:00Z", EndDate: "2021-03-01T23:59:00Z", AllDay: true, AttendingUserIds: []int{1, 2}},
+		{TypeId: 8, StartDate: "2021-03-01T09:00:00Z", EndDate: "2021-03-01T10:00:00Z"},
+	}
+
+	if l := LeaveFromCalendarEventsV3(v3, []string{"7"}); len(l) != 1 || len(l[0].PersonIDs) != 2 {
+		t.Errorf("expected a single leave period for 2 people but got %d", len(l))
+	}
+}
+
+func TestGetUtilizationReport(t *testing.T) {
+
+	conn := initMockConnection(t, func(w http.ResponseWriter, r *http.Request) {
+
+		switch r.URL.Path {
+		case "/calendareventtypes.json":
+			fmt.Fprint(w, `{"STATUS": "OK", "eventtypes": [{"id": "7", "name": "Meeting"}, {"id": "9", "name": "vacation"}]}`)
+		case "/projects/api/v3/calendar/events.json":
+			fmt.Fprint(w, `{"calendarEvents": [
+				{"id": 1, "typeId": 9, "attendingUserIds": [1], "startDate": "2021-03-01T00:00:00Z", "endDate": "2021-03-02T00:00:00Z", "allDay": true},
+				{"id": 2, "typeId": 7, "attendingUserIds": [1, 2], "startDate": "2021-03-03T00:00:00Z", "endDate": "2021-03-03T00:00:00Z", "allDay": true}
+			]}`)
+		case "/projects/api/v3/time.json":
+			if r.URL.Query().Get("page") == "1" {
+				fmt.Fprint(w, `{"timelogs": [
+					{"id": 1, "userId": 1, "minutes": 720, "isBillable": true, "timeLogged": "2021-03-03T09:00:00Z"}
+				], "meta": {"page": {"hasMore": true}}}`)
+			} else {
+				fmt.Fprint(w, `{"timelogs": [
+					{"id": 2, "userId": 2, "minutes": 1200, "isBillable": true, "timeLogged": "2021-03-03T09:00:00Z"}
+				], "meta": {"page": {"hasMore": false}}}`)
+			}
+		default:
+			t.Errorf("unexpected request for %s", r.URL.Path)
+		}
+	})
+
+	// the report works with a connection for either API version
+	for _, url := range []string{conn.URL, conn.URL + "projects/api/v3/"} {
+
+		conn.URL = url
+
+		r, err := conn.GetUtilizationReport([]string{"1", "2"}, "20210301", "20210305", nil)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		// the default leave types match the vacation but not the meeting
+		if r.People["1"].LeaveHours != 16 || r.People["2"].LeaveHours != 0 {
+			t.Errorf("expected 16 and 0 leave hours but got %f and %f", r.People["1"].LeaveHours, r.People["2"].LeaveHours)
+		}
+
+		if r.People["1"].Utilization != 50 || r.People["2"].Utilization != 50 {
+			t.Errorf("expected utilization of 50 but got %f and %f", r.People["1"].Utilization, r.People["2"].Utilization)
+		}
+	}
+
+	conf := &UtilizationConfig{LeaveEventTypes: []string{"vacation", "Sick"}}
+
+	_, err := conn.GetUtilizationReport([]string{"1", "2"}, "20210301", "20210305", conf)
+	if err == nil || !strings.Contains(err.Error(), "Sick") {
+		t.Errorf("expected error for unknown leave type but got (%v)", err)
+	}
+}